@@ -2,23 +2,49 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/jqdurham/reddit/internal/api"
+	"github.com/jqdurham/reddit/internal/breaker"
+	"github.com/jqdurham/reddit/internal/budget"
 	"github.com/jqdurham/reddit/internal/config"
+	"github.com/jqdurham/reddit/internal/coord"
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/jqdurham/reddit/internal/links"
 	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/metrics"
 	"github.com/jqdurham/reddit/internal/orchestrator"
 	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/rules"
+	"github.com/jqdurham/reddit/internal/sentiment"
 	"github.com/jqdurham/reddit/internal/service/post"
+	"github.com/jqdurham/reddit/internal/snapshot"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/jqdurham/reddit/internal/tui"
+	"github.com/jqdurham/reddit/internal/webhook"
 	"golang.org/x/time/rate"
 )
 
-const rateLimiterAllowableBurst = 1
+const (
+	rateLimiterAllowableBurst = 1
+	sentimentExtremes         = 5
+	linkResolveTimeout        = 5 * time.Second
+)
 
 func main() {
+	tuiMode := flag.Bool("tui", false, "redraw a full-screen dashboard instead of printing reports")
+	statusMode := flag.Bool("status", false, "print the status of each job of the running monitor and exit")
+	flag.Parse()
+
 	lvl := new(slog.LevelVar)
 	logr := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: lvl}))
 
@@ -36,41 +62,391 @@ func main() {
 
 	lvl.Set(cfg.LogLevel)
 
+	if *statusMode {
+		if err := printStatus(os.Stdout, cfg.HTTPAddr); err != nil {
+			logr.Error(err.Error())
+			exit()
+		}
+
+		return
+	}
+
 	ctx := logger.NewContext(context.Background(), logr)
 
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	registry := metrics.NewRegistry()
+
+	// The request rate is divided between jobs by weight. The scheduler outlives the signal so jobs
+	// in flight can finish their requests while shutting down.
 	rateLimiter := rate.NewLimiter(rate.Every(cfg.RateLimit), rateLimiterAllowableBurst)
-	client := reddit.NewClient(cfg.ClientID, cfg.ClientSecret, http.DefaultClient, rateLimiter)
+	scheduler := budget.NewScheduler(rateLimiter)
+
+	budgetCtx, stopBudget := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopBudget()
+
+	go scheduler.Run(budgetCtx)
+
+	client := reddit.NewClient(cfg.ClientID, cfg.ClientSecret, http.DefaultClient, scheduler,
+		reddit.WithMetrics(registry))
 
 	if err := client.Login(ctx, cfg.RedditUsername, cfg.RedditPassword); err != nil {
 		logr.Error(err.Error())
 		exit()
 	}
 
-	postSvc := post.NewService(client, os.Stdout)
+	store := stats.NewStore()
+	postOpts := []post.Option{post.WithStore(store), post.WithMetrics(registry), post.WithLeaderboardChanges()}
+
+	if cfg.SentimentLexicon != "" {
+		analyzer, err := loadAnalyzer(cfg.SentimentLexicon)
+		if err != nil {
+			logr.Error(err.Error())
+			exit()
+		}
+
+		postOpts = append(postOpts, post.WithSentimentAnalyzer(analyzer))
+	}
+
+	if cfg.RulesFile != "" {
+		parsed, err := loadRules(cfg.RulesFile)
+		if err != nil {
+			logr.Error(err.Error())
+			exit()
+		}
+
+		postOpts = append(postOpts, post.WithRules(rules.NewEngine(parsed)))
+	}
+
+	if len(cfg.LinkShorteners) > 0 {
+		postOpts = append(postOpts, post.WithLinkNormalizer(
+			links.NewNormalizer(cfg.LinkShorteners, links.NewHTTPResolver(linkResolveTimeout))))
+	}
+
+	reporter, err := report.New(cfg.ReportFormat, os.Stdout)
+	if err != nil {
+		logr.Error(err.Error())
+		exit()
+	}
+
+	// Leaderboard diffs are always published to subscribers; stdout gets either the diffs or the
+	// full leaderboards.
+	if cfg.ReportChangesOnly {
+		reporter = report.NewFilter(reporter, report.KindTopPosts, report.KindTopAuthors)
+	} else {
+		reporter = report.NewFilter(reporter, report.KindTopPostsChanges, report.KindTopAuthorsChanges)
+	}
+
+	var (
+		recorder      metrics.Recorder = registry
+		stopDashboard                  = func() {}
+	)
+
+	if *tuiMode && !tui.IsTerminal(os.Stdout) {
+		logr.Warn("stdout is not a terminal, ignoring --tui")
+	} else if *tuiMode {
+		dashboard := tui.NewDashboard(os.Stdout, client)
+		reporter = dashboard
+		recorder = metrics.NewMulti(registry, dashboard)
+
+		// Warnings and errors go to the dashboard's ticker rather than scrolling over the screen.
+		ctx = logger.NewContext(ctx, slog.New(slog.NewTextHandler(dashboard,
+			&slog.HandlerOptions{Level: max(cfg.LogLevel, slog.LevelWarn)})))
+
+		dashCtx, cancel := context.WithCancel(ctx)
+		dashDone := make(chan struct{})
+
+		go func() {
+			defer close(dashDone)
+
+			if err := dashboard.Run(dashCtx); err != nil {
+				logr.Error(err.Error())
+			}
+		}()
+
+		stopDashboard = func() {
+			cancel()
+			<-dashDone
+		}
+	}
+
+	// Reports are also published to the bus so HTTP clients can subscribe to live updates.
+	bus := events.NewBus()
+	reporter = report.NewMulti(reporter, events.NewReporter(bus))
+
+	digestDone := make(chan struct{})
+	if cfg.DigestPath != "" {
+		digest := snapshot.NewDigest(cfg.DigestPath, "Reddit Digest")
+		reporter = report.NewMulti(reporter, report.NewFilter(digest,
+			report.KindTopPostsChanges, report.KindTopAuthorsChanges, report.KindRuleMatches))
+
+		go func() {
+			defer close(digestDone)
+
+			digest.Run(ctx, cfg.DigestInterval)
+		}()
+	} else {
+		close(digestDone)
+	}
+
+	postOpts = append(postOpts, post.WithTrendingThreshold(cfg.TrendingScoreDelta))
+
+	webhookDone := make(chan struct{})
+	if len(cfg.Webhooks) > 0 {
+		queue := webhook.NewQueue(cfg.Webhooks)
+		postOpts = append(postOpts, post.WithNotifier(queue))
+
+		go func() {
+			defer close(webhookDone)
+
+			queue.Run(ctx)
+		}()
+	} else {
+		close(webhookDone)
+	}
+
+	// Subreddits may be tracked and untracked while running, through the admin API or by reloading
+	// the configuration.
+	tracker := &subreddits{
+		cfg:       cfg,
+		scheduler: scheduler,
+		recorder:  recorder,
+		logr:      logger.FromContext(ctx),
+		weights:   cfg.SubredditWeights,
+		polled:    map[string]bool{},
+		pacers:    map[string]*orchestrator.Adaptive{},
+	}
+
+	// The sentiment job polls each subreddit's newest posts, also detecting removals, optionally at
+	// a pace adapted to how often posts arrive.
+	if cfg.AdaptivePolling {
+		postOpts = append(postOpts, post.WithArrivalObserver(tracker.observeArrivals))
+	}
+
+	// Fetched listings are parsed, aggregated and reported in stages so jobs only wait on a full
+	// stage. The stages outlive the signal so listings fetched while shutting down are still handled.
+	postOpts = append(postOpts, post.WithPipeline(cfg.Pipeline))
+
+	// A subreddit which keeps failing, e.g. once it goes private, is not fetched from until its
+	// circuit cools down. Circuits changing state are published to the bus.
+	fetcher := breaker.New(client, cfg.Breaker, breaker.WithObserver(publishTransition(bus)))
+	tracker.breaker = fetcher
+
+	if tracker.posts, err = post.NewService(fetcher, reporter, postOpts...); err != nil {
+		logr.Error(err.Error())
+		exit()
+	}
+
+	pipelineCtx, cancelPipeline := context.WithCancel(context.WithoutCancel(ctx))
+	pipelineDone := make(chan struct{})
+
+	go func() {
+		defer close(pipelineDone)
+
+		tracker.posts.Run(pipelineCtx)
+	}()
+
+	stopPipeline := func() {
+		cancelPipeline()
+		<-pipelineDone
+	}
 
 	errCh := make(chan error)
+	tracker.runner = orchestrator.Run(ctx, errCh)
+
+	// Instances sharing a coordination directory divide the tracked subreddits between them by
+	// consistent hashing, and the request rate evenly, rebalancing whenever one joins or leaves.
+	var coordinator *coord.Coordinator
+
+	coordDone := make(chan struct{})
+	if cfg.CoordDir != "" {
+		coordinator = coord.New(coord.NewFileBackend(cfg.CoordDir), cfg.InstanceID, cfg.CoordTTL,
+			coord.WithOnChange(func(m coord.Membership) {
+				rateLimiter.SetLimit(rate.Every(cfg.RateLimit * time.Duration(max(len(m.Members), 1))))
+
+				if err := tracker.Rebalance(); err != nil {
+					logr.Error(err.Error())
+				}
+			}))
+		tracker.owns = coordinator.Owns
+
+		if err := coordinator.Join(ctx); err != nil {
+			logr.Error(err.Error())
+			exit()
+		}
+
+		go func() {
+			defer close(coordDone)
+
+			coordinator.Run(ctx)
+		}()
+	} else {
+		close(coordDone)
+	}
+
+	serverDone := make(chan struct{})
+	if cfg.HTTPAddr != "" {
+		server := api.NewServer(cfg.HTTPAddr, store, api.WithEvents(bus), api.WithAdmin(tracker, cfg.AdminToken))
+		server.Handle("GET /metrics", registry)
+		server.Handle("GET /budget", scheduler)
+		server.Handle("GET /jobs", tracker.runner)
+		server.Handle("GET /breakers", fetcher)
+
+		if coordinator != nil {
+			server.Handle("GET /cluster", coordinator)
+		}
+
+		go func() {
+			defer close(serverDone)
+
+			if err := server.Run(ctx); err != nil {
+				select {
+				case errCh <- err:
+				case <-ctx.Done():
+					logr.Error(err.Error())
+				}
+			}
+		}()
+	} else {
+		close(serverDone)
+	}
+
+	htmlDone := make(chan struct{})
+	if cfg.HTMLReportPath != "" {
+		html := snapshot.NewHTML(cfg.HTMLReportPath, store, snapshot.WithTopN(cfg.TopNAuthors))
+
+		go func() {
+			defer close(htmlDone)
+
+			html.Run(ctx, cfg.HTMLReportInterval)
+		}()
+	} else {
+		close(htmlDone)
+	}
 
-	jobs := make([]orchestrator.Job, 0, len(cfg.Subreddits)*2)
 	for _, subreddit := range cfg.Subreddits {
-		jobs = append(jobs, func() error {
-			return postSvc.UpdateTopPosts(ctx, subreddit)
-		})
-		jobs = append(jobs, func() error {
-			return postSvc.UpdateTopNAuthors(ctx, subreddit, cfg.TopNAuthors)
-		})
+		if _, err := tracker.Track(subreddit); err != nil {
+			logr.Error(err.Error())
+		}
 	}
 
-	orchestrator.Run(ctx, errCh, jobs...)
+	for _, spec := range tracker.globalSpecs() {
+		if err := tracker.runner.Add(spec); err != nil {
+			logr.Error(err.Error())
+		}
+	}
+
+	// SIGHUP reloads the configuration and tracks the subreddits it lists.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(reload)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				if err := reloadSubreddits(tracker); err != nil {
+					logr.Error(err.Error())
+				}
+			}
+		}
+	}()
 
 	select {
 	case err := <-errCh:
+		summaries := tracker.runner.Shutdown(cfg.ShutdownTimeout)
+		stopPipeline()
+		stopDashboard()
 		logr.Error(err.Error())
+		logSummaries(logr, summaries)
 		exit()
 	case <-ctx.Done():
+		summaries := tracker.runner.Shutdown(cfg.ShutdownTimeout)
+		stopPipeline()
+		stopDashboard()
 		logr.Info("Shutdown signal received, exiting...")
+		logSummaries(logr, summaries)
+		<-serverDone
+		<-htmlDone
+		<-webhookDone
+		<-digestDone
+		<-coordDone
+	}
+}
+
+func loadAnalyzer(path string) (*sentiment.Analyzer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open sentiment lexicon: %w", err)
+	}
+	defer file.Close()
+
+	lexicon, err := sentiment.LoadLexicon(file)
+	if err != nil {
+		return nil, fmt.Errorf("load sentiment lexicon: %w", err)
+	}
+
+	return sentiment.NewAnalyzer(lexicon), nil
+}
+
+func loadRules(path string) ([]rules.Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open rules: %w", err)
+	}
+	defer file.Close()
+
+	parsed, err := rules.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("load rules: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// reloadSubreddits re-reads the configuration, tracking exactly the subreddits it lists and
+// weighing them as configured. Other settings take effect on restart.
+func reloadSubreddits(tracker *subreddits) error {
+	file, err := os.Open("./.env")
+	if err != nil {
+		return fmt.Errorf("reload configuration: %w", err)
+	}
+	defer file.Close()
+
+	cfg, err := config.Configure(file)
+	if err != nil {
+		return fmt.Errorf("reload configuration: %w", err)
+	}
+
+	return tracker.Sync(cfg.Subreddits, cfg.SubredditWeights)
+}
+
+// publishTransition returns an observer publishing each circuit transition to the bus.
+func publishTransition(bus *events.Bus) func(t breaker.Transition) {
+	return func(t breaker.Transition) {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return
+		}
+
+		bus.Publish(events.Event{Kind: breaker.EventKind, Subreddit: t.Key, Time: t.Time, Data: data})
+	}
+}
+
+// logSummaries logs how each job fared before shutting down.
+func logSummaries(logr *slog.Logger, summaries []orchestrator.Summary) {
+	for _, s := range summaries {
+		attrs := []any{"job", s.Name, "interval", s.Interval, "runs", s.Runs, "failures", s.Failures,
+			"disabled", s.Disabled, "interrupted", s.Interrupted}
+		if s.LastErr != nil {
+			attrs = append(attrs, "last_err", s.LastErr.Error())
+		}
+
+		logr.Info("job summary", attrs...)
 	}
 }
 
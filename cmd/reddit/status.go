@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/orchestrator"
+)
+
+const (
+	statusTimeout = 5 * time.Second
+	// statusSnippetLen bounds how much of an error response is shown.
+	statusSnippetLen = 200
+)
+
+var errNoHTTPAddr = errors.New("job status needs the API, set REDDIT_HTTP_ADDR")
+
+// StatusError is returned when the monitor answers the status request with an error.
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("job status: unexpected status code %d: %s", e.Status, e.Body)
+}
+
+func NewStatusError(status int, body string) *StatusError {
+	return &StatusError{Status: status, Body: body}
+}
+
+// printStatus fetches the status of each job from the API of the monitor listening on addr and
+// prints it as a table.
+func printStatus(w io.Writer, addr string) error {
+	if addr == "" {
+		return errNoHTTPAddr
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("parse http addr: %w", err)
+	}
+
+	if host == "" {
+		host = "localhost"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+net.JoinHostPort(host, port)+"/jobs", nil)
+	if err != nil {
+		return fmt.Errorf("create status request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch job status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, statusSnippetLen))
+
+		return NewStatusError(resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	var body struct {
+		Jobs []orchestrator.Status `json:"jobs"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode job status: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSTATE\tINTERVAL\tRUNS\tFAILURES\tCONSECUTIVE\tLAST START\tLAST DURATION\tNEXT RUN\tLAST ERROR")
+
+	for _, job := range body.Jobs {
+		// Adaptive intervals are paced to the subreddit's activity, so whole seconds are precise enough.
+		interval := time.Duration(job.IntervalSeconds * float64(time.Second)).Round(time.Second)
+		lastDuration := time.Duration(job.LastDurationSeconds * float64(time.Second)).Round(time.Millisecond)
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", job.Name, jobState(job), interval, job.Runs,
+			job.Failures, job.ConsecutiveFailures, formatTime(job.LastStart), lastDuration, formatTime(job.NextRun), job.LastError)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("print job status: %w", err)
+	}
+
+	return nil
+}
+
+func jobState(job orchestrator.Status) string {
+	switch {
+	case job.Disabled:
+		return "disabled"
+	case job.Waiting:
+		return "waiting"
+	case job.Running:
+		return "running"
+	default:
+		return "scheduled"
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/breaker"
+	"github.com/jqdurham/reddit/internal/budget"
+	"github.com/jqdurham/reddit/internal/config"
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/metrics"
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/service/post"
+)
+
+// jobKinds lists the jobs run for every tracked subreddit.
+var jobKinds = []string{"top-posts", "top-authors", "sentiment", "removals"}
+
+// subreddits registers each tracked subreddit's jobs with the runner, and unregisters them and
+// drops the subreddit's statistics once it is no longer tracked. When instances divide the
+// subreddits between them, only the tracked subreddits this instance owns are polled.
+type subreddits struct {
+	cfg       *config.Config
+	runner    *orchestrator.Runner
+	posts     *post.Service
+	scheduler *budget.Scheduler
+	breaker   *breaker.Breaker
+	recorder  metrics.Recorder
+	logr      logger.Logger
+	// owns reports whether this instance polls the subreddit; nil polls every subreddit.
+	owns func(subreddit string) bool
+
+	mu      sync.Mutex
+	tracked []string
+	// weights maps subreddit -> weight of its jobs, as last configured.
+	weights map[string]float64
+	// polled holds the tracked subreddits whose jobs are registered.
+	polled map[string]bool
+	// pacers maps subreddit -> pace of its sentiment job when polling adaptively.
+	pacers map[string]*orchestrator.Adaptive
+}
+
+// Track tracks the subreddit, registering its jobs if this instance owns it, returning false if it
+// is already tracked.
+func (s *subreddits) Track(subreddit string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.Contains(s.tracked, subreddit) {
+		return false, nil
+	}
+
+	polled := s.owned(subreddit)
+	if polled {
+		if err := s.start(subreddit); err != nil {
+			return false, err
+		}
+	}
+
+	s.tracked = append(s.tracked, subreddit)
+
+	s.logr.Info("tracking subreddit", "subreddit", subreddit, "polled", polled)
+
+	return true, nil
+}
+
+// Untrack unregisters the subreddit's jobs and drops its statistics, returning false if it was not
+// tracked. A run in flight is left to finish, but the listings it fetched are discarded.
+func (s *subreddits) Untrack(subreddit string) bool {
+	s.mu.Lock()
+
+	i := slices.Index(s.tracked, subreddit)
+	if i < 0 {
+		s.mu.Unlock()
+
+		return false
+	}
+
+	stopped := s.polled[subreddit]
+	if stopped {
+		s.stop(subreddit)
+	}
+
+	s.tracked = slices.Delete(s.tracked, i, i+1)
+	s.mu.Unlock()
+
+	if stopped {
+		s.forget(subreddit)
+	}
+
+	s.logr.Info("untracked subreddit", "subreddit", subreddit)
+
+	return true
+}
+
+// Tracked lists the tracked subreddits in the order they were added.
+func (s *subreddits) Tracked() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.tracked)
+}
+
+// Sync tracks exactly the listed subreddits and weighs them as provided, e.g. after the
+// configuration is reloaded.
+func (s *subreddits) Sync(want []string, weights map[string]float64) error {
+	var errs []error
+
+	s.reweigh(weights)
+
+	for _, subreddit := range s.Tracked() {
+		if !slices.Contains(want, subreddit) {
+			s.Untrack(subreddit)
+		}
+	}
+
+	for _, subreddit := range want {
+		if _, err := s.Track(subreddit); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Rebalance polls the tracked subreddits this instance now owns and stops polling, dropping the
+// statistics of, those it no longer owns, e.g. once another instance joins or leaves.
+func (s *subreddits) Rebalance() error {
+	var (
+		gained, lost []string
+		errs         []error
+	)
+
+	s.mu.Lock()
+
+	for _, subreddit := range s.tracked {
+		switch owned := s.owned(subreddit); {
+		case owned && !s.polled[subreddit]:
+			if err := s.start(subreddit); err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			gained = append(gained, subreddit)
+		case !owned && s.polled[subreddit]:
+			s.stop(subreddit)
+
+			lost = append(lost, subreddit)
+		}
+	}
+
+	polled := len(s.polled)
+	s.mu.Unlock()
+
+	s.forget(lost...)
+
+	if len(gained) > 0 || len(lost) > 0 {
+		s.logr.Info("subreddits rebalanced", "gained", gained, "lost", lost, "polled", polled)
+	}
+
+	return errors.Join(errs...)
+}
+
+// reweigh replaces the subreddit weights, updating those of the polled subreddits' jobs.
+func (s *subreddits) reweigh(weights map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.weights = weights
+
+	for subreddit := range s.polled {
+		for _, kind := range jobKinds {
+			s.scheduler.SetWeight(kind+":"+subreddit, weight(s.cfg.JobWeights, kind)*weight(s.weights, subreddit))
+		}
+	}
+}
+
+// owned reports whether this instance polls the subreddit.
+func (s *subreddits) owned(subreddit string) bool {
+	return s.owns == nil || s.owns(subreddit)
+}
+
+// start registers the subreddit's jobs.
+func (s *subreddits) start(subreddit string) error {
+	var pacer *orchestrator.Adaptive
+	if s.cfg.AdaptivePolling {
+		pacer = orchestrator.NewAdaptive(s.cfg.JobIntervals["sentiment"],
+			s.cfg.PollMinInterval, s.cfg.PollMaxInterval, s.cfg.PollTargetNewPosts)
+	}
+
+	for i, kind := range jobKinds {
+		spec := s.spec(kind, subreddit)
+		if kind == "sentiment" {
+			spec.Adaptive = pacer
+		}
+
+		s.scheduler.SetWeight(spec.Name, weight(s.cfg.JobWeights, kind)*weight(s.weights, subreddit))
+
+		if err := s.runner.Add(spec); err != nil {
+			// The job which failed to register was not added, so only its weight is dropped.
+			s.scheduler.Forget(spec.Name)
+
+			for _, added := range jobKinds[:i] {
+				s.runner.Remove(added + ":" + subreddit)
+				s.scheduler.Forget(added + ":" + subreddit)
+			}
+
+			return err
+		}
+	}
+
+	s.polled[subreddit] = true
+	if pacer != nil {
+		s.pacers[subreddit] = pacer
+	}
+
+	return nil
+}
+
+// stop unregisters the subreddit's jobs and drops its circuit. Its statistics are dropped by forget once s.mu is
+// released.
+func (s *subreddits) stop(subreddit string) {
+	for _, kind := range jobKinds {
+		s.runner.Remove(kind + ":" + subreddit)
+		s.scheduler.Forget(kind + ":" + subreddit)
+	}
+
+	s.breaker.Forget(subreddit)
+	delete(s.polled, subreddit)
+	delete(s.pacers, subreddit)
+}
+
+// forget drops the statistics of stopped subreddits. It is called without holding s.mu, as
+// forgetting waits for the listings being recorded and the pipeline observes arrivals under s.mu.
+func (s *subreddits) forget(subreddits ...string) {
+	for _, subreddit := range subreddits {
+		s.posts.Forget(subreddit)
+	}
+}
+
+// observeArrivals adjusts the subreddit's polling pace to how many new posts a poll found.
+func (s *subreddits) observeArrivals(subreddit string, arrived int, elapsed time.Duration) {
+	s.mu.Lock()
+	pacer := s.pacers[subreddit]
+	s.mu.Unlock()
+
+	if pacer == nil {
+		return
+	}
+
+	before := pacer.Interval().Round(time.Second)
+
+	if after := pacer.Observe(arrived, elapsed).Round(time.Second); after != before {
+		s.logr.Info("poll interval adjusted", "subreddit", subreddit, "interval", after,
+			"arrived", arrived, "elapsed", elapsed.Round(time.Second))
+	}
+}
+
+// globalSpecs describes the jobs reporting across every polled subreddit, which run once rather
+// than for each subreddit. They make no requests, and first run once the subreddits' jobs had time
+// to gather statistics.
+func (s *subreddits) globalSpecs() []orchestrator.Spec {
+	jobs := []struct {
+		name, kind string
+		job        orchestrator.Job
+	}{
+		{name: "author-overlap", kind: "top-authors", job: func(ctx context.Context) error {
+			return s.posts.ReportAuthorOverlap(ctx, s.cfg.TopNAuthors)
+		}},
+		{name: "removal-rates", kind: "removals", job: s.posts.ReportRemovalRates},
+	}
+
+	specs := make([]orchestrator.Spec, 0, len(jobs))
+	for _, job := range jobs {
+		specs = append(specs, orchestrator.Spec{
+			Name:         job.name,
+			Job:          orchestrator.Instrument(job.name, s.recorder, job.job),
+			Interval:     s.cfg.JobIntervals[job.kind],
+			InitialDelay: s.cfg.JobIntervals[job.kind],
+			Jitter:       s.cfg.JobJitter,
+			Policy:       s.cfg.JobPolicy,
+		})
+	}
+
+	return specs
+}
+
+// spec describes a job run every interval configured for its kind, charging its requests to its
+// own budget.
+func (s *subreddits) spec(kind, subreddit string) orchestrator.Spec {
+	name := kind + ":" + subreddit
+	job := s.job(kind, subreddit)
+	charged := func(ctx context.Context) error {
+		return job(budget.WithJob(ctx, name))
+	}
+
+	return orchestrator.Spec{
+		Name:     name,
+		Job:      orchestrator.Instrument(name, s.recorder, charged),
+		Interval: s.cfg.JobIntervals[kind],
+		Jitter:   s.cfg.JobJitter,
+		Policy:   s.cfg.JobPolicy,
+		Waiting:  func() bool { return s.scheduler.Waiting(name) },
+	}
+}
+
+func (s *subreddits) job(kind, subreddit string) orchestrator.Job {
+	switch kind {
+	case "top-posts":
+		return func(ctx context.Context) error {
+			// The domains are reported once the top posts are aggregated, so they include them.
+			return s.posts.UpdateTopPosts(ctx, subreddit, func(ctx context.Context) error {
+				return s.posts.UpdateDomains(ctx, subreddit, s.cfg.TopNAuthors)
+			})
+		}
+	case "top-authors":
+		return func(ctx context.Context) error {
+			return s.posts.UpdateTopNAuthors(ctx, subreddit, s.cfg.TopNAuthors)
+		}
+	case "sentiment":
+		return func(ctx context.Context) error {
+			return s.posts.UpdateSentiment(ctx, subreddit, sentimentExtremes, func(ctx context.Context) error {
+				return s.posts.UpdateActivity(ctx, subreddit)
+			})
+		}
+	default:
+		return func(ctx context.Context) error {
+			return s.posts.ReportRemovals(ctx, subreddit, s.cfg.TopNAuthors)
+		}
+	}
+}
+
+// weight returns the configured weight of name, 1 by default.
+func weight(weights map[string]float64, name string) float64 {
+	if w, ok := weights[name]; ok {
+		return w
+	}
+
+	return 1
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// subredditPattern matches valid subreddit names.
+var subredditPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_]{1,20}$`)
+
+// authorize rejects requests without the admin bearer token.
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, NewUnauthorizedError())
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) trackedSubreddits(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"subreddits": s.admin.Tracked()})
+}
+
+func (s *Server) trackSubreddit(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !subredditPattern.MatchString(name) {
+		writeError(w, NewInvalidParamError("name", "not a subreddit name"))
+
+		return
+	}
+
+	added, err := s.admin.Track(name)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+
+	writeJSON(w, status, map[string]any{"subreddit": name, "tracked": true})
+}
+
+func (s *Server) untrackSubreddit(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !s.admin.Untrack(name) {
+		writeError(w, NewUnknownSubredditError(name))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
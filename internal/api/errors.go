@@ -0,0 +1,51 @@
+package api
+
+// InvalidParamError is returned when a query parameter cannot be used.
+type InvalidParamError struct {
+	param, reason string
+}
+
+func (e *InvalidParamError) Error() string {
+	return "invalid query param: " + e.param + " reason: " + e.reason
+}
+
+func NewInvalidParamError(param, reason string) *InvalidParamError {
+	return &InvalidParamError{param: param, reason: reason}
+}
+
+// UnknownSubredditError is returned when nothing has been recorded for the requested subreddit.
+type UnknownSubredditError struct {
+	subreddit string
+}
+
+func (e *UnknownSubredditError) Error() string {
+	return "unknown subreddit: " + e.subreddit
+}
+
+func NewUnknownSubredditError(subreddit string) *UnknownSubredditError {
+	return &UnknownSubredditError{subreddit: subreddit}
+}
+
+// HandshakeError is returned when a WebSocket upgrade request is malformed.
+type HandshakeError struct {
+	reason string
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket handshake: " + e.reason
+}
+
+func NewHandshakeError(reason string) *HandshakeError {
+	return &HandshakeError{reason: reason}
+}
+
+// UnauthorizedError is returned when an admin request lacks the admin token.
+type UnauthorizedError struct{}
+
+func (e *UnauthorizedError) Error() string {
+	return "unauthorized"
+}
+
+func NewUnauthorizedError() *UnauthorizedError {
+	return &UnauthorizedError{}
+}
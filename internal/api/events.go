@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+const (
+	// subscriptionBuffer bounds the events queued for a slow client before the oldest are dropped.
+	subscriptionBuffer = 64
+	// keepAliveInterval keeps idle streams from being closed by proxies.
+	keepAliveInterval = 15 * time.Second
+)
+
+// streamEvents pushes events to the client as Server-Sent Events until it disconnects.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errors.New("streaming unsupported"))
+
+		return
+	}
+
+	sub := s.bus.Subscribe(eventFilter(r), subscriptionBuffer)
+	defer s.bus.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			if err := writeSSE(w, ev); err != nil {
+				logger.FromContext(r.Context()).Debug("write event", "err", err.Error())
+
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeSSE(w io.Writer, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Kind, data); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
+
+// eventFilter reads the subreddit and kind query params. Each may be repeated or comma separated.
+func eventFilter(r *http.Request) events.Filter {
+	params := r.URL.Query()
+
+	return events.Filter{Subreddits: splitParam(params["subreddit"]), Kinds: splitParam(params["kind"])}
+}
+
+func splitParam(values []string) []string {
+	var out []string
+
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,220 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/api"
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StreamEvents(t *testing.T) {
+	t.Parallel()
+
+	bus := events.NewBus()
+	srv := httptest.NewServer(api.NewServer("", stats.NewStore(), api.WithEvents(bus)).Handler())
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events?subreddit=golang&kind=mood,top-posts", nil)
+	require.NoError(t, err)
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	bus.Publish(events.Event{Kind: "mood", Subreddit: "rust", Time: now, Data: json.RawMessage(`{}`)})
+	bus.Publish(events.Event{Kind: "domains", Subreddit: "golang", Time: now, Data: json.RawMessage(`{}`)})
+	bus.Publish(events.Event{Kind: "top-posts", Subreddit: "golang", Time: now, Data: json.RawMessage(`{"rows":[]}`)})
+
+	reader := bufio.NewReader(resp.Body)
+
+	var lines []string
+
+	for len(lines) < 4 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	require.Equal(t, []string{
+		"id: 3",
+		"event: top-posts",
+		`data: {"id":3,"kind":"top-posts","subreddit":"golang","time":"2024-04-01T12:00:00Z","data":{"rows":[]}}`,
+		"",
+	}, lines)
+}
+
+func TestServer_StreamWebSocket(t *testing.T) {
+	t.Parallel()
+
+	bus := events.NewBus()
+	srv := httptest.NewServer(api.NewServer("", stats.NewStore(), api.WithEvents(bus)).Handler())
+	t.Cleanup(srv.Close)
+
+	t.Run("Invalid handshake", func(t *testing.T) {
+		t.Parallel()
+
+		resp, err := srv.Client().Get(srv.URL + "/events/ws")
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.JSONEq(t, `{"error":"websocket handshake: missing Connection: Upgrade header"}`, string(body))
+	})
+
+	t.Run("Stream events", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+		_, err = io.WriteString(conn, "GET /events/ws?kind=mood HTTP/1.1\r\nHost: localhost\r\n"+
+			"Connection: keep-alive, Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\n"+
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(conn)
+
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		// Example key and accept value from RFC 6455.
+		require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+		bus.Publish(events.Event{Kind: "domains", Subreddit: "golang", Data: json.RawMessage(`{}`)})
+		bus.Publish(events.Event{Kind: "mood", Subreddit: "golang", Data: json.RawMessage(`{}`)})
+
+		opcode, payload := readFrame(t, reader)
+		require.Equal(t, byte(0x1), opcode)
+		require.JSONEq(t, `{"id":2,"kind":"mood","subreddit":"golang","time":"0001-01-01T00:00:00Z","data":{}}`,
+			string(payload))
+
+		writeFrame(t, conn, 0x9, []byte("ping"))
+
+		opcode, payload = readFrame(t, reader)
+		require.Equal(t, byte(0xA), opcode)
+		require.Equal(t, "ping", string(payload))
+
+		writeFrame(t, conn, 0x8, binary.BigEndian.AppendUint16(nil, 1000))
+
+		opcode, payload = readFrame(t, reader)
+		require.Equal(t, byte(0x8), opcode)
+		require.Equal(t, uint16(1000), binary.BigEndian.Uint16(payload))
+	})
+}
+
+func TestServer_StreamWebSocket_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	// Reserve a free port for the server to listen on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	done := make(chan error)
+	go func() {
+		done <- api.NewServer(addr, stats.NewStore(), api.WithEvents(events.NewBus())).Run(ctx)
+	}()
+
+	var conn net.Conn
+
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", addr)
+
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = io.WriteString(conn, "GET /events/ws HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\n"+
+		"Upgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	cancel()
+
+	// The client is told the server is going away and the connection closed.
+	opcode, payload := readFrame(t, reader)
+	require.Equal(t, byte(0x8), opcode)
+	require.Equal(t, uint16(1001), binary.BigEndian.Uint16(payload))
+
+	_, err = reader.ReadByte()
+	require.ErrorIs(t, err, io.EOF)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func readFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(reader, header)
+	require.NoError(t, err)
+
+	size := int(header[1] & 0x7F)
+	if size == 126 {
+		ext := make([]byte, 2)
+		_, err = io.ReadFull(reader, ext)
+		require.NoError(t, err)
+
+		size = int(binary.BigEndian.Uint16(ext))
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(reader, payload)
+	require.NoError(t, err)
+
+	return header[0] & 0x0F, payload
+}
+
+func writeFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%len(mask)])
+	}
+
+	_, err := conn.Write(frame)
+	require.NoError(t, err)
+}
@@ -0,0 +1,14 @@
+package api
+
+// Admin changes which subreddits are tracked while running.
+//
+//go:generate mockery --name Admin
+type Admin interface {
+	// Track starts tracking a subreddit, returning false if it already is.
+	Track(subreddit string) (bool, error)
+	// Untrack stops tracking a subreddit and drops its statistics, returning false if it was not
+	// tracked.
+	Untrack(subreddit string) bool
+	// Tracked lists the tracked subreddits.
+	Tracked() []string
+}
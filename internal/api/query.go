@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+// parseQuery reads the limit, window and sort query params shared by the leaderboard endpoints.
+func (s *Server) parseQuery(r *http.Request, sorts []string) (stats.Query, error) {
+	var (
+		params = r.URL.Query()
+		query  = stats.Query{Limit: defaultLimit, Sort: sorts[0]}
+	)
+
+	if v := params.Get("limit"); v != "" {
+		num, err := strconv.Atoi(v)
+		if err != nil || num < 1 || num > maxLimit {
+			return stats.Query{}, NewInvalidParamError("limit", "must be between 1 and "+strconv.Itoa(maxLimit))
+		}
+
+		query.Limit = num
+	}
+
+	if v := params.Get("window"); v != "" {
+		window, err := time.ParseDuration(v)
+		if err != nil || window <= 0 {
+			return stats.Query{}, NewInvalidParamError("window", "must be a positive duration, e.g. 30m or 24h")
+		}
+
+		query.Since = s.now().Add(-window)
+	}
+
+	if v := params.Get("sort"); v != "" {
+		if !slices.Contains(sorts, v) {
+			return stats.Query{}, NewInvalidParamError("sort", "must be: "+strings.Join(sorts, ", "))
+		}
+
+		query.Sort = v
+	}
+
+	return query, nil
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/stats"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+// Server exposes the in-process statistics over HTTP.
+type Server struct {
+	store      *stats.Store
+	bus        *events.Bus
+	admin      Admin
+	adminToken string
+	mux        *http.ServeMux
+	httpServer *http.Server
+	now        func() time.Time
+
+	// sockets holds the hijacked WebSocket connections, which Shutdown neither tracks nor closes.
+	mu      sync.Mutex
+	sockets map[*wsConn]struct{}
+}
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// WithClock overrides the time source used to resolve query windows.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WithEvents streams the bus's events to subscribers over Server-Sent Events and WebSocket.
+func WithEvents(bus *events.Bus) Option {
+	return func(s *Server) {
+		s.bus = bus
+	}
+}
+
+// WithAdmin lets requests bearing token change the tracked subreddits under /admin. Without a
+// token the admin routes are not served.
+func WithAdmin(admin Admin, token string) Option {
+	return func(s *Server) {
+		s.admin = admin
+		s.adminToken = token
+	}
+}
+
+// NewServer creates a Server that will listen on addr once run.
+func NewServer(addr string, store *stats.Store, opts ...Option) *Server {
+	srv := &Server{
+		store:   store,
+		mux:     http.NewServeMux(),
+		now:     time.Now,
+		sockets: map[*wsConn]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	srv.mux.HandleFunc("GET /subreddits", srv.subreddits)
+	srv.mux.HandleFunc("GET /subreddits/{name}/top-posts", srv.topPosts)
+	srv.mux.HandleFunc("GET /subreddits/{name}/top-authors", srv.topAuthors)
+
+	if srv.admin != nil && srv.adminToken != "" {
+		srv.mux.HandleFunc("GET /admin/subreddits", srv.authorize(srv.trackedSubreddits))
+		srv.mux.HandleFunc("PUT /admin/subreddits/{name}", srv.authorize(srv.trackSubreddit))
+		srv.mux.HandleFunc("DELETE /admin/subreddits/{name}", srv.authorize(srv.untrackSubreddit))
+	}
+
+	if srv.bus != nil {
+		srv.mux.HandleFunc("GET /events", srv.streamEvents)
+		srv.mux.HandleFunc("GET /events/ws", srv.streamWebSocket)
+	}
+
+	srv.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           srv.mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	srv.httpServer.RegisterOnShutdown(srv.closeSockets)
+
+	return srv
+}
+
+// Handle registers an additional handler, such as a metrics or status endpoint.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the server's routes.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Run serves requests until the context is cancelled, then shuts down gracefully, allowing
+// in-flight requests to complete and closing WebSocket connections.
+func (s *Server) Run(ctx context.Context) error {
+	logr := logger.FromContext(ctx)
+
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	s.httpServer.BaseContext = func(_ net.Listener) context.Context {
+		return ctx
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- s.httpServer.Serve(listener)
+	}()
+
+	logr.Info("http server listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	logr.Info("http server stopped")
+
+	return nil
+}
+
+func (s *Server) subreddits(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"subreddits": s.store.Subreddits()})
+}
+
+func (s *Server) topPosts(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	query, err := s.parseQuery(r, stats.PostSorts())
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if !s.store.HasSubreddit(name) {
+		writeError(w, NewUnknownSubredditError(name))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"subreddit": name, "posts": s.store.TopPosts(name, query)})
+}
+
+func (s *Server) topAuthors(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	query, err := s.parseQuery(r, stats.AuthorSorts())
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	if !s.store.HasSubreddit(name) {
+		writeError(w, NewUnknownSubredditError(name))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"subreddit": name, "authors": s.store.TopAuthors(name, query)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	var (
+		invalidParam     *InvalidParamError
+		unknownSubreddit *UnknownSubredditError
+		handshake        *HandshakeError
+		unauthorized     *UnauthorizedError
+	)
+
+	switch {
+	case errors.As(err, &invalidParam), errors.As(err, &handshake):
+		status = http.StatusBadRequest
+	case errors.As(err, &unknownSubreddit):
+		status = http.StatusNotFound
+	case errors.As(err, &unauthorized):
+		status = http.StatusUnauthorized
+	}
+
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,262 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/api"
+	apimocks "github.com/jqdurham/reddit/internal/api/mocks"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Handler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	store := stats.NewStore()
+	store.SetAuthorCounts("golang", map[string]int{"alice": 2, "bob": 1})
+	store.RecordPosts("golang", now,
+		stats.PostStat{
+			Name: "t3_a", Title: "Old", Author: "alice", Permalink: "/r/golang/comments/a/",
+			Score: 10, Comments: 1, Created: now.Add(-3 * time.Hour),
+		},
+		stats.PostStat{
+			Name: "t3_b", Title: "New", Author: "bob", Permalink: "/r/golang/comments/b/",
+			Score: 5, Comments: 9, Created: now.Add(-time.Hour),
+		},
+	)
+
+	handler := api.NewServer("", store, api.WithClock(func() time.Time { return now })).Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{
+			name:   "List subreddits",
+			target: "/subreddits",
+			status: http.StatusOK,
+			body: `{"subreddits":[{"name":"golang","posts":2,"authors":2,` +
+				`"updated":"2024-04-01T12:00:00Z"}]}`,
+		},
+		{
+			name:   "Top posts by comments",
+			target: "/subreddits/golang/top-posts?sort=comments&limit=1",
+			status: http.StatusOK,
+			body: `{"posts":[{"name":"t3_b","title":"New","author":"bob","permalink":"/r/golang/comments/b/",` +
+				`"score":5,"comments":9,"created":"2024-04-01T11:00:00Z"}],"subreddit":"golang"}`,
+		},
+		{
+			name:   "Top posts within window",
+			target: "/subreddits/golang/top-posts?window=2h",
+			status: http.StatusOK,
+			body: `{"posts":[{"name":"t3_b","title":"New","author":"bob","permalink":"/r/golang/comments/b/",` +
+				`"score":5,"comments":9,"created":"2024-04-01T11:00:00Z"}],"subreddit":"golang"}`,
+		},
+		{
+			name:   "Top authors",
+			target: "/subreddits/golang/top-authors",
+			status: http.StatusOK,
+			body: `{"authors":[{"author":"alice","posts":2,"score":10},{"author":"bob","posts":1,"score":5}],` +
+				`"subreddit":"golang"}`,
+		},
+		{
+			name:   "Top authors by score",
+			target: "/subreddits/golang/top-authors?sort=score&limit=1",
+			status: http.StatusOK,
+			body:   `{"authors":[{"author":"alice","posts":2,"score":10}],"subreddit":"golang"}`,
+		},
+		{
+			name:   "Unknown subreddit",
+			target: "/subreddits/rust/top-posts",
+			status: http.StatusNotFound,
+			body:   `{"error":"unknown subreddit: rust"}`,
+		},
+		{
+			name:   "Invalid limit",
+			target: "/subreddits/golang/top-posts?limit=1000",
+			status: http.StatusBadRequest,
+			body:   `{"error":"invalid query param: limit reason: must be between 1 and 100"}`,
+		},
+		{
+			name:   "Invalid window",
+			target: "/subreddits/golang/top-authors?window=yesterday",
+			status: http.StatusBadRequest,
+			body:   `{"error":"invalid query param: window reason: must be a positive duration, e.g. 30m or 24h"}`,
+		},
+		{
+			name:   "Invalid sort",
+			target: "/subreddits/golang/top-authors?sort=comments",
+			status: http.StatusBadRequest,
+			body:   `{"error":"invalid query param: sort reason: must be: posts, score"}`,
+		},
+		{
+			name:   "Method not allowed",
+			method: http.MethodPost,
+			target: "/subreddits",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, tt.target, nil))
+
+			require.Equal(t, tt.status, rec.Code)
+
+			if tt.body != "" {
+				require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				require.JSONEq(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_Admin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		admin  func(m *apimocks.Admin)
+		status int
+		body   string
+	}{
+		{
+			name:   "List tracked subreddits",
+			method: http.MethodGet,
+			target: "/admin/subreddits",
+			token:  "s3cr3t",
+			admin:  func(m *apimocks.Admin) { m.On("Tracked").Return([]string{"golang", "rust"}).Once() },
+			status: http.StatusOK,
+			body:   `{"subreddits":["golang","rust"]}`,
+		},
+		{
+			name:   "Track subreddit",
+			method: http.MethodPut,
+			target: "/admin/subreddits/rust",
+			token:  "s3cr3t",
+			admin:  func(m *apimocks.Admin) { m.On("Track", "rust").Return(true, nil).Once() },
+			status: http.StatusCreated,
+			body:   `{"subreddit":"rust","tracked":true}`,
+		},
+		{
+			name:   "Track subreddit already tracked",
+			method: http.MethodPut,
+			target: "/admin/subreddits/golang",
+			token:  "s3cr3t",
+			admin:  func(m *apimocks.Admin) { m.On("Track", "golang").Return(false, nil).Once() },
+			status: http.StatusOK,
+			body:   `{"subreddit":"golang","tracked":true}`,
+		},
+		{
+			name:   "Track invalid name",
+			method: http.MethodPut,
+			target: "/admin/subreddits/r%2Fgolang",
+			token:  "s3cr3t",
+			status: http.StatusBadRequest,
+			body:   `{"error":"invalid query param: name reason: not a subreddit name"}`,
+		},
+		{
+			name:   "Untrack subreddit",
+			method: http.MethodDelete,
+			target: "/admin/subreddits/golang",
+			token:  "s3cr3t",
+			admin:  func(m *apimocks.Admin) { m.On("Untrack", "golang").Return(true).Once() },
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "Untrack subreddit not tracked",
+			method: http.MethodDelete,
+			target: "/admin/subreddits/rust",
+			token:  "s3cr3t",
+			admin:  func(m *apimocks.Admin) { m.On("Untrack", "rust").Return(false).Once() },
+			status: http.StatusNotFound,
+			body:   `{"error":"unknown subreddit: rust"}`,
+		},
+		{
+			name:   "Wrong token",
+			method: http.MethodDelete,
+			target: "/admin/subreddits/golang",
+			token:  "guess",
+			status: http.StatusUnauthorized,
+			body:   `{"error":"unauthorized"}`,
+		},
+		{
+			name:   "Missing token",
+			method: http.MethodGet,
+			target: "/admin/subreddits",
+			status: http.StatusUnauthorized,
+			body:   `{"error":"unauthorized"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			admin := apimocks.NewAdmin(t)
+			if tt.admin != nil {
+				tt.admin(admin)
+			}
+
+			handler := api.NewServer("", stats.NewStore(), api.WithAdmin(admin, "s3cr3t")).Handler()
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.status, rec.Code)
+
+			if tt.body != "" {
+				require.JSONEq(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+
+	// Without a token the admin routes are not served.
+	rec := httptest.NewRecorder()
+	api.NewServer("", stats.NewStore(), api.WithAdmin(apimocks.NewAdmin(t), "")).Handler().
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/subreddits", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- api.NewServer("127.0.0.1:0", stats.NewStore()).Run(ctx)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
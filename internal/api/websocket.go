@@ -0,0 +1,354 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // mandated by RFC 6455 for the handshake, not used for security.
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opContinuation = 0x0
+	opText         = 0x1
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+
+	finBit     = 0x80
+	maskBit    = 0x80
+	opcodeMask = 0x0F
+
+	// Frame layout: a 2 byte header, an optional 2 or 8 byte extended length and, for client
+	// frames, a 4 byte masking key.
+	frameHeaderLen    = 2
+	frameHeaderMaxLen = 14
+	payloadLen16      = 126
+	payloadLen64      = 127
+	len16Bytes        = 2
+	len64Bytes        = 8
+	maskKeyLen        = 4
+	maxPayloadLen7    = 125
+	maxPayloadLen16   = 0xFFFF
+
+	// maxClientPayload bounds frames accepted from clients, which are not expected to send data.
+	maxClientPayload  = 4096
+	maxControlPayload = 125
+	frameWriteTimeout = 10 * time.Second
+
+	closeNormal      = 1000
+	closeGoingAway   = 1001
+	closeProtocolErr = 1002
+	closeTooBig      = 1009
+	closeCodeLen     = 2
+)
+
+var (
+	errUnmaskedFrame = errors.New("client frame is not masked")
+	errFrameTooBig   = errors.New("client frame too big")
+)
+
+// streamWebSocket upgrades the connection and pushes events as text messages until either side
+// closes it. Pings from the client are answered and any data it sends is discarded.
+func (s *Server) streamWebSocket(w http.ResponseWriter, r *http.Request) {
+	logr := logger.FromContext(r.Context())
+
+	// Subscribe before completing the handshake so no event published afterwards is missed.
+	sub := s.bus.Subscribe(eventFilter(r), subscriptionBuffer)
+	defer s.bus.Unsubscribe(sub)
+
+	conn, err := upgrade(w, r)
+	if err != nil {
+		var handshake *HandshakeError
+		if errors.As(err, &handshake) {
+			writeError(w, err)
+		} else {
+			logr.Warn("websocket upgrade", "err", err.Error())
+		}
+
+		return
+	}
+	defer conn.Close()
+
+	s.track(conn)
+	defer s.untrack(conn)
+
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		if err := conn.readLoop(); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+			logr.Debug("websocket read", "err", err.Error())
+		}
+	}()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-r.Context().Done():
+			_ = conn.writeClose(closeGoingAway)
+
+			return
+		case <-closed:
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				_ = conn.writeClose(closeNormal)
+
+				return
+			}
+
+			var data []byte
+			if data, err = json.Marshal(ev); err == nil {
+				err = conn.writeFrame(opText, data)
+			}
+		case <-keepAlive.C:
+			err = conn.writeFrame(opPing, nil)
+		}
+
+		if err != nil {
+			logr.Debug("websocket write", "err", err.Error())
+
+			return
+		}
+	}
+}
+
+func (s *Server) track(conn *wsConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sockets[conn] = struct{}{}
+}
+
+func (s *Server) untrack(conn *wsConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sockets, conn)
+}
+
+// closeSockets tells every WebSocket client the server is going away and closes its connection,
+// which ends the stream.
+func (s *Server) closeSockets() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.sockets {
+		_ = conn.writeClose(closeGoingAway)
+		_ = conn.Close()
+	}
+}
+
+// wsConn is the server side of a WebSocket connection (RFC 6455) which only sends unfragmented
+// messages.
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	mu   sync.Mutex
+}
+
+// upgrade validates the handshake and takes over the connection. A HandshakeError is returned,
+// without writing a response, when the request is not a valid upgrade.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	switch {
+	case !headerContains(r.Header, "Connection", "upgrade"):
+		return nil, NewHandshakeError("missing Connection: Upgrade header")
+	case !headerContains(r.Header, "Upgrade", "websocket"):
+		return nil, NewHandshakeError("missing Upgrade: websocket header")
+	case r.Header.Get("Sec-Websocket-Version") != "13":
+		return nil, NewHandshakeError("unsupported version, must be 13")
+	case r.Header.Get("Sec-Websocket-Key") == "":
+		return nil, NewHandshakeError("missing Sec-WebSocket-Key header")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection cannot be hijacked")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijack: %w", err)
+	}
+
+	_, err = fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-Websocket-Key")))
+	if err == nil {
+		err = rw.Flush()
+	}
+
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("write handshake: %w", err)
+	}
+
+	return &wsConn{conn: conn, br: rw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	//nolint:gosec // mandated by RFC 6455.
+	sum := sha1.Sum([]byte(key + websocketGUID))
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Close closes the underlying connection.
+func (c *wsConn) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	return nil
+}
+
+// writeFrame sends a single unmasked, final frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := make([]byte, frameHeaderLen, frameHeaderMaxLen)
+	header[0] = finBit | opcode
+
+	switch size := len(payload); {
+	case size <= maxPayloadLen7:
+		header[1] = byte(size)
+	case size <= maxPayloadLen16:
+		header[1] = payloadLen16
+		header = binary.BigEndian.AppendUint16(header, uint16(size))
+	default:
+		header[1] = payloadLen64
+		header = binary.BigEndian.AppendUint64(header, uint64(size))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(frameWriteTimeout)); err != nil {
+		return fmt.Errorf("set deadline: %w", err)
+	}
+
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return fmt.Errorf("write frame: %w", err)
+	}
+
+	return nil
+}
+
+func (c *wsConn) writeClose(code uint16) error {
+	return c.writeFrame(opClose, binary.BigEndian.AppendUint16(nil, code))
+}
+
+// readLoop consumes client frames until the connection is closed, answering pings and close
+// requests.
+func (c *wsConn) readLoop() error {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			switch {
+			case errors.Is(err, errFrameTooBig):
+				_ = c.writeClose(closeTooBig)
+			case errors.Is(err, errUnmaskedFrame):
+				_ = c.writeClose(closeProtocolErr)
+			}
+
+			return err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			code := uint16(closeNormal)
+			if len(payload) >= closeCodeLen {
+				code = binary.BigEndian.Uint16(payload)
+			}
+
+			_ = c.writeClose(code)
+
+			return io.EOF
+		case opContinuation, opText, opPong:
+		default:
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, frameHeaderLen)
+	if _, err := io.ReadFull(c.br, header); err != nil {
+		return 0, nil, fmt.Errorf("read header: %w", err)
+	}
+
+	opcode := header[0] & opcodeMask
+	if header[1]&maskBit == 0 {
+		return 0, nil, errUnmaskedFrame
+	}
+
+	size := uint64(header[1] &^ maskBit)
+
+	switch size {
+	case payloadLen16:
+		ext := make([]byte, len16Bytes)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return 0, nil, fmt.Errorf("read length: %w", err)
+		}
+
+		size = uint64(binary.BigEndian.Uint16(ext))
+	case payloadLen64:
+		ext := make([]byte, len64Bytes)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return 0, nil, fmt.Errorf("read length: %w", err)
+		}
+
+		size = binary.BigEndian.Uint64(ext)
+	}
+
+	if size > maxClientPayload || (opcode >= opClose && size > maxControlPayload) {
+		return 0, nil, errFrameTooBig
+	}
+
+	mask := make([]byte, maskKeyLen)
+	if _, err := io.ReadFull(c.br, mask); err != nil {
+		return 0, nil, fmt.Errorf("read mask: %w", err)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, fmt.Errorf("read payload: %w", err)
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%maskKeyLen]
+	}
+
+	return opcode, payload, nil
+}
@@ -0,0 +1,297 @@
+package breaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/reddit"
+)
+
+// State is the state of a circuit.
+type State string
+
+const (
+	// StateClosed lets every request through.
+	StateClosed State = "closed"
+	// StateOpen rejects every request until its cooldown elapses.
+	StateOpen State = "open"
+	// StateHalfOpen lets one trial request through at a time, closing again once enough succeed.
+	StateHalfOpen State = "half-open"
+)
+
+// EventKind is the kind of event describing a Transition.
+const EventKind = "circuit-breaker"
+
+// Config decides when circuits open and close.
+type Config struct {
+	// Threshold is how many consecutive failures open a circuit; zero never opens one.
+	Threshold int
+	// Cooldown is how long an open circuit rejects requests before turning half-open.
+	Cooldown time.Duration
+	// Successes is how many trial requests must succeed in a row to close a half-open circuit.
+	Successes int
+}
+
+// Transition describes a circuit changing state.
+type Transition struct {
+	Key  string    `json:"key"`
+	From State     `json:"from"`
+	To   State     `json:"to"`
+	Time time.Time `json:"time"`
+	// Reason is the error which opened the circuit.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Circuit describes a circuit's current state.
+type Circuit struct {
+	Key   string `json:"key"`
+	State State  `json:"state"`
+	// Failures counts consecutive failures while closed.
+	Failures int `json:"failures"`
+	// OpenedAt is when the circuit last opened.
+	OpenedAt time.Time `json:"opened_at"`
+	LastErr  string    `json:"last_error,omitempty"`
+}
+
+// Breaker is a ListingFetcher which stops fetching from a subreddit that keeps failing, e.g. once
+// it has gone private or is erroring, so its jobs do not spend the shared request budget. Each
+// subreddit has its own circuit, so healthy subreddits are unaffected. Only responses blaming the
+// subreddit count as failures: 403, 404 and 5xx.
+type Breaker struct {
+	fetcher reddit.ListingFetcher
+	cfg     Config
+	now     func() time.Time
+	// observe is called with every transition.
+	observe func(t Transition)
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of a key's requests.
+type circuit struct {
+	state     State
+	failures  int
+	successes int
+	openedAt  time.Time
+	// trial is set while a half-open circuit's trial request is in flight.
+	trial   bool
+	lastErr error
+}
+
+// Option customizes a Breaker.
+type Option func(b *Breaker)
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(b *Breaker) {
+		b.now = now
+	}
+}
+
+// WithObserver calls observe whenever a circuit changes state, e.g. to publish events.
+func WithObserver(observe func(t Transition)) Option {
+	return func(b *Breaker) {
+		b.observe = observe
+	}
+}
+
+// New creates a Breaker in front of fetcher.
+func New(fetcher reddit.ListingFetcher, cfg Config, opts ...Option) *Breaker {
+	b := &Breaker{
+		fetcher:  fetcher,
+		cfg:      cfg,
+		now:      time.Now,
+		observe:  func(Transition) {},
+		circuits: map[string]*circuit{},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.cfg.Successes = max(b.cfg.Successes, 1)
+
+	return b
+}
+
+// FetchListing fetches the listing unless the circuit of its subreddit is open.
+func (b *Breaker) FetchListing(ctx context.Context, path string) (*reddit.Listing, error) {
+	key := Key(path)
+	if err := b.allow(ctx, key); err != nil {
+		return nil, err
+	}
+
+	listing, err := b.fetcher.FetchListing(ctx, path)
+	b.record(ctx, key, err)
+
+	return listing, err //nolint:wrapcheck // errors pass through unchanged so they are classified as usual.
+}
+
+// FetchAllListings fetches every page of the listing unless the circuit of its subreddit is open.
+func (b *Breaker) FetchAllListings(ctx context.Context, path string) ([]*reddit.Listing, error) {
+	key := Key(path)
+	if err := b.allow(ctx, key); err != nil {
+		return nil, err
+	}
+
+	listings, err := b.fetcher.FetchAllListings(ctx, path)
+	b.record(ctx, key, err)
+
+	return listings, err //nolint:wrapcheck // errors pass through unchanged so they are classified as usual.
+}
+
+// Circuits returns the state of every circuit which has seen a request, sorted by key.
+func (b *Breaker) Circuits() []Circuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]Circuit, 0, len(b.circuits))
+	for key, c := range b.circuits {
+		circuit := Circuit{Key: key, State: c.state, Failures: c.failures, OpenedAt: c.openedAt}
+		if c.lastErr != nil {
+			circuit.LastErr = c.lastErr.Error()
+		}
+
+		out = append(out, circuit)
+	}
+
+	slices.SortFunc(out, func(a, b Circuit) int { return strings.Compare(a.Key, b.Key) })
+
+	return out
+}
+
+// Forget drops the circuit of a subreddit, e.g. once it is no longer tracked. A request in flight
+// starts a new, closed circuit.
+func (b *Breaker) Forget(subreddit string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.circuits, strings.ToLower(subreddit))
+}
+
+// ServeHTTP writes the state of every circuit as JSON.
+func (b *Breaker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"circuits": b.Circuits()})
+}
+
+// Key returns the circuit a path belongs to: its subreddit, or the path itself outside /r/.
+func Key(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+
+	if rest, ok := strings.CutPrefix(path, "/r/"); ok {
+		subreddit, _, _ := strings.Cut(rest, "/")
+
+		return strings.ToLower(subreddit)
+	}
+
+	return path
+}
+
+// allow returns an OpenError unless the circuit lets the request through.
+func (b *Breaker) allow(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+
+	switch c.state {
+	case StateOpen:
+		if retryIn := c.openedAt.Add(b.cfg.Cooldown).Sub(b.now()); retryIn > 0 {
+			return NewOpenError(key, retryIn)
+		}
+
+		b.transition(ctx, key, c, StateHalfOpen)
+		c.trial = true
+	case StateHalfOpen:
+		if c.trial {
+			return NewOpenError(key, 0)
+		}
+
+		c.trial = true
+	case StateClosed:
+	}
+
+	return nil
+}
+
+// record updates the circuit with the outcome of a request.
+func (b *Breaker) record(ctx context.Context, key string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(key)
+	c.trial = false
+
+	switch {
+	case err == nil:
+		c.failures = 0
+
+		if c.state == StateHalfOpen {
+			if c.successes++; c.successes >= b.cfg.Successes {
+				b.transition(ctx, key, c, StateClosed)
+			}
+		}
+	case trips(err):
+		c.lastErr = err
+		c.failures++
+
+		if c.state == StateHalfOpen || (b.cfg.Threshold > 0 && c.failures >= b.cfg.Threshold && c.state == StateClosed) {
+			b.transition(ctx, key, c, StateOpen)
+		}
+	}
+}
+
+// transition changes the circuit's state, logging and observing the change.
+func (b *Breaker) transition(ctx context.Context, key string, c *circuit, to State) {
+	t := Transition{Key: key, From: c.state, To: to, Time: b.now()}
+	c.state = to
+
+	logr := logger.FromContext(ctx)
+
+	switch to {
+	case StateOpen:
+		c.openedAt, c.successes = t.Time, 0
+		t.Reason = c.lastErr.Error()
+
+		logr.Warn("circuit opened", "key", key, "failures", c.failures, "cooldown", b.cfg.Cooldown, "err", t.Reason)
+	case StateHalfOpen:
+		logr.Info("circuit half-open", "key", key)
+	case StateClosed:
+		c.failures, c.successes = 0, 0
+
+		logr.Info("circuit closed", "key", key)
+	}
+
+	b.observe(t)
+}
+
+func (b *Breaker) circuit(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{state: StateClosed}
+		b.circuits[key] = c
+	}
+
+	return c
+}
+
+// trips reports whether the error blames the subreddit rather than the client or the network.
+func trips(err error) bool {
+	var status *reddit.UnexpectedStatusError
+	if !errors.As(err, &status) {
+		return false
+	}
+
+	return status.Status == http.StatusForbidden || status.Status == http.StatusNotFound ||
+		status.Status >= http.StatusInternalServerError
+}
@@ -0,0 +1,219 @@
+package breaker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/breaker"
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/jqdurham/reddit/internal/reddit/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path, want string
+	}{
+		{path: "/r/golang/top?t=day&limit=100", want: "golang"},
+		{path: "/r/GoLang/comments", want: "golang"},
+		{path: "/r/golang", want: "golang"},
+		{path: "/api/v1/me?raw_json=1", want: "/api/v1/me"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, breaker.Key(tt.path))
+		})
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now         = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		transitions []breaker.Transition
+		forbidden   = reddit.NewUnexpectedStatusError(http.MethodGet, "/r/private/new", http.StatusForbidden)
+		listing     = &reddit.Listing{}
+		ctx         = context.Background()
+	)
+
+	fetcher := mocks.NewListingFetcher(t)
+	b := breaker.New(fetcher, breaker.Config{Threshold: 2, Cooldown: time.Minute, Successes: 2},
+		breaker.WithClock(func() time.Time { return now }),
+		breaker.WithObserver(func(t breaker.Transition) { transitions = append(transitions, t) }))
+
+	// Throttling is not the subreddit's fault.
+	fetcher.On("FetchListing", ctx, "/r/private/new").Return(nil, reddit.NewRateLimitExceededError(time.Second)).Times(3)
+
+	for range 3 {
+		_, err := b.FetchListing(ctx, "/r/private/new")
+		require.Error(t, err)
+	}
+
+	require.Empty(t, transitions)
+
+	fetcher.On("FetchListing", ctx, "/r/private/new").Return(nil, forbidden).Times(3)
+	fetcher.On("FetchAllListings", ctx, "/r/golang/top").Return([]*reddit.Listing{listing}, nil).Once()
+
+	for range 2 {
+		_, err := b.FetchListing(ctx, "/r/private/new")
+		require.ErrorIs(t, err, forbidden)
+	}
+
+	// Other subreddits are unaffected.
+	listings, err := b.FetchAllListings(ctx, "/r/golang/top")
+	require.NoError(t, err)
+	assert.Equal(t, []*reddit.Listing{listing}, listings)
+
+	var open *breaker.OpenError
+
+	now = now.Add(time.Second)
+	_, err = b.FetchAllListings(ctx, "/r/private/top")
+	require.ErrorAs(t, err, &open)
+	require.EqualError(t, open, "circuit open: private, retry in 59s")
+	require.Implements(t, (*orchestrator.Rejecter)(nil), open)
+
+	// Once cooled down, a failed trial opens the circuit again.
+	now = now.Add(time.Minute)
+	_, err = b.FetchListing(ctx, "/r/private/new")
+	require.ErrorIs(t, err, forbidden)
+
+	_, err = b.FetchListing(ctx, "/r/private/new")
+	require.ErrorAs(t, err, &open)
+
+	// Trials must succeed in a row to close it.
+	now = now.Add(time.Minute)
+	fetcher.On("FetchListing", ctx, "/r/private/new").Return(listing, nil).Twice()
+
+	for range 2 {
+		got, err := b.FetchListing(ctx, "/r/private/new")
+		require.NoError(t, err)
+		assert.Equal(t, listing, got)
+	}
+
+	states := make([]breaker.State, 0, len(transitions))
+	for _, transition := range transitions {
+		assert.Equal(t, "private", transition.Key)
+
+		states = append(states, transition.To)
+	}
+
+	assert.Equal(t, []breaker.State{
+		breaker.StateOpen, breaker.StateHalfOpen, breaker.StateOpen, breaker.StateHalfOpen, breaker.StateClosed,
+	}, states)
+	assert.Equal(t, forbidden.Error(), transitions[0].Reason)
+
+	assert.Equal(t, []breaker.Circuit{
+		{Key: "golang", State: breaker.StateClosed},
+		{Key: "private", State: breaker.StateClosed, OpenedAt: now.Add(-time.Minute), LastErr: forbidden.Error()},
+	}, b.Circuits())
+}
+
+func TestBreaker_HalfOpenTrial(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now     = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		ctx     = context.Background()
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	fetcher := mocks.NewListingFetcher(t)
+	b := breaker.New(fetcher, breaker.Config{Threshold: 1, Cooldown: time.Minute},
+		breaker.WithClock(func() time.Time { return now }))
+
+	fetcher.On("FetchListing", ctx, "/r/golang/new").
+		Return(nil, reddit.NewUnexpectedStatusError(http.MethodGet, "/r/golang/new", http.StatusBadGateway)).Once()
+
+	_, err := b.FetchListing(ctx, "/r/golang/new")
+	require.Error(t, err)
+
+	now = now.Add(time.Minute)
+
+	fetcher.On("FetchListing", ctx, "/r/golang/new").Return(&reddit.Listing{}, nil).Run(func(_ mock.Arguments) {
+		close(started)
+		<-release
+	}).Once()
+
+	done := make(chan error)
+
+	go func() {
+		_, err := b.FetchListing(ctx, "/r/golang/new")
+		done <- err
+	}()
+
+	<-started
+
+	// Only one trial is let through at a time.
+	var open *breaker.OpenError
+
+	_, err = b.FetchListing(ctx, "/r/golang/new")
+	require.ErrorAs(t, err, &open)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, breaker.StateClosed, b.Circuits()[0].State)
+}
+
+func TestBreaker_Forget(t *testing.T) {
+	t.Parallel()
+
+	fetcher := mocks.NewListingFetcher(t)
+	fetcher.On("FetchListing", context.Background(), "/r/Gone/new").
+		Return(nil, reddit.NewUnexpectedStatusError(http.MethodGet, "/r/Gone/new", http.StatusNotFound)).Once()
+	fetcher.On("FetchListing", context.Background(), "/r/Gone/new").Return(&reddit.Listing{}, nil).Once()
+
+	b := breaker.New(fetcher, breaker.Config{Threshold: 1, Cooldown: time.Minute})
+
+	_, err := b.FetchListing(context.Background(), "/r/Gone/new")
+	require.Error(t, err)
+	require.Len(t, b.Circuits(), 1)
+
+	// A subreddit tracked again after being forgotten is fetched from right away.
+	b.Forget("Gone")
+	require.Empty(t, b.Circuits())
+
+	_, err = b.FetchListing(context.Background(), "/r/Gone/new")
+	require.NoError(t, err)
+}
+
+func TestBreaker_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	fetcher := mocks.NewListingFetcher(t)
+	fetcher.On("FetchListing", context.Background(), "/r/gone/new").
+		Return(nil, reddit.NewUnexpectedStatusError(http.MethodGet, "/r/gone/new", http.StatusNotFound)).Once()
+
+	b := breaker.New(fetcher, breaker.Config{Threshold: 1, Cooldown: time.Minute})
+
+	_, err := b.FetchListing(context.Background(), "/r/gone/new")
+	require.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/breakers", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Circuits []breaker.Circuit `json:"circuits"`
+	}
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Circuits, 1)
+	assert.Equal(t, "gone", body.Circuits[0].Key)
+	assert.Equal(t, breaker.StateOpen, body.Circuits[0].State)
+}
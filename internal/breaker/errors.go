@@ -0,0 +1,26 @@
+package breaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// OpenError is returned instead of fetching while a circuit is open.
+type OpenError struct {
+	Key string
+	// RetryIn is how long until the circuit lets a trial request through.
+	RetryIn time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit open: %s, retry in %s", e.Key, e.RetryIn)
+}
+
+// Rejected reports that no request was made, so jobs neither retry nor count the failure.
+func (e *OpenError) Rejected() bool {
+	return true
+}
+
+func NewOpenError(key string, retryIn time.Duration) *OpenError {
+	return &OpenError{Key: key, RetryIn: retryIn}
+}
@@ -0,0 +1,19 @@
+package budget
+
+import "context"
+
+type jobKey struct{}
+
+// WithJob returns a context whose requests are charged to the named job.
+func WithJob(ctx context.Context, job string) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// JobFromContext returns the job requests made with ctx are charged to, or Unattributed.
+func JobFromContext(ctx context.Context) string {
+	if job, ok := ctx.Value(jobKey{}).(string); ok {
+		return job
+	}
+
+	return Unattributed
+}
@@ -0,0 +1,53 @@
+package budget
+
+import "time"
+
+// ticket is a request waiting for its turn.
+type ticket struct {
+	job string
+	// tag is the virtual time at which the request finishes in a fluid fair share, and seq breaks
+	// ties in arrival order.
+	tag float64
+	seq uint64
+	// cost is what the request added to its job's finish tag, refunded should it be cancelled.
+	cost     float64
+	enqueued time.Time
+	ready    chan struct{}
+	// index is the ticket's position in the queue, or -1 once removed.
+	index int
+}
+
+// queue is a container/heap of tickets, lowest tag first.
+type queue []*ticket
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	if q[i].tag != q[j].tag {
+		return q[i].tag < q[j].tag
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q queue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *queue) Push(x any) {
+	t, _ := x.(*ticket)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *queue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*q = old[:len(old)-1]
+
+	return t
+}
@@ -0,0 +1,300 @@
+package budget
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/reddit"
+)
+
+// Unattributed is the job charged for requests made without WithJob, such as logging in.
+const Unattributed = "unattributed"
+
+// Scheduler divides the request rate of a limiter between jobs by weighted fair queuing: while
+// several jobs are waiting, each is granted requests in proportion to its weight, so high-value
+// jobs get fresher data and background crawls yield. A job using less than its share is never
+// held back, and a job does not accumulate credit while idle.
+type Scheduler struct {
+	limiter reddit.Waiter
+	weights map[string]float64
+	now     func() time.Time
+	// wake is signalled when a ticket is queued.
+	wake chan struct{}
+
+	mu    sync.Mutex
+	queue queue
+	// virtual is the tag of the latest granted request.
+	virtual float64
+	seq     uint64
+	jobs    map[string]*account
+}
+
+// account tracks a job's place in the fair queue and its usage.
+type account struct {
+	// finish is the tag of the job's latest queued request.
+	finish  float64
+	granted int
+	waiting int
+	waited  time.Duration
+}
+
+// Usage describes a job's share of the request budget.
+type Usage struct {
+	Job    string  `json:"job"`
+	Weight float64 `json:"weight"`
+	// Granted counts requests allowed to proceed, and Share is their fraction of all granted.
+	Granted int     `json:"granted"`
+	Share   float64 `json:"share"`
+	// Waiting counts requests queued for their turn.
+	Waiting int `json:"waiting"`
+	// WaitSeconds is the total time granted requests spent queued.
+	WaitSeconds float64 `json:"wait_seconds"`
+}
+
+// Option customizes a Scheduler.
+type Option func(s *Scheduler)
+
+// WithWeight sets a job's weight; jobs default to a weight of 1. Weights that are not positive
+// are ignored.
+func WithWeight(job string, weight float64) Option {
+	return func(s *Scheduler) {
+		if weight > 0 {
+			s.weights[job] = weight
+		}
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// NewScheduler creates a Scheduler granting requests as fast as limiter allows. Requests only
+// proceed while Run is running.
+func NewScheduler(limiter reddit.Waiter, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		limiter: limiter,
+		weights: map[string]float64{},
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+		jobs:    map[string]*account{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// SetWeight changes a job's weight, see WithWeight. Requests already queued keep their place.
+func (s *Scheduler) SetWeight(job string, weight float64) {
+	if weight <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.weights[job] = weight
+}
+
+// Forget drops a job's weight and usage, e.g. once it has been removed. A job with requests still
+// queued keeps its usage.
+func (s *Scheduler) Forget(job string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.weights, job)
+
+	if acct, ok := s.jobs[job]; ok && acct.waiting == 0 {
+		delete(s.jobs, job)
+	}
+}
+
+// Waiting reports whether the job has requests queued for their turn.
+func (s *Scheduler) Waiting(job string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct, ok := s.jobs[job]
+
+	return ok && acct.waiting > 0
+}
+
+// Wait blocks until the job in ctx is granted a request, see WithJob.
+func (s *Scheduler) Wait(ctx context.Context) error {
+	job := JobFromContext(ctx)
+
+	s.mu.Lock()
+	acct := s.account(job)
+	cost := 1 / s.weight(job)
+	acct.finish = max(s.virtual, acct.finish) + cost
+	acct.waiting++
+	s.seq++
+	t := &ticket{job: job, tag: acct.finish, seq: s.seq, cost: cost, enqueued: s.now(), ready: make(chan struct{})}
+	heap.Push(&s.queue, t)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-t.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if t.index < 0 {
+			// Granted while being cancelled; the request may as well proceed.
+			return nil
+		}
+
+		heap.Remove(&s.queue, t.index)
+		acct.waiting--
+		s.refund(acct, t)
+
+		return ctx.Err() //nolint:wrapcheck // the caller's own context error.
+	}
+}
+
+// refund takes a cancelled request's cost back from its job, moving the job's requests queued after
+// it forward, so abandoned requests do not push the job's later requests back.
+func (s *Scheduler) refund(acct *account, cancelled *ticket) {
+	acct.finish -= cancelled.cost
+
+	for _, t := range s.queue {
+		if t.job == cancelled.job && t.tag > cancelled.tag {
+			t.tag -= cancelled.cost
+		}
+	}
+
+	heap.Init(&s.queue)
+}
+
+// Run grants queued requests in fair order, one per limiter token, until the context is
+// cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	held := false
+
+	for {
+		if !held {
+			if err := s.limiter.Wait(ctx); err != nil {
+				return
+			}
+
+			held = true
+		}
+
+		if s.grant() {
+			held = false
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+	}
+}
+
+// grant lets the queued request with the lowest tag proceed, reporting whether there was one.
+func (s *Scheduler) grant() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queue.Len() == 0 {
+		return false
+	}
+
+	t, _ := heap.Pop(&s.queue).(*ticket)
+	s.virtual = t.tag
+
+	acct := s.jobs[t.job]
+	acct.waiting--
+	acct.granted++
+	acct.waited += s.now().Sub(t.enqueued)
+
+	close(t.ready)
+
+	return true
+}
+
+// Usage returns every job's usage, ordered by job name. Weighted jobs are included before they
+// make their first request.
+func (s *Scheduler) Usage() []Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, acct := range s.jobs {
+		total += acct.granted
+	}
+
+	names := make([]string, 0, len(s.jobs)+len(s.weights))
+	for job := range s.jobs {
+		names = append(names, job)
+	}
+
+	for job := range s.weights {
+		if _, ok := s.jobs[job]; !ok {
+			names = append(names, job)
+		}
+	}
+
+	slices.Sort(names)
+
+	out := make([]Usage, len(names))
+	for i, job := range names {
+		out[i] = Usage{Job: job, Weight: s.weight(job)}
+
+		if acct, ok := s.jobs[job]; ok {
+			out[i].Granted = acct.granted
+			out[i].Waiting = acct.waiting
+			out[i].WaitSeconds = acct.waited.Seconds()
+
+			if total > 0 {
+				out[i].Share = float64(acct.granted) / float64(total)
+			}
+		}
+	}
+
+	return out
+}
+
+// ServeHTTP writes every job's usage as JSON.
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"jobs": s.Usage()})
+}
+
+func (s *Scheduler) account(job string) *account {
+	acct, ok := s.jobs[job]
+	if !ok {
+		acct = &account{}
+		s.jobs[job] = acct
+	}
+
+	return acct
+}
+
+func (s *Scheduler) weight(job string) float64 {
+	if w, ok := s.weights[job]; ok {
+		return w
+	}
+
+	return 1
+}
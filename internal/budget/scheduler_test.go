@@ -0,0 +1,290 @@
+package budget_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/budget"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gate is a limiter handing out one token per send on tokens.
+type gate struct {
+	tokens chan struct{}
+}
+
+func (g *gate) Wait(ctx context.Context) error {
+	select {
+	case <-g.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestScheduler_Wait(t *testing.T) {
+	t.Parallel()
+
+	limiter := &gate{tokens: make(chan struct{})}
+	scheduler := budget.NewScheduler(limiter, budget.WithWeight("top-posts:golang", 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go scheduler.Run(ctx)
+
+	var (
+		mu      sync.Mutex
+		granted []string
+		wg      sync.WaitGroup
+	)
+
+	for _, job := range []string{"top-posts:golang", "top-authors:golang"} {
+		for range 8 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if err := scheduler.Wait(budget.WithJob(ctx, job)); err == nil {
+					mu.Lock()
+					granted = append(granted, job)
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		waiting := 0
+		for _, u := range scheduler.Usage() {
+			waiting += u.Waiting
+		}
+
+		return waiting == 16
+	}, time.Second, time.Millisecond)
+
+	for range 8 {
+		limiter.tokens <- struct{}{}
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(granted) == 8
+	}, time.Second, time.Millisecond)
+
+	counts := map[string]int{}
+	for _, job := range granted {
+		counts[job]++
+	}
+
+	// A weight of 3 to 1 splits the first 8 requests 6 to 2.
+	assert.Equal(t, map[string]int{"top-posts:golang": 6, "top-authors:golang": 2}, counts)
+
+	usage := scheduler.Usage()
+	require.Len(t, usage, 2)
+	assert.Equal(t, "top-authors:golang", usage[0].Job)
+	assert.InDelta(t, 1.0, usage[0].Weight, 0)
+	assert.Equal(t, 2, usage[0].Granted)
+	assert.Equal(t, 6, usage[0].Waiting)
+	assert.InDelta(t, 0.25, usage[0].Share, 1e-9)
+	assert.Equal(t, "top-posts:golang", usage[1].Job)
+	assert.Equal(t, 6, usage[1].Granted)
+	assert.Equal(t, 2, usage[1].Waiting)
+
+	cancel()
+	wg.Wait()
+
+	for _, u := range scheduler.Usage() {
+		assert.Zero(t, u.Waiting, u.Job)
+	}
+}
+
+func TestScheduler_IdleJobGetsNoCredit(t *testing.T) {
+	t.Parallel()
+
+	limiter := &gate{tokens: make(chan struct{})}
+	scheduler := budget.NewScheduler(limiter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go scheduler.Run(ctx)
+
+	// A job alone is never held back by others' weights.
+	for range 3 {
+		done := make(chan error)
+
+		go func() { done <- scheduler.Wait(budget.WithJob(ctx, "busy")) }()
+
+		limiter.tokens <- struct{}{}
+		require.NoError(t, <-done)
+	}
+
+	// A job waking up after idling competes evenly rather than catching up on its missed share.
+	order := make(chan string, 4)
+
+	for _, job := range []string{"busy", "busy", "idle", "idle"} {
+		go func() {
+			if scheduler.Wait(budget.WithJob(ctx, job)) == nil {
+				order <- job
+			}
+		}()
+
+		require.Eventually(t, func() bool {
+			for _, u := range scheduler.Usage() {
+				if u.Job == job && u.Waiting > 0 {
+					return true
+				}
+			}
+
+			return false
+		}, time.Second, time.Millisecond)
+	}
+
+	got := make([]string, 0, 4)
+
+	for range 4 {
+		limiter.tokens <- struct{}{}
+		got = append(got, <-order)
+	}
+
+	assert.Equal(t, []string{"busy", "idle", "busy", "idle"}, got)
+}
+
+func TestScheduler_Wait_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	scheduler := budget.NewScheduler(&gate{tokens: make(chan struct{})})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	t.Cleanup(cancel)
+
+	require.ErrorIs(t, scheduler.Wait(ctx), context.DeadlineExceeded)
+	assert.Equal(t, []budget.Usage{{Job: budget.Unattributed, Weight: 1}}, scheduler.Usage())
+}
+
+func TestScheduler_Wait_CancelledRefunds(t *testing.T) {
+	t.Parallel()
+
+	limiter := &gate{tokens: make(chan struct{})}
+	scheduler := budget.NewScheduler(limiter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go scheduler.Run(ctx)
+
+	// Requests abandoned while queued do not count against the job's later requests.
+	abandon, abandoned := context.WithCancel(ctx)
+	errs := make(chan error, 3)
+
+	for range 3 {
+		go func() { errs <- scheduler.Wait(budget.WithJob(abandon, "abandoning")) }()
+	}
+
+	require.Eventually(t, func() bool { return scheduler.Waiting("abandoning") }, time.Second, time.Millisecond)
+	abandoned()
+
+	for range 3 {
+		require.ErrorIs(t, <-errs, context.Canceled)
+	}
+
+	order := make(chan string, 4)
+
+	for _, job := range []string{"steady", "steady", "abandoning", "abandoning"} {
+		before := waiting(scheduler, job)
+
+		go func() {
+			if scheduler.Wait(budget.WithJob(ctx, job)) == nil {
+				order <- job
+			}
+		}()
+
+		require.Eventually(t, func() bool { return waiting(scheduler, job) > before }, time.Second, time.Millisecond)
+	}
+
+	got := make([]string, 0, 4)
+
+	for range 4 {
+		limiter.tokens <- struct{}{}
+		got = append(got, <-order)
+	}
+
+	assert.Equal(t, []string{"steady", "abandoning", "steady", "abandoning"}, got)
+}
+
+func waiting(scheduler *budget.Scheduler, job string) int {
+	for _, u := range scheduler.Usage() {
+		if u.Job == job {
+			return u.Waiting
+		}
+	}
+
+	return 0
+}
+
+func TestScheduler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	scheduler := budget.NewScheduler(&gate{}, budget.WithWeight("top-posts:golang", 2))
+
+	rec := httptest.NewRecorder()
+	scheduler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/budget", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Jobs []budget.Usage `json:"jobs"`
+	}
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, []budget.Usage{{Job: "top-posts:golang", Weight: 2}}, body.Jobs)
+}
+
+func TestScheduler_SetWeight_Forget(t *testing.T) {
+	t.Parallel()
+
+	scheduler := budget.NewScheduler(&gate{})
+	scheduler.SetWeight("top-posts:golang", 2)
+	scheduler.SetWeight("top-posts:rust", 3)
+	scheduler.SetWeight("top-posts:rust", 0)
+
+	assert.Equal(t, []budget.Usage{
+		{Job: "top-posts:golang", Weight: 2},
+		{Job: "top-posts:rust", Weight: 3},
+	}, scheduler.Usage())
+
+	scheduler.Forget("top-posts:rust")
+
+	assert.Equal(t, []budget.Usage{{Job: "top-posts:golang", Weight: 2}}, scheduler.Usage())
+}
+
+func TestScheduler_Waiting(t *testing.T) {
+	t.Parallel()
+
+	scheduler := budget.NewScheduler(&gate{})
+
+	ctx, cancel := context.WithCancel(budget.WithJob(context.Background(), "top-posts:golang"))
+	done := make(chan error)
+
+	go func() {
+		done <- scheduler.Wait(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return scheduler.Waiting("top-posts:golang") }, time.Second, time.Millisecond)
+	assert.False(t, scheduler.Waiting("top-posts:rust"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	assert.False(t, scheduler.Waiting("top-posts:golang"))
+}
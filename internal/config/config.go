@@ -4,21 +4,103 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/jqdurham/reddit/internal/breaker"
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/pipeline"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/webhook"
 )
 
 type Config struct {
 	ClientID, ClientSecret,
 	RedditUsername, RedditPassword string
-	Subreddits  []string
-	RateLimit   time.Duration
-	LogLevel    slog.Level
-	TopNAuthors int
+	Subreddits       []string
+	RateLimit        time.Duration
+	LogLevel         slog.Level
+	TopNAuthors      int
+	SentimentLexicon string
+	ReportFormat     string
+	// LinkShorteners lists the shortener hosts whose links are unwrapped over HTTP. Shortened links
+	// are left as they are when unset.
+	LinkShorteners []string
+	// HTTPAddr is the listen address of the statistics API; empty disables it.
+	HTTPAddr string
+	// AdminToken authorizes changing the tracked subreddits through the API; empty disables it.
+	AdminToken string
+	// HTMLReportPath is the file rewritten with an HTML report every HTMLReportInterval; empty
+	// disables it.
+	HTMLReportPath     string
+	HTMLReportInterval time.Duration
+	// ReportChangesOnly prints only how the top posts and authors leaderboards changed instead of
+	// the full leaderboards.
+	ReportChangesOnly bool
+	// Webhooks receive notifications of noteworthy leaderboard changes.
+	Webhooks []webhook.Endpoint
+	// TrendingScoreDelta is the score gain between updates which makes a top post trending.
+	TrendingScoreDelta int
+	// RulesFile defines alert rules evaluated on every ingested post and comment; empty disables them.
+	RulesFile string
+	// DigestPath is the Markdown digest of the latest reports rewritten every DigestInterval; empty
+	// disables it.
+	DigestPath     string
+	DigestInterval time.Duration
+	// JobIntervals pause each kind of job between runs, keyed by top-posts, top-authors, sentiment
+	// and removals.
+	JobIntervals map[string]time.Duration
+	// JobJitter randomly lengthens every pause by up to its value.
+	JobJitter time.Duration
+	// JobPolicy handles failures of every job.
+	JobPolicy orchestrator.Policy
+	// JobWeights divide the request rate between kinds of jobs, keyed like JobIntervals, and
+	// SubredditWeights between subreddits; a job's weight is the product of the two.
+	JobWeights       map[string]float64
+	SubredditWeights map[string]float64
+	// AdaptivePolling paces each subreddit's sentiment job, which polls its newest posts, to find
+	// about PollTargetNewPosts new posts per poll, between PollMinInterval and PollMaxInterval,
+	// instead of using its fixed JobIntervals.
+	AdaptivePolling                  bool
+	PollMinInterval, PollMaxInterval time.Duration
+	PollTargetNewPosts               int
+	// Pipeline sizes the parse, aggregate and report stages between fetching listings and reporting.
+	Pipeline map[string]pipeline.Config
+	// Breaker decides when fetching from a failing subreddit is paused.
+	Breaker breaker.Config
+	// CoordDir is the directory shared by instances dividing the subreddits between them; empty runs
+	// a single instance polling every subreddit. InstanceID names this instance among them, and
+	// CoordTTL is how long its lease outlives its last heartbeat.
+	CoordDir   string
+	InstanceID string
+	CoordTTL   time.Duration
+	// ShutdownTimeout bounds how long jobs in flight may take to finish once shutting down, after
+	// which they are cancelled.
+	ShutdownTimeout time.Duration
+}
+
+// instanceIDPattern matches valid instance IDs, which name the instance's lease file.
+var instanceIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// pipelineDefaults maps each pipeline stage to the prefix of its settings and their defaults.
+var pipelineDefaults = []struct{ stage, env, workers, queue string }{
+	{stage: "parse", env: "REDDIT_PIPELINE_PARSE", workers: "4", queue: "64"},
+	{stage: "aggregate", env: "REDDIT_PIPELINE_AGGREGATE", workers: "2", queue: "64"},
+	{stage: "report", env: "REDDIT_PIPELINE_REPORT", workers: "1", queue: "256"},
+}
+
+// jobIntervalDefaults maps each kind of job to its interval setting and default.
+var jobIntervalDefaults = []struct{ job, env, def string }{
+	{job: "top-posts", env: "REDDIT_TOP_POSTS_INTERVAL", def: "1m"},
+	{job: "top-authors", env: "REDDIT_TOP_AUTHORS_INTERVAL", def: "15m"},
+	{job: "sentiment", env: "REDDIT_SENTIMENT_INTERVAL", def: "5m"},
+	{job: "removals", env: "REDDIT_REMOVALS_INTERVAL", def: "5m"},
 }
 
 func Configure(envVars io.Reader) (*Config, error) {
@@ -65,23 +147,416 @@ func Configure(envVars io.Reader) (*Config, error) {
 		return nil, NewInvalidConfigInputError("REDDIT_TOP_N_AUTHORS", err.Error())
 	}
 
+	if num < 1 {
+		return nil, NewInvalidConfigInputError("REDDIT_TOP_N_AUTHORS", "must be positive")
+	}
+
+	sentimentLexicon := getOptionalEnv(vars, "REDDIT_SENTIMENT_LEXICON", "")
+
+	reportFormat := strings.ToLower(getOptionalEnv(vars, "REDDIT_REPORT_FORMAT", report.FormatText))
+	if !slices.Contains(report.Formats(), reportFormat) {
+		return nil, NewInvalidConfigInputError("REDDIT_REPORT_FORMAT", "must be: "+strings.Join(report.Formats(), ", "))
+	}
+
+	var linkShorteners []string
+	if v := getOptionalEnv(vars, "REDDIT_LINK_SHORTENERS", ""); v != "" {
+		linkShorteners = strings.Split(v, ",")
+	}
+
+	httpAddr := getOptionalEnv(vars, "REDDIT_HTTP_ADDR", "")
+	htmlReportPath := getOptionalEnv(vars, "REDDIT_HTML_REPORT_PATH", "")
+
+	htmlReportInterval, err := time.ParseDuration(getOptionalEnv(vars, "REDDIT_HTML_REPORT_INTERVAL", "1m"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_HTML_REPORT_INTERVAL", err.Error())
+	}
+
+	if htmlReportInterval <= 0 {
+		return nil, NewInvalidConfigInputError("REDDIT_HTML_REPORT_INTERVAL", "must be positive")
+	}
+
+	changesOnly, err := strconv.ParseBool(getOptionalEnv(vars, "REDDIT_REPORT_CHANGES_ONLY", "false"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_REPORT_CHANGES_ONLY", err.Error())
+	}
+
+	webhooks, err := parseWebhooks(getOptionalEnv(vars, "REDDIT_WEBHOOKS", ""),
+		getOptionalEnv(vars, "REDDIT_WEBHOOK_SECRET", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	trendingDelta, err := strconv.Atoi(getOptionalEnv(vars, "REDDIT_TRENDING_SCORE_DELTA", "500"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_TRENDING_SCORE_DELTA", err.Error())
+	}
+
+	if trendingDelta <= 0 {
+		return nil, NewInvalidConfigInputError("REDDIT_TRENDING_SCORE_DELTA", "must be positive")
+	}
+
+	rulesFile := getOptionalEnv(vars, "REDDIT_RULES_FILE", "")
+	digestPath := getOptionalEnv(vars, "REDDIT_DIGEST_PATH", "")
+
+	digestInterval, err := time.ParseDuration(getOptionalEnv(vars, "REDDIT_DIGEST_INTERVAL", "168h"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_DIGEST_INTERVAL", err.Error())
+	}
+
+	if digestInterval <= 0 {
+		return nil, NewInvalidConfigInputError("REDDIT_DIGEST_INTERVAL", "must be positive")
+	}
+
+	jobIntervals := make(map[string]time.Duration, len(jobIntervalDefaults))
+	for _, job := range jobIntervalDefaults {
+		if jobIntervals[job.job], err = getDuration(vars, job.env, job.def); err != nil {
+			return nil, err
+		}
+	}
+
+	jobJitter, err := getDuration(vars, "REDDIT_JOB_JITTER", "15s")
+	if err != nil {
+		return nil, err
+	}
+
+	jobPolicy, err := parseJobPolicy(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	jobKinds := make([]string, len(jobIntervalDefaults))
+	for i, job := range jobIntervalDefaults {
+		jobKinds[i] = job.job
+	}
+
+	jobWeights, err := parseWeights("REDDIT_JOB_WEIGHTS",
+		getOptionalEnv(vars, "REDDIT_JOB_WEIGHTS", "top-posts=4,top-authors=1,sentiment=2,removals=2"), jobKinds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subreddits tracked later, e.g. through the admin API, may be weighted too.
+	subredditWeights, err := parseWeights("REDDIT_SUBREDDIT_WEIGHTS",
+		getOptionalEnv(vars, "REDDIT_SUBREDDIT_WEIGHTS", ""), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	adaptivePolling, err := strconv.ParseBool(getOptionalEnv(vars, "REDDIT_ADAPTIVE_POLLING", "false"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_ADAPTIVE_POLLING", err.Error())
+	}
+
+	pollMin, err := getDuration(vars, "REDDIT_POLL_MIN_INTERVAL", "1m")
+	if err != nil {
+		return nil, err
+	}
+
+	if pollMin == 0 {
+		return nil, NewInvalidConfigInputError("REDDIT_POLL_MIN_INTERVAL", "must be positive")
+	}
+
+	pollMax, err := getDuration(vars, "REDDIT_POLL_MAX_INTERVAL", "30m")
+	if err != nil {
+		return nil, err
+	}
+
+	if pollMax < pollMin {
+		return nil, NewInvalidConfigInputError("REDDIT_POLL_MAX_INTERVAL", "must not be less than REDDIT_POLL_MIN_INTERVAL")
+	}
+
+	pollTarget, err := strconv.Atoi(getOptionalEnv(vars, "REDDIT_POLL_TARGET_NEW_POSTS", "10"))
+	if err != nil {
+		return nil, NewInvalidConfigInputError("REDDIT_POLL_TARGET_NEW_POSTS", err.Error())
+	}
+
+	if pollTarget <= 0 {
+		return nil, NewInvalidConfigInputError("REDDIT_POLL_TARGET_NEW_POSTS", "must be positive")
+	}
+
+	adminToken := getOptionalEnv(vars, "REDDIT_ADMIN_TOKEN", "")
+
+	stages, err := parsePipeline(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	breakerCfg, err := parseBreaker(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	coordDir, instanceID, coordTTL, err := parseCoordination(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := getDuration(vars, "REDDIT_SHUTDOWN_TIMEOUT", "10s")
+	if err != nil {
+		return nil, err
+	}
+
 	level, err := toLevel(logLevel)
 	if err != nil {
 		return nil, NewInvalidConfigInputError("REDDIT_LOG_LEVEL", err.Error())
 	}
 
 	return &Config{
-		ClientID:       clientID,
-		ClientSecret:   secret,
-		RedditUsername: username,
-		RedditPassword: password,
-		Subreddits:     strings.Split(subreddits, ","),
-		RateLimit:      freq,
-		LogLevel:       level,
-		TopNAuthors:    num,
+		ClientID:           clientID,
+		ClientSecret:       secret,
+		RedditUsername:     username,
+		RedditPassword:     password,
+		Subreddits:         strings.Split(subreddits, ","),
+		RateLimit:          freq,
+		LogLevel:           level,
+		TopNAuthors:        num,
+		SentimentLexicon:   sentimentLexicon,
+		ReportFormat:       reportFormat,
+		LinkShorteners:     linkShorteners,
+		HTTPAddr:           httpAddr,
+		AdminToken:         adminToken,
+		HTMLReportPath:     htmlReportPath,
+		HTMLReportInterval: htmlReportInterval,
+		ReportChangesOnly:  changesOnly,
+		Webhooks:           webhooks,
+		TrendingScoreDelta: trendingDelta,
+		RulesFile:          rulesFile,
+		DigestPath:         digestPath,
+		DigestInterval:     digestInterval,
+		JobIntervals:       jobIntervals,
+		JobJitter:          jobJitter,
+		JobPolicy:          jobPolicy,
+		JobWeights:         jobWeights,
+		SubredditWeights:   subredditWeights,
+		AdaptivePolling:    adaptivePolling,
+		PollMinInterval:    pollMin,
+		PollMaxInterval:    pollMax,
+		PollTargetNewPosts: pollTarget,
+		Pipeline:           stages,
+		Breaker:            breakerCfg,
+		CoordDir:           coordDir,
+		InstanceID:         instanceID,
+		CoordTTL:           coordTTL,
+		ShutdownTimeout:    shutdownTimeout,
 	}, nil
 }
 
+// parsePipeline parses the workers, queue capacity and full queue policy of each pipeline stage.
+func parsePipeline(vars map[string]string) (map[string]pipeline.Config, error) {
+	stages := make(map[string]pipeline.Config, len(pipelineDefaults))
+
+	for _, stage := range pipelineDefaults {
+		workers, err := getCount(vars, stage.env+"_WORKERS", stage.workers)
+		if err != nil {
+			return nil, err
+		}
+
+		if workers == 0 {
+			return nil, NewInvalidConfigInputError(stage.env+"_WORKERS", "must be positive")
+		}
+
+		queue, err := getCount(vars, stage.env+"_QUEUE", stage.queue)
+		if err != nil {
+			return nil, err
+		}
+
+		policy := pipeline.Policy(strings.ToLower(getOptionalEnv(vars, stage.env+"_POLICY", string(pipeline.PolicyBlock))))
+		if !slices.Contains(pipeline.Policies(), policy) {
+			return nil, NewInvalidConfigInputError(stage.env+"_POLICY", "must be: block, drop")
+		}
+
+		stages[stage.stage] = pipeline.Config{Workers: workers, Queue: queue, Policy: policy}
+	}
+
+	return stages, nil
+}
+
+// parseBreaker parses when a subreddit's circuit opens and closes. By default, it opens after 5
+// consecutive failures, stays open for a minute, then closes after a successful trial request.
+func parseBreaker(vars map[string]string) (breaker.Config, error) {
+	var (
+		cfg breaker.Config
+		err error
+	)
+
+	if cfg.Threshold, err = getCount(vars, "REDDIT_BREAKER_THRESHOLD", "5"); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Cooldown, err = getDuration(vars, "REDDIT_BREAKER_COOLDOWN", "1m"); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Successes, err = getCount(vars, "REDDIT_BREAKER_SUCCESSES", "1"); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Successes == 0 {
+		return cfg, NewInvalidConfigInputError("REDDIT_BREAKER_SUCCESSES", "must be positive")
+	}
+
+	return cfg, nil
+}
+
+// parseCoordination parses how instances divide the subreddits between them. The instance ID
+// defaults to the hostname and process ID, and is only set when coordinating.
+func parseCoordination(vars map[string]string) (string, string, time.Duration, error) {
+	dir := getOptionalEnv(vars, "REDDIT_COORD_DIR", "")
+	if dir == "" {
+		return "", "", 0, nil
+	}
+
+	ttl, err := getDuration(vars, "REDDIT_COORD_TTL", "15s")
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	if ttl <= 0 {
+		return "", "", 0, NewInvalidConfigInputError("REDDIT_COORD_TTL", "must be positive")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "reddit"
+	}
+
+	id := getOptionalEnv(vars, "REDDIT_INSTANCE_ID", fmt.Sprintf("%s-%d", hostname, os.Getpid()))
+	if !instanceIDPattern.MatchString(id) {
+		return "", "", 0, NewInvalidConfigInputError("REDDIT_INSTANCE_ID", "must be letters, digits, '.', '_' or '-'")
+	}
+
+	return dir, id, ttl, nil
+}
+
+// parseJobPolicy parses how job failures are handled. By default, transient failures are retried
+// 3 times, a job is disabled after 10 consecutive failed runs, and authentication failures shut
+// down the process.
+func parseJobPolicy(vars map[string]string) (orchestrator.Policy, error) {
+	var (
+		policy orchestrator.Policy
+		err    error
+	)
+
+	if policy.Retries, err = getCount(vars, "REDDIT_JOB_RETRIES", "3"); err != nil {
+		return policy, err
+	}
+
+	if policy.Backoff, err = getDuration(vars, "REDDIT_JOB_RETRY_BACKOFF", "5s"); err != nil {
+		return policy, err
+	}
+
+	if policy.DisableAfter, err = getCount(vars, "REDDIT_JOB_DISABLE_AFTER", "10"); err != nil {
+		return policy, err
+	}
+
+	for _, category := range strings.Split(getOptionalEnv(vars, "REDDIT_JOB_ESCALATE", string(orchestrator.CategoryAuth)), ",") {
+		if category = strings.TrimSpace(category); category == "" {
+			continue
+		}
+
+		if !slices.Contains(orchestrator.Categories(), orchestrator.Category(category)) {
+			names := make([]string, 0, len(orchestrator.Categories()))
+			for _, c := range orchestrator.Categories() {
+				names = append(names, string(c))
+			}
+
+			return policy, NewInvalidConfigInputError("REDDIT_JOB_ESCALATE", "categories must be: "+strings.Join(names, ", "))
+		}
+
+		policy.Escalate = append(policy.Escalate, orchestrator.Category(category))
+	}
+
+	return policy, nil
+}
+
+// parseWeights parses a comma separated list of name=weight pairs, where each weight must be
+// positive and, unless known is nil, each name known.
+func parseWeights(env, list string, known []string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	if list == "" {
+		return weights, nil
+	}
+
+	for _, entry := range strings.Split(list, ",") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, NewInvalidConfigInputError(env, "expected name=weight: "+entry)
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, NewInvalidConfigInputError(env, "missing name: "+entry)
+		}
+
+		if known != nil && !slices.Contains(known, name) {
+			return nil, NewInvalidConfigInputError(env, "unknown name: "+name+", must be: "+strings.Join(known, ", "))
+		}
+
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight <= 0 {
+			return nil, NewInvalidConfigInputError(env, "weight must be a positive number: "+entry)
+		}
+
+		weights[name] = weight
+	}
+
+	return weights, nil
+}
+
+// getCount parses an optional, non-negative integer.
+func getCount(vars map[string]string, env, def string) (int, error) {
+	n, err := strconv.Atoi(getOptionalEnv(vars, env, def))
+	if err != nil {
+		return 0, NewInvalidConfigInputError(env, err.Error())
+	}
+
+	if n < 0 {
+		return 0, NewInvalidConfigInputError(env, "must not be negative")
+	}
+
+	return n, nil
+}
+
+// getDuration parses an optional, non-negative duration.
+func getDuration(vars map[string]string, env, def string) (time.Duration, error) {
+	d, err := time.ParseDuration(getOptionalEnv(vars, env, def))
+	if err != nil {
+		return 0, NewInvalidConfigInputError(env, err.Error())
+	}
+
+	if d < 0 {
+		return 0, NewInvalidConfigInputError(env, "must not be negative")
+	}
+
+	return d, nil
+}
+
+// parseWebhooks parses a comma separated list of webhook URLs, each optionally prefixed with its
+// payload format and an equals sign, e.g. "slack=https://hooks.slack.com/services/...".
+func parseWebhooks(list, secret string) ([]webhook.Endpoint, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var endpoints []webhook.Endpoint
+
+	for _, entry := range strings.Split(list, ",") {
+		// Only a known format is taken as a prefix, as the URL itself may contain "=" in its query.
+		format, rawURL := webhook.FormatJSON, entry
+		if prefix, rest, ok := strings.Cut(entry, "="); ok && slices.Contains(webhook.Formats(), strings.ToLower(prefix)) {
+			format, rawURL = strings.ToLower(prefix), rest
+		}
+
+		if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, NewInvalidConfigInputError("REDDIT_WEBHOOKS", "invalid URL: "+rawURL)
+		}
+
+		endpoints = append(endpoints, webhook.Endpoint{URL: rawURL, Format: format, Secret: secret})
+	}
+
+	return endpoints, nil
+}
+
 func getRequiredEnv(vars map[string]string, env string) (string, error) {
 	if v, ok := os.LookupEnv(env); ok {
 		return v, nil
@@ -8,7 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jqdurham/reddit/internal/breaker"
 	"github.com/jqdurham/reddit/internal/config"
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/pipeline"
+	"github.com/jqdurham/reddit/internal/webhook"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,14 +35,41 @@ REDDIT_PASSWORD=test-password`
 			name:    "Required parameters only",
 			envVars: strings.NewReader(requiredEnvs),
 			want: &config.Config{
-				ClientID:       "test-client-id",
-				ClientSecret:   "test-client-secret",
-				RedditUsername: "test-username",
-				RedditPassword: "test-password",
-				Subreddits:     []string{"golang"},
-				RateLimit:      time.Second,
-				LogLevel:       slog.LevelInfo,
-				TopNAuthors:    10,
+				ClientID:           "test-client-id",
+				ClientSecret:       "test-client-secret",
+				RedditUsername:     "test-username",
+				RedditPassword:     "test-password",
+				Subreddits:         []string{"golang"},
+				RateLimit:          time.Second,
+				LogLevel:           slog.LevelInfo,
+				TopNAuthors:        10,
+				ReportFormat:       "text",
+				HTMLReportInterval: time.Minute,
+				TrendingScoreDelta: 500,
+				DigestInterval:     7 * 24 * time.Hour,
+				JobIntervals: map[string]time.Duration{
+					"top-posts": time.Minute, "top-authors": 15 * time.Minute,
+					"sentiment": 5 * time.Minute, "removals": 5 * time.Minute,
+				},
+				JobJitter: 15 * time.Second,
+				JobPolicy: orchestrator.Policy{
+					Retries: 3, Backoff: 5 * time.Second, DisableAfter: 10,
+					Escalate: []orchestrator.Category{orchestrator.CategoryAuth},
+				},
+				JobWeights: map[string]float64{
+					"top-posts": 4, "top-authors": 1, "sentiment": 2, "removals": 2,
+				},
+				SubredditWeights:   map[string]float64{},
+				PollMinInterval:    time.Minute,
+				PollMaxInterval:    30 * time.Minute,
+				PollTargetNewPosts: 10,
+				Pipeline: map[string]pipeline.Config{
+					"parse":     {Workers: 4, Queue: 64, Policy: pipeline.PolicyBlock},
+					"aggregate": {Workers: 2, Queue: 64, Policy: pipeline.PolicyBlock},
+					"report":    {Workers: 1, Queue: 256, Policy: pipeline.PolicyBlock},
+				},
+				Breaker:         breaker.Config{Threshold: 5, Cooldown: time.Minute, Successes: 1},
+				ShutdownTimeout: 10 * time.Second,
 			},
 		},
 		{
@@ -74,27 +105,205 @@ REDDIT_PASSWORD=test-password`
 			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_TOP_N_AUTHORS=NaN"),
 			errMsg:  `invalid env: REDDIT_TOP_N_AUTHORS reason: strconv.Atoi: parsing "NaN": invalid syntax`,
 		},
+		{
+			name:    "Zero REDDIT_TOP_N_AUTHORS",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_TOP_N_AUTHORS=0"),
+			errMsg:  `invalid env: REDDIT_TOP_N_AUTHORS reason: must be positive`,
+		},
 		{
 			name:    "Invalid REDDIT_LOG_LEVEL",
 			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_LOG_LEVEL=NaL"),
 			errMsg:  `invalid env: REDDIT_LOG_LEVEL reason: invalid env: log level reason: must be: debug, info, warn, error`,
 		},
+		{
+			name:    "Invalid REDDIT_REPORT_FORMAT",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_REPORT_FORMAT=xml"),
+			errMsg:  `invalid env: REDDIT_REPORT_FORMAT reason: must be: text, json, ndjson, csv, table, markdown`,
+		},
+		{
+			name:    "Invalid REDDIT_HTML_REPORT_INTERVAL",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_HTML_REPORT_INTERVAL=0s"),
+			errMsg:  `invalid env: REDDIT_HTML_REPORT_INTERVAL reason: must be positive`,
+		},
+		{
+			name:    "Invalid REDDIT_REPORT_CHANGES_ONLY",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_REPORT_CHANGES_ONLY=sometimes"),
+			errMsg: `invalid env: REDDIT_REPORT_CHANGES_ONLY reason: strconv.ParseBool: parsing "sometimes": ` +
+				`invalid syntax`,
+		},
+		{
+			name:    "Unknown REDDIT_WEBHOOKS format",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_WEBHOOKS=teams=https://example.com/hook"),
+			errMsg:  `invalid env: REDDIT_WEBHOOKS reason: invalid URL: teams=https://example.com/hook`,
+		},
+		{
+			name:    "Invalid REDDIT_WEBHOOKS URL",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_WEBHOOKS=example.com/hook"),
+			errMsg:  `invalid env: REDDIT_WEBHOOKS reason: invalid URL: example.com/hook`,
+		},
+		{
+			name:    "Invalid REDDIT_TRENDING_SCORE_DELTA",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_TRENDING_SCORE_DELTA=-1"),
+			errMsg:  `invalid env: REDDIT_TRENDING_SCORE_DELTA reason: must be positive`,
+		},
+		{
+			name:    "Invalid REDDIT_DIGEST_INTERVAL",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_DIGEST_INTERVAL=weekly"),
+			errMsg:  `invalid env: REDDIT_DIGEST_INTERVAL reason: time: invalid duration "weekly"`,
+		},
+		{
+			name:    "Negative REDDIT_TOP_AUTHORS_INTERVAL",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_TOP_AUTHORS_INTERVAL=-1m"),
+			errMsg:  `invalid env: REDDIT_TOP_AUTHORS_INTERVAL reason: must not be negative`,
+		},
+		{
+			name:    "Negative REDDIT_JOB_RETRIES",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_JOB_RETRIES=-1"),
+			errMsg:  `invalid env: REDDIT_JOB_RETRIES reason: must not be negative`,
+		},
+		{
+			name:    "Invalid REDDIT_JOB_ESCALATE",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_JOB_ESCALATE=auth,timeout"),
+			errMsg:  `invalid env: REDDIT_JOB_ESCALATE reason: categories must be: transient, rate-limited, auth, fatal`,
+		},
+		{
+			name:    "Unknown REDDIT_JOB_WEIGHTS job",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_JOB_WEIGHTS=comments=2"),
+			errMsg: `invalid env: REDDIT_JOB_WEIGHTS reason: unknown name: comments, ` +
+				`must be: top-posts, top-authors, sentiment, removals`,
+		},
+		{
+			name:    "Invalid REDDIT_SUBREDDIT_WEIGHTS weight",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_SUBREDDIT_WEIGHTS=golang=0"),
+			errMsg:  `invalid env: REDDIT_SUBREDDIT_WEIGHTS reason: weight must be a positive number: golang=0`,
+		},
+		{
+			name:    "Missing REDDIT_SUBREDDIT_WEIGHTS name",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_SUBREDDIT_WEIGHTS==2"),
+			errMsg:  `invalid env: REDDIT_SUBREDDIT_WEIGHTS reason: missing name: =2`,
+		},
+		{
+			name:    "Zero REDDIT_PIPELINE_PARSE_WORKERS",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_PIPELINE_PARSE_WORKERS=0"),
+			errMsg:  `invalid env: REDDIT_PIPELINE_PARSE_WORKERS reason: must be positive`,
+		},
+		{
+			name:    "Invalid REDDIT_PIPELINE_REPORT_POLICY",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_PIPELINE_REPORT_POLICY=latest"),
+			errMsg:  `invalid env: REDDIT_PIPELINE_REPORT_POLICY reason: must be: block, drop`,
+		},
+		{
+			name:    "Zero REDDIT_BREAKER_SUCCESSES",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_BREAKER_SUCCESSES=0"),
+			errMsg:  `invalid env: REDDIT_BREAKER_SUCCESSES reason: must be positive`,
+		},
+		{
+			name:    "Invalid REDDIT_INSTANCE_ID",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_COORD_DIR=/tmp/reddit\nREDDIT_INSTANCE_ID=../a"),
+			errMsg:  `invalid env: REDDIT_INSTANCE_ID reason: must be letters, digits, '.', '_' or '-'`,
+		},
+		{
+			name:    "REDDIT_POLL_MAX_INTERVAL less than minimum",
+			envVars: strings.NewReader(requiredEnvs + "\nREDDIT_POLL_MIN_INTERVAL=10m\nREDDIT_POLL_MAX_INTERVAL=5m"),
+			errMsg:  `invalid env: REDDIT_POLL_MAX_INTERVAL reason: must not be less than REDDIT_POLL_MIN_INTERVAL`,
+		},
 		{
 			name: "All parameters",
 			envVars: strings.NewReader(requiredEnvs +
 				"\nREDDIT_SUBREDDITS=subreddit1,subreddit2" +
 				"\nREDDIT_RATE_LIMIT=60s" +
 				"\nREDDIT_LOG_LEVEL=debug" +
-				"\nREDDIT_TOP_N_AUTHORS=1337"),
+				"\nREDDIT_TOP_N_AUTHORS=1337" +
+				"\nREDDIT_SENTIMENT_LEXICON=./lexicon.txt" +
+				"\nREDDIT_REPORT_FORMAT=NDJSON" +
+				"\nREDDIT_LINK_SHORTENERS=bit.ly,t.co" +
+				"\nREDDIT_HTTP_ADDR=:9090" +
+				"\nREDDIT_ADMIN_TOKEN=t0k3n" +
+				"\nREDDIT_HTML_REPORT_PATH=/srv/share/reddit.html" +
+				"\nREDDIT_HTML_REPORT_INTERVAL=5m" +
+				"\nREDDIT_REPORT_CHANGES_ONLY=true" +
+				"\nREDDIT_WEBHOOKS=https://example.com/hook,Slack=https://hooks.slack.com/services/T0/B0/x," +
+				"https://example.com/hook?token=abc" +
+				"\nREDDIT_WEBHOOK_SECRET=s3cr3t" +
+				"\nREDDIT_TRENDING_SCORE_DELTA=250" +
+				"\nREDDIT_RULES_FILE=./rules.txt" +
+				"\nREDDIT_DIGEST_PATH=./digest.md" +
+				"\nREDDIT_DIGEST_INTERVAL=24h" +
+				"\nREDDIT_TOP_POSTS_INTERVAL=30s" +
+				"\nREDDIT_TOP_AUTHORS_INTERVAL=1h" +
+				"\nREDDIT_SENTIMENT_INTERVAL=10m" +
+				"\nREDDIT_REMOVALS_INTERVAL=0s" +
+				"\nREDDIT_JOB_JITTER=0s" +
+				"\nREDDIT_JOB_RETRIES=0" +
+				"\nREDDIT_JOB_RETRY_BACKOFF=1s" +
+				"\nREDDIT_JOB_DISABLE_AFTER=0" +
+				"\nREDDIT_JOB_ESCALATE=auth, fatal" +
+				"\nREDDIT_SHUTDOWN_TIMEOUT=1m" +
+				"\nREDDIT_JOB_WEIGHTS=top-posts=1.5" +
+				"\nREDDIT_SUBREDDIT_WEIGHTS=subreddit1=3, subreddit2=0.5,subreddit3=2" +
+				"\nREDDIT_ADAPTIVE_POLLING=true" +
+				"\nREDDIT_POLL_MIN_INTERVAL=30s" +
+				"\nREDDIT_POLL_MAX_INTERVAL=1h" +
+				"\nREDDIT_POLL_TARGET_NEW_POSTS=5" +
+				"\nREDDIT_PIPELINE_PARSE_WORKERS=8" +
+				"\nREDDIT_PIPELINE_AGGREGATE_QUEUE=0" +
+				"\nREDDIT_PIPELINE_REPORT_POLICY=Drop" +
+				"\nREDDIT_BREAKER_THRESHOLD=0" +
+				"\nREDDIT_BREAKER_COOLDOWN=30s" +
+				"\nREDDIT_BREAKER_SUCCESSES=3" +
+				"\nREDDIT_COORD_DIR=/srv/share/reddit" +
+				"\nREDDIT_INSTANCE_ID=replica-1" +
+				"\nREDDIT_COORD_TTL=1m"),
 			want: &config.Config{
-				ClientID:       "test-client-id",
-				ClientSecret:   "test-client-secret",
-				RedditUsername: "test-username",
-				RedditPassword: "test-password",
-				Subreddits:     []string{"subreddit1", "subreddit2"},
-				RateLimit:      time.Minute,
-				LogLevel:       slog.LevelDebug,
-				TopNAuthors:    1337,
+				ClientID:           "test-client-id",
+				ClientSecret:       "test-client-secret",
+				RedditUsername:     "test-username",
+				RedditPassword:     "test-password",
+				Subreddits:         []string{"subreddit1", "subreddit2"},
+				RateLimit:          time.Minute,
+				LogLevel:           slog.LevelDebug,
+				TopNAuthors:        1337,
+				SentimentLexicon:   "./lexicon.txt",
+				ReportFormat:       "ndjson",
+				LinkShorteners:     []string{"bit.ly", "t.co"},
+				HTTPAddr:           ":9090",
+				AdminToken:         "t0k3n",
+				HTMLReportPath:     "/srv/share/reddit.html",
+				HTMLReportInterval: 5 * time.Minute,
+				ReportChangesOnly:  true,
+				Webhooks: []webhook.Endpoint{
+					{URL: "https://example.com/hook", Format: "json", Secret: "s3cr3t"},
+					{URL: "https://hooks.slack.com/services/T0/B0/x", Format: "slack", Secret: "s3cr3t"},
+					{URL: "https://example.com/hook?token=abc", Format: "json", Secret: "s3cr3t"},
+				},
+				TrendingScoreDelta: 250,
+				RulesFile:          "./rules.txt",
+				DigestPath:         "./digest.md",
+				DigestInterval:     24 * time.Hour,
+				JobIntervals: map[string]time.Duration{
+					"top-posts": 30 * time.Second, "top-authors": time.Hour,
+					"sentiment": 10 * time.Minute, "removals": 0,
+				},
+				JobPolicy: orchestrator.Policy{
+					Backoff:  time.Second,
+					Escalate: []orchestrator.Category{orchestrator.CategoryAuth, orchestrator.CategoryFatal},
+				},
+				JobWeights:         map[string]float64{"top-posts": 1.5},
+				SubredditWeights:   map[string]float64{"subreddit1": 3, "subreddit2": 0.5, "subreddit3": 2},
+				AdaptivePolling:    true,
+				PollMinInterval:    30 * time.Second,
+				PollMaxInterval:    time.Hour,
+				PollTargetNewPosts: 5,
+				Pipeline: map[string]pipeline.Config{
+					"parse":     {Workers: 8, Queue: 64, Policy: pipeline.PolicyBlock},
+					"aggregate": {Workers: 2, Queue: 0, Policy: pipeline.PolicyBlock},
+					"report":    {Workers: 1, Queue: 256, Policy: pipeline.PolicyDrop},
+				},
+				Breaker:         breaker.Config{Cooldown: 30 * time.Second, Successes: 3},
+				CoordDir:        "/srv/share/reddit",
+				InstanceID:      "replica-1",
+				CoordTTL:        time.Minute,
+				ShutdownTimeout: time.Minute,
 			},
 		},
 	}
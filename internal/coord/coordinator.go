@@ -0,0 +1,176 @@
+package coord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+// Coordinator keeps an instance's membership alive and decides which subreddits it polls. Each
+// subreddit is owned by one member, assigned by consistent hashing, so instances sharing the work
+// never poll the same subreddit twice.
+type Coordinator struct {
+	backend Backend
+	id      string
+	ttl     time.Duration
+	// onChange is called with the membership whenever it changes.
+	onChange func(m Membership)
+
+	mu         sync.RWMutex
+	membership Membership
+	ring       *Ring
+	// renewed is when the instance's lease was last renewed.
+	renewed time.Time
+}
+
+// Option customizes a Coordinator.
+type Option func(c *Coordinator)
+
+// WithOnChange calls onChange with the membership whenever an instance joins or leaves, or the
+// leader changes, e.g. to rebalance the subreddits.
+func WithOnChange(onChange func(m Membership)) Option {
+	return func(c *Coordinator) {
+		c.onChange = onChange
+	}
+}
+
+// New creates a Coordinator for the instance named id, whose lease expires ttl after each renewal.
+func New(backend Backend, id string, ttl time.Duration, opts ...Option) *Coordinator {
+	c := &Coordinator{
+		backend:  backend,
+		id:       id,
+		ttl:      ttl,
+		onChange: func(Membership) {},
+		ring:     NewRing(nil),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Join renews the instance's lease for the first time, so it knows the subreddits it owns before
+// it starts polling.
+func (c *Coordinator) Join(ctx context.Context) error {
+	return c.renew(ctx)
+}
+
+// Run renews the instance's lease every third of its ttl until ctx is cancelled, then leaves. An
+// instance unable to renew its lease before it expires owns no subreddits, as the others will have
+// taken them over.
+func (c *Coordinator) Run(ctx context.Context) {
+	logr := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.backend.Leave(context.WithoutCancel(ctx), c.id); err != nil {
+				logr.Error(err.Error())
+			}
+
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.renew(ctx); err != nil {
+			logr.Warn("lease not renewed", "instance", c.id, "err", err.Error())
+
+			c.mu.RLock()
+			expired := time.Since(c.renewed) >= c.ttl
+			c.mu.RUnlock()
+
+			if expired {
+				c.update(ctx, Membership{})
+			}
+		}
+	}
+}
+
+// Owns reports whether the instance polls the subreddit.
+func (c *Coordinator) Owns(subreddit string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ring.Owner(strings.ToLower(subreddit)) == c.id
+}
+
+// Leader reports whether the instance leads the others.
+func (c *Coordinator) Leader() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.membership.Leader == c.id
+}
+
+// Membership returns the latest known membership.
+func (c *Coordinator) Membership() Membership {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Membership{Members: slices.Clone(c.membership.Members), Leader: c.membership.Leader}
+}
+
+// ServeHTTP writes the instance's ID and the membership as JSON.
+func (c *Coordinator) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	m := c.Membership()
+	_ = json.NewEncoder(w).Encode(map[string]any{"instance": c.id, "members": m.Members, "leader": m.Leader})
+}
+
+func (c *Coordinator) renew(ctx context.Context) error {
+	m, err := c.backend.Renew(ctx, c.id, c.ttl)
+	if err != nil {
+		return err //nolint:wrapcheck // backends wrap their own errors.
+	}
+
+	c.mu.Lock()
+	c.renewed = time.Now()
+	c.mu.Unlock()
+
+	c.update(ctx, m)
+
+	return nil
+}
+
+// update replaces the membership, rebuilding the ring and notifying onChange when it changed.
+func (c *Coordinator) update(ctx context.Context, m Membership) {
+	c.mu.Lock()
+
+	prev := c.membership
+	if slices.Equal(prev.Members, m.Members) && prev.Leader == m.Leader {
+		c.mu.Unlock()
+
+		return
+	}
+
+	c.membership = m
+	if !slices.Equal(prev.Members, m.Members) {
+		c.ring = NewRing(m.Members)
+	}
+
+	c.mu.Unlock()
+
+	logr := logger.FromContext(ctx)
+	logr.Info("cluster membership changed", "instance", c.id, "members", m.Members, "leader", m.Leader)
+
+	switch {
+	case m.Leader == c.id && prev.Leader != c.id:
+		logr.Info("elected leader", "instance", c.id)
+	case m.Leader != c.id && prev.Leader == c.id:
+		logr.Info("no longer leader", "instance", c.id)
+	}
+
+	c.onChange(m)
+}
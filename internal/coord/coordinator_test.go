@@ -0,0 +1,104 @@
+package coord_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/coord"
+	"github.com/jqdurham/reddit/internal/coord/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errMockedFailure = errors.New("mocked failure")
+
+func TestCoordinator(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ttl     = 30 * time.Millisecond
+		mu      sync.Mutex
+		changes []coord.Membership
+	)
+
+	backend := mocks.NewBackend(t)
+	backend.On("Renew", mock.Anything, "a", ttl).
+		Return(coord.Membership{Members: []string{"a"}, Leader: "a"}, nil).Once()
+	backend.On("Renew", mock.Anything, "a", ttl).
+		Return(coord.Membership{Members: []string{"a", "b"}, Leader: "a"}, nil).Once()
+	backend.On("Renew", mock.Anything, "a", ttl).Return(coord.Membership{}, errMockedFailure)
+	backend.On("Leave", mock.Anything, "a").Return(nil).Once()
+
+	c := coord.New(backend, "a", ttl, coord.WithOnChange(func(m coord.Membership) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		changes = append(changes, m)
+	}))
+
+	assert.False(t, c.Owns("golang"), "owns nothing before joining")
+
+	require.NoError(t, c.Join(context.Background()))
+	assert.True(t, c.Owns("golang"))
+	assert.True(t, c.Leader())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		c.Run(ctx)
+	}()
+
+	// Once b joins, a owns only its share.
+	require.Eventually(t, func() bool { return len(c.Membership().Members) == 2 }, time.Second, time.Millisecond)
+
+	ring := coord.NewRing([]string{"a", "b"})
+	for i := range 100 {
+		subreddit := "subreddit" + strconv.Itoa(i)
+		assert.Equal(t, ring.Owner(subreddit) == "a", c.Owns(subreddit), subreddit)
+	}
+
+	// Unable to renew its lease, a owns nothing once it expires.
+	require.Eventually(t, func() bool { return len(c.Membership().Members) == 0 }, time.Second, time.Millisecond)
+	assert.False(t, c.Owns("golang"))
+	assert.False(t, c.Leader())
+
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []coord.Membership{
+		{Members: []string{"a"}, Leader: "a"},
+		{Members: []string{"a", "b"}, Leader: "a"},
+		{},
+	}, changes)
+}
+
+func TestCoordinator_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	backend := mocks.NewBackend(t)
+	backend.On("Renew", mock.Anything, "b", time.Minute).
+		Return(coord.Membership{Members: []string{"a", "b"}, Leader: "a"}, nil).Once()
+
+	c := coord.New(backend, "b", time.Minute)
+	require.NoError(t, c.Join(context.Background()))
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cluster", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"instance":"b","members":["a","b"],"leader":"a"}`, rec.Body.String())
+}
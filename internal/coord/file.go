@@ -0,0 +1,346 @@
+package coord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+const (
+	// lockRetry is how often a held lock is retried.
+	lockRetry = 10 * time.Millisecond
+	// staleLock is how old a lock must be before it is assumed to be left by a crashed instance.
+	staleLock = 10 * time.Second
+	// tokenLen is the number of random bytes identifying a lock's holder.
+	tokenLen = 16
+	// leaseExt is the extension of lease files.
+	leaseExt = ".json"
+)
+
+// FileBackend keeps leases as files in a directory shared by the instances, e.g. on one host or a
+// network filesystem. A lock file serializes access to the directory, and a guard file removing
+// the lock: members/ holds a lease per member and leader.json the leader's. The leader removes
+// expired members' leases.
+type FileBackend struct {
+	dir string
+}
+
+// lease is held by an instance until it expires or is released.
+type lease struct {
+	ID      string    `json:"id"`
+	Expires time.Time `json:"expires"`
+}
+
+// NewFileBackend creates a FileBackend keeping leases in dir, which is created when missing.
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+// Renew holds the instance's membership lease for ttl, taking the leader lease if it is free, and
+// returns the members whose leases have not expired. Unreadable leases count as expired.
+func (b *FileBackend) Renew(ctx context.Context, id string, ttl time.Duration) (Membership, error) {
+	if err := os.MkdirAll(filepath.Join(b.dir, "members"), 0o750); err != nil {
+		return Membership{}, fmt.Errorf("create coordination dir: %w", err)
+	}
+
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return Membership{}, err
+	}
+	defer unlock()
+
+	now := time.Now()
+
+	if err := writeLease(b.memberPath(id), lease{ID: id, Expires: now.Add(ttl)}); err != nil {
+		return Membership{}, err
+	}
+
+	// An unreadable leader lease is treated as expired too, and taken over, rather than fail every
+	// renewal until someone removes it.
+	leader, err := readLease(b.leaderPath())
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.FromContext(ctx).Warn("replaced unreadable lease", "path", b.leaderPath(), "err", err.Error())
+		}
+
+		leader = lease{}
+	}
+
+	if leader.ID == "" || leader.ID == id || !leader.Expires.After(now) {
+		leader = lease{ID: id, Expires: now.Add(ttl)}
+		if err := writeLease(b.leaderPath(), leader); err != nil {
+			return Membership{}, err
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(b.dir, "members"))
+	if err != nil {
+		return Membership{}, fmt.Errorf("list members: %w", err)
+	}
+
+	members := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), leaseExt) || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(b.dir, "members", entry.Name())
+
+		// An unreadable lease, e.g. left half-written by a crash, is treated as expired.
+		member, err := readLease(path)
+		if err != nil {
+			logger.FromContext(ctx).Warn("skipped unreadable lease", "path", path, "err", err.Error())
+		}
+
+		if err != nil || !member.Expires.After(now) {
+			if leader.ID == id {
+				_ = os.Remove(path)
+			}
+
+			continue
+		}
+
+		members = append(members, member.ID)
+	}
+
+	slices.Sort(members)
+
+	return Membership{Members: members, Leader: leader.ID}, nil
+}
+
+// Leave removes the instance's membership lease, and the leader lease if it holds it.
+func (b *FileBackend) Leave(ctx context.Context, id string) error {
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(b.memberPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove member lease: %w", err)
+	}
+
+	leader, err := readLease(b.leaderPath())
+	if err != nil || leader.ID != id {
+		return nil //nolint:nilerr // a missing or unreadable leader lease is not ours to release.
+	}
+
+	if err := os.Remove(b.leaderPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove leader lease: %w", err)
+	}
+
+	return nil
+}
+
+// lock creates the lock file, waiting while another instance holds it, and returns a function
+// removing it. A lock older than staleLock is broken.
+//
+// Locks are only removed while holding the guard file, so a lock cannot be replaced between being
+// checked and removed: the lock records a token unique to its holder, which releases it only if it
+// still holds that token, and a stale lock is only removed if it is still stale.
+func (b *FileBackend) lock(ctx context.Context) (func(), error) {
+	path := filepath.Join(b.dir, "lock")
+
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("acquire coordination lock: %w", err)
+	}
+
+	for {
+		err := createLock(path, token)
+		if err == nil {
+			// Releasing waits for the guard even once ctx is done, as the lock must not be left
+			// held until it goes stale.
+			return func() { _ = releaseLock(context.WithoutCancel(ctx), path, token) }, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("acquire coordination lock: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			if err := breakLock(ctx, path); err != nil {
+				return nil, fmt.Errorf("break stale coordination lock: %w", err)
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquire coordination lock: %w", ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// createLock creates the lock file holding token, failing with fs.ErrExist while it is held.
+func createLock(path, token string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create lock: %w", err)
+	}
+
+	_, err = file.WriteString(token)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		// Nobody else removes a lock until it is stale, so the lock created here is removed as is.
+		_ = os.Remove(path)
+
+		return fmt.Errorf("write lock: %w", err)
+	}
+
+	return nil
+}
+
+// releaseLock removes the lock file if it still holds token, i.e. it was not broken as stale and
+// then acquired by another instance meanwhile.
+func releaseLock(ctx context.Context, path, token string) error {
+	return withGuard(ctx, path, func() error {
+		held, err := os.ReadFile(path)
+		if err != nil || string(held) != token {
+			return nil //nolint:nilerr // a missing or replaced lock is not ours to release.
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove lock: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// breakLock removes the lock file if it is still stale, i.e. it was not released and acquired
+// again meanwhile.
+func breakLock(ctx context.Context, path string) error {
+	return withGuard(ctx, path, func() error {
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) <= staleLock {
+			return nil //nolint:nilerr // a released or fresh lock is left for its holder.
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove lock: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// withGuard runs fn while holding the guard file of the lock at path. The guard is only held for a
+// few file operations, so one older than staleLock was left by a crash and is removed.
+func withGuard(ctx context.Context, path string, fn func() error) error {
+	guard := path + ".guard"
+
+	for {
+		file, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = file.Close()
+
+			err = fn()
+			if removeErr := os.Remove(guard); err == nil && removeErr != nil {
+				err = fmt.Errorf("remove lock guard: %w", removeErr)
+			}
+
+			return err
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("acquire lock guard: %w", err)
+		}
+
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > staleLock {
+			_ = os.Remove(guard)
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("acquire lock guard: %w", ctx.Err())
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// newToken returns a random token identifying a lock's holder.
+func newToken() (string, error) {
+	buf := make([]byte, tokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate lock token: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func (b *FileBackend) memberPath(id string) string {
+	return filepath.Join(b.dir, "members", id+leaseExt)
+}
+
+func (b *FileBackend) leaderPath() string {
+	return filepath.Join(b.dir, "leader"+leaseExt)
+}
+
+// writeLease replaces the lease file at once, so it is never read half-written.
+func writeLease(path string, l lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("encode lease: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".lease-*")
+	if err != nil {
+		return fmt.Errorf("create lease: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write lease: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write lease: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("replace lease: %w", err)
+	}
+
+	return nil
+}
+
+func readLease(path string) (lease, error) {
+	var l lease
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return l, fmt.Errorf("read lease: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &l); err != nil {
+		return l, fmt.Errorf("decode lease: %v: %w", filepath.Base(path), err)
+	}
+
+	return l, nil
+}
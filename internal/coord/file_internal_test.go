@@ -0,0 +1,62 @@
+package coord
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackend_lock_Contenders(t *testing.T) {
+	t.Parallel()
+
+	const contenders = 3
+
+	var (
+		ctx     = context.Background()
+		dir     = t.TempDir()
+		path    = filepath.Join(dir, "lock")
+		stale   = time.Now().Add(-time.Minute)
+		holders atomic.Int32
+	)
+
+	// Each round, the contenders race to break the same stale lock, yet only one holds the lock at
+	// a time and each releases only its own.
+	for range 20 {
+		require.NoError(t, os.WriteFile(path, []byte("crashed"), 0o600))
+		require.NoError(t, os.Chtimes(path, stale, stale))
+
+		var wg sync.WaitGroup
+
+		for range contenders {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				unlock, err := NewFileBackend(dir).lock(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				assert.Equal(t, int32(1), holders.Add(1), "lock held by one contender")
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+
+				unlock()
+			}()
+		}
+
+		wg.Wait()
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries, "no lock or guard left")
+	}
+}
@@ -0,0 +1,103 @@
+package coord_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/coord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		dir     = filepath.Join(t.TempDir(), "coord")
+		backend = coord.NewFileBackend(dir)
+	)
+
+	m, err := backend.Renew(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, coord.Membership{Members: []string{"a"}, Leader: "a"}, m)
+
+	m, err = backend.Renew(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, coord.Membership{Members: []string{"a", "b"}, Leader: "a"}, m)
+
+	// An expired member is left out, and its lease removed by the leader.
+	_, err = backend.Renew(ctx, "c", time.Millisecond)
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+
+	m, err = backend.Renew(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, m.Members)
+	assert.FileExists(t, filepath.Join(dir, "members", "c.json"))
+
+	_, err = backend.Renew(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "members", "c.json"))
+
+	// The leader leaving hands leadership over.
+	require.NoError(t, backend.Leave(ctx, "a"))
+
+	m, err = backend.Renew(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, coord.Membership{Members: []string{"b"}, Leader: "b"}, m)
+}
+
+func TestFileBackend_UnreadableLease(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = context.Background()
+		dir     = t.TempDir()
+		backend = coord.NewFileBackend(dir)
+	)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "members"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "members", "b.json"), []byte(`{"id":`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "leader.json"), []byte(`{"id":"b","exp`), 0o600))
+
+	// A lease left half-written is treated as expired: the leader lease is taken over, and the
+	// member's removed by the new leader.
+	m, err := backend.Renew(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, coord.Membership{Members: []string{"a"}, Leader: "a"}, m)
+	assert.NoFileExists(t, filepath.Join(dir, "members", "b.json"))
+}
+
+func TestFileBackend_Lock(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lock"), nil, 0o600))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := coord.NewFileBackend(dir).Renew(ctx, "a", time.Minute)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A stale lock, left by a crashed instance, is broken.
+	stale := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "lock"), stale, stale))
+
+	m, err := coord.NewFileBackend(dir).Renew(context.Background(), "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, m.Members)
+	assert.NoFileExists(t, filepath.Join(dir, "lock"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "lock", "no lock left aside")
+	}
+}
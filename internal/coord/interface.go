@@ -0,0 +1,24 @@
+package coord
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores the leases through which instances discover each other and elect a leader.
+//
+//go:generate mockery --name Backend
+type Backend interface {
+	// Renew holds the instance's membership lease for ttl, taking the leader lease if it is free,
+	// and returns the members whose leases have not expired.
+	Renew(ctx context.Context, id string, ttl time.Duration) (Membership, error)
+	// Leave releases the instance's leases so its subreddits are reassigned without waiting for
+	// them to expire.
+	Leave(ctx context.Context, id string) error
+}
+
+// Membership is the instances sharing the work, sorted by ID, and the one leading them.
+type Membership struct {
+	Members []string `json:"members"`
+	Leader  string   `json:"leader"`
+}
@@ -0,0 +1,64 @@
+package coord
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strconv"
+)
+
+// virtualNodes is how many points each member has on the ring, which evens out their shares.
+const virtualNodes = 128
+
+// Ring assigns keys to members by consistent hashing: a member joining takes a share of keys from
+// each of the others, and a member leaving hands its keys out between them, while every other key
+// keeps its owner.
+type Ring struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+// NewRing places each member's virtual nodes on the ring.
+func NewRing(members []string) *Ring {
+	r := &Ring{
+		points: make([]uint64, 0, len(members)*virtualNodes),
+		owners: make(map[uint64]string, len(members)*virtualNodes),
+	}
+
+	for _, member := range members {
+		for i := range virtualNodes {
+			point := hash(member + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[point]; taken {
+				continue
+			}
+
+			r.points = append(r.points, point)
+			r.owners[point] = member
+		}
+	}
+
+	slices.Sort(r.points)
+
+	return r
+}
+
+// Owner returns the member owning key: the first member clockwise of the key's point. It returns
+// an empty string when the ring has no members.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	i, _ := slices.BinarySearch(r.points, hash(key))
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.owners[r.points[i]]
+}
+
+func hash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+
+	return binary.BigEndian.Uint64(sum[:8])
+}
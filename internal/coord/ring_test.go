@@ -0,0 +1,45 @@
+package coord_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/coord"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]string, 3000)
+	for i := range keys {
+		keys[i] = "subreddit" + strconv.Itoa(i)
+	}
+
+	assert.Empty(t, coord.NewRing(nil).Owner("golang"))
+
+	three := coord.NewRing([]string{"a", "b", "c"})
+	shares := map[string]int{}
+
+	for _, key := range keys {
+		shares[three.Owner(key)]++
+	}
+
+	for _, member := range []string{"a", "b", "c"} {
+		assert.InDelta(t, len(keys)/3, shares[member], float64(len(keys))/10, member)
+	}
+
+	// A member joining only takes keys, and a member leaving only gives its own away.
+	four := coord.NewRing([]string{"a", "b", "c", "d"})
+	two := coord.NewRing([]string{"a", "c"})
+
+	for _, key := range keys {
+		if owner := four.Owner(key); owner != "d" {
+			assert.Equal(t, three.Owner(key), owner, key)
+		}
+
+		if owner := three.Owner(key); owner != "b" {
+			assert.Equal(t, owner, two.Owner(key), key)
+		}
+	}
+}
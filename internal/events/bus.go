@@ -0,0 +1,124 @@
+package events
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is a statistic update delivered to subscribers.
+type Event struct {
+	ID        uint64          `json:"id"`
+	Kind      string          `json:"kind"`
+	Subreddit string          `json:"subreddit,omitempty"`
+	Time      time.Time       `json:"time"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Filter selects the events delivered to a subscription. Empty fields match everything.
+type Filter struct {
+	Subreddits []string
+	Kinds      []string
+}
+
+// Match reports whether the event passes the filter. Events spanning every subreddit are delivered
+// regardless of the subreddits requested.
+func (f Filter) Match(ev Event) bool {
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, ev.Kind) {
+		return false
+	}
+
+	return len(f.Subreddits) == 0 || ev.Subreddit == "" || slices.Contains(f.Subreddits, ev.Subreddit)
+}
+
+// Subscription receives the events matching its filter.
+type Subscription struct {
+	filter  Filter
+	ch      chan Event
+	dropped atomic.Uint64
+}
+
+// Events returns the channel events are delivered on. It is closed once unsubscribed.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns how many events were discarded because the subscriber fell behind.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Bus fans events out to subscribers. Each subscription has a bounded buffer and publishing never
+// blocks: when a subscriber falls behind, its oldest undelivered event is discarded.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	nextID uint64
+}
+
+// NewBus creates a Bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe registers a subscription buffering up to buffer events.
+func (b *Bus) Subscribe(filter Filter, buffer int) *Subscription {
+	sub := &Subscription{filter: filter, ch: make(chan Event, max(buffer, 1))}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe stops delivery to the subscription and closes its channel.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+// Publish assigns the event an ID and delivers it to every matching subscription.
+func (b *Bus) Publish(ev Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	ev.ID = b.nextID
+
+	for sub := range b.subs {
+		if !sub.filter.Match(ev) {
+			continue
+		}
+
+		sub.deliver(ev)
+	}
+}
+
+// deliver queues the event, discarding the oldest queued event when the buffer is full.
+func (s *Subscription) deliver(ev Event) {
+	for {
+		select {
+		case s.ch <- ev:
+			return
+		default:
+		}
+
+		// The subscriber may have caught up in the meantime, in which case nothing is discarded.
+		select {
+		case <-s.ch:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter events.Filter
+		event  events.Event
+		want   bool
+	}{
+		{name: "Empty filter", event: events.Event{Kind: "mood", Subreddit: "golang"}, want: true},
+		{
+			name:   "Matching subreddit and kind",
+			filter: events.Filter{Subreddits: []string{"golang"}, Kinds: []string{"mood", "top-posts"}},
+			event:  events.Event{Kind: "mood", Subreddit: "golang"},
+			want:   true,
+		},
+		{
+			name:   "Other subreddit",
+			filter: events.Filter{Subreddits: []string{"golang"}},
+			event:  events.Event{Kind: "mood", Subreddit: "rust"},
+		},
+		{
+			name:   "Other kind",
+			filter: events.Filter{Kinds: []string{"top-posts"}},
+			event:  events.Event{Kind: "mood", Subreddit: "golang"},
+		},
+		{
+			name:   "Event spanning every subreddit",
+			filter: events.Filter{Subreddits: []string{"golang"}},
+			event:  events.Event{Kind: "removal-rates"},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.filter.Match(tt.event))
+		})
+	}
+}
+
+func TestBus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		bus    = events.NewBus()
+		golang = bus.Subscribe(events.Filter{Subreddits: []string{"golang"}}, 2)
+		all    = bus.Subscribe(events.Filter{}, 10)
+		now    = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	for _, sub := range []string{"golang", "rust", "golang", "golang"} {
+		bus.Publish(events.Event{Kind: "top-posts", Subreddit: sub, Time: now, Data: json.RawMessage(`{}`)})
+	}
+
+	// The slow subscriber keeps the newest events and counts those it missed.
+	require.Equal(t, uint64(1), golang.Dropped())
+	require.Equal(t, uint64(3), (<-golang.Events()).ID)
+	require.Equal(t, uint64(4), (<-golang.Events()).ID)
+
+	require.Zero(t, all.Dropped())
+	require.Len(t, all.Events(), 4)
+
+	bus.Unsubscribe(golang)
+	bus.Unsubscribe(golang)
+
+	_, ok := <-golang.Events()
+	require.False(t, ok)
+
+	// Publishing after unsubscribing neither blocks nor panics.
+	bus.Publish(events.Event{Kind: "top-posts", Subreddit: "golang"})
+	require.Len(t, all.Events(), 5)
+}
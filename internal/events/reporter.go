@@ -0,0 +1,30 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jqdurham/reddit/internal/report"
+)
+
+// Reporter publishes each report to a Bus.
+type Reporter struct {
+	bus *Bus
+}
+
+// NewReporter creates a Reporter publishing to the bus.
+func NewReporter(bus *Bus) *Reporter {
+	return &Reporter{bus: bus}
+}
+
+// Report publishes the report as an event of the report's kind.
+func (r *Reporter) Report(rep *report.Report) error {
+	data, err := json.Marshal(rep)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	r.bus.Publish(Event{Kind: rep.Kind, Subreddit: rep.Subreddit, Time: rep.GeneratedAt, Data: data})
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package events_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/events"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/stretchr/testify/require"
+)
+
+type testRow struct {
+	title string
+	ups   int
+}
+
+func (r testRow) String() string {
+	return fmt.Sprintf("(%d) - %s", r.ups, r.title)
+}
+
+func (r testRow) Values() []any {
+	return []any{r.title, r.ups}
+}
+
+func TestReporter_Report(t *testing.T) {
+	t.Parallel()
+
+	var (
+		bus = events.NewBus()
+		sub = bus.Subscribe(events.Filter{Kinds: []string{"top-posts"}}, 1)
+		now = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	err := events.NewReporter(bus).Report(&report.Report{
+		Kind:        "top-posts",
+		Title:       "Top Posts",
+		Subreddit:   "golang",
+		Columns:     []string{"title", "ups"},
+		Rows:        []report.Row{testRow{title: "Go 1.22 released", ups: 1337}},
+		GeneratedAt: now,
+	})
+	require.NoError(t, err)
+
+	ev := <-sub.Events()
+	require.Equal(t, "top-posts", ev.Kind)
+	require.Equal(t, "golang", ev.Subreddit)
+	require.Equal(t, now, ev.Time)
+	require.JSONEq(t, `{"kind":"top-posts","title":"Top Posts","subreddit":"golang",`+
+		`"generated_at":"2024-04-01T12:00:00Z","columns":["title","ups"],`+
+		`"rows":[{"title":"Go 1.22 released","ups":1337}]}`, string(ev.Data))
+}
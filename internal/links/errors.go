@@ -0,0 +1,30 @@
+package links
+
+import "fmt"
+
+// NotRedirectedError is returned when a shortener responds without a redirect location.
+type NotRedirectedError struct {
+	URL    string
+	Status int
+}
+
+func (e *NotRedirectedError) Error() string {
+	return fmt.Sprintf("no redirect from %s (status %d)", e.URL, e.Status)
+}
+
+func NewNotRedirectedError(url string, status int) *NotRedirectedError {
+	return &NotRedirectedError{URL: url, Status: status}
+}
+
+// MissingHostError is returned when a link is not absolute.
+type MissingHostError struct {
+	URL string
+}
+
+func (e *MissingHostError) Error() string {
+	return "missing host: " + e.URL
+}
+
+func NewMissingHostError(url string) *MissingHostError {
+	return &MissingHostError{URL: url}
+}
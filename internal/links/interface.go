@@ -0,0 +1,10 @@
+package links
+
+import "context"
+
+// Resolver follows a single redirect issued by a link shortener.
+//
+//go:generate mockery --name Resolver
+type Resolver interface {
+	Resolve(ctx context.Context, rawURL string) (string, error)
+}
@@ -0,0 +1,205 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+const (
+	// maxHops bounds how many shorteners are unwrapped when one redirects to another.
+	maxHops = 3
+	// maxCacheEntries bounds memory used by resolved shortener links.
+	maxCacheEntries = 10000
+	// failureTTL is how long a shortener that failed to resolve is not retried, so a broken link
+	// seen on every poll does not cost a request each time.
+	failureTTL = 10 * time.Minute
+)
+
+// DefaultShorteners returns the hosts treated as link shorteners when none are configured.
+func DefaultShorteners() []string {
+	return []string{"bit.ly", "buff.ly", "dlvr.it", "goo.gl", "is.gd", "ow.ly", "t.co", "tinyurl.com", "trib.al"}
+}
+
+// hostPrefixes are stripped so desktop, mobile and AMP links count toward the same domain.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var hostPrefixes = []string{"www.", "m.", "mobile.", "amp."}
+
+// trackingParams are query parameters that identify campaigns or referrers rather than content.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var trackingParams = map[string]struct{}{
+	"fbclid": {}, "gclid": {}, "dclid": {}, "msclkid": {}, "yclid": {}, "igshid": {}, "mc_cid": {},
+	"mc_eid": {}, "ref": {}, "ref_src": {}, "ref_url": {}, "si": {}, "spm": {}, "_ga": {}, "s_cid": {},
+}
+
+// Normalizer canonicalizes links so the same destination is counted once regardless of how it was
+// shared.
+type Normalizer struct {
+	shorteners map[string]struct{}
+	resolver   Resolver
+
+	mu    sync.Mutex
+	cache map[string]string
+	// failures holds when each shortened link that failed to resolve may be retried.
+	failures map[string]failure
+}
+
+// failure is a cached resolution error.
+type failure struct {
+	err   error
+	retry time.Time
+}
+
+// NewNormalizer creates a Normalizer that unwraps links hosted by the provided shorteners. A nil
+// resolver leaves shortened links as they are, so normalizing never touches the network.
+func NewNormalizer(shorteners []string, resolver Resolver) *Normalizer {
+	hosts := make(map[string]struct{}, len(shorteners))
+	for _, host := range shorteners {
+		hosts[stripHostPrefix(strings.ToLower(strings.TrimSpace(host)))] = struct{}{}
+	}
+
+	return &Normalizer{shorteners: hosts, resolver: resolver, cache: map[string]string{}, failures: map[string]failure{}}
+}
+
+// Normalize lower-cases the host, strips www/mobile prefixes, default ports, fragments and tracking
+// parameters, and unwraps shortened links. When a shortener cannot be resolved the shortened link
+// itself is normalized, and it is not retried for a while.
+func (n *Normalizer) Normalize(ctx context.Context, rawURL string) (*url.URL, error) {
+	uri, err := parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for hop := 0; hop < maxHops && n.resolver != nil && n.isShortener(uri.Host); hop++ {
+		resolved, cached, err := n.resolve(ctx, uri.String())
+		if err != nil {
+			logr := logger.FromContext(ctx)
+			if cached {
+				logr.Debug("unwrap shortened link", "url", uri.String(), "err", err.Error())
+			} else {
+				logr.Warn("unwrap shortened link", "url", uri.String(), "err", err.Error())
+			}
+
+			break
+		}
+
+		next, err := parse(resolved)
+		if err != nil {
+			break
+		}
+
+		uri = next
+	}
+
+	return uri, nil
+}
+
+// Domain returns the normalized host of a link.
+func (n *Normalizer) Domain(ctx context.Context, rawURL string) (string, error) {
+	uri, err := n.Normalize(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	return uri.Hostname(), nil
+}
+
+func (n *Normalizer) isShortener(host string) bool {
+	_, ok := n.shorteners[host]
+
+	return ok
+}
+
+// resolve unwraps a shortened link, reporting whether the result, or the failure, was cached.
+func (n *Normalizer) resolve(ctx context.Context, shortened string) (string, bool, error) {
+	n.mu.Lock()
+	resolved, ok := n.cache[shortened]
+	failed, hasFailed := n.failures[shortened]
+	n.mu.Unlock()
+
+	if ok {
+		return resolved, true, nil
+	}
+
+	if hasFailed && time.Now().Before(failed.retry) {
+		return "", true, failed.err
+	}
+
+	resolved, err := n.resolver.Resolve(ctx, shortened)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err != nil {
+		if len(n.failures) >= maxCacheEntries {
+			clear(n.failures)
+		}
+
+		err = fmt.Errorf("resolve: %w", err)
+		n.failures[shortened] = failure{err: err, retry: time.Now().Add(failureTTL)}
+
+		return "", false, err
+	}
+
+	if len(n.cache) >= maxCacheEntries {
+		clear(n.cache)
+	}
+
+	n.cache[shortened] = resolved
+	delete(n.failures, shortened)
+
+	return resolved, false, nil
+}
+
+func parse(rawURL string) (*url.URL, error) {
+	uri, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	if uri.Host == "" {
+		return nil, fmt.Errorf("parse url: %w", NewMissingHostError(rawURL))
+	}
+
+	host := stripHostPrefix(strings.ToLower(uri.Hostname()))
+	if port := uri.Port(); port != "" && !isDefaultPort(uri.Scheme, port) {
+		host += ":" + port
+	}
+
+	uri.Host = host
+	uri.Scheme = strings.ToLower(uri.Scheme)
+	uri.Fragment = ""
+	uri.RawFragment = ""
+
+	query := uri.Query()
+	for param := range query {
+		if _, ok := trackingParams[strings.ToLower(param)]; ok || strings.HasPrefix(strings.ToLower(param), "utm_") {
+			query.Del(param)
+		}
+	}
+
+	uri.RawQuery = query.Encode()
+
+	return uri, nil
+}
+
+func stripHostPrefix(host string) string {
+	for _, prefix := range hostPrefixes {
+		if trimmed, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(trimmed, ".") {
+			return trimmed
+		}
+	}
+
+	return host
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
@@ -0,0 +1,137 @@
+package links_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/links"
+	"github.com/jqdurham/reddit/internal/links/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errMockedFailure = errors.New("mocked failure")
+
+func TestNormalizer_Normalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rawURL   string
+		resolver func(t *testing.T) links.Resolver
+		want     string
+		errMsg   string
+	}{
+		{
+			name:   "Strips www prefix, tracking params, default port and fragment",
+			rawURL: "HTTPS://WWW.Example.com:443/a?utm_source=reddit&id=7&fbclid=abc#comments",
+			want:   "https://example.com/a?id=7",
+		},
+		{
+			name:   "Strips mobile prefix",
+			rawURL: "https://m.youtube.com/watch?v=123&si=xyz",
+			want:   "https://youtube.com/watch?v=123",
+		},
+		{
+			name:   "Keeps non-default port",
+			rawURL: "http://www.example.com:8080/",
+			want:   "http://example.com:8080/",
+		},
+		{
+			name:   "Unwraps chained shorteners",
+			rawURL: "https://bit.ly/abc",
+			resolver: func(t *testing.T) links.Resolver {
+				t.Helper()
+				m := mocks.NewResolver(t)
+				m.On("Resolve", mock.Anything, "https://bit.ly/abc").Return("https://t.co/xyz", nil).Once()
+				m.On("Resolve", mock.Anything, "https://t.co/xyz").
+					Return("https://www.github.com/jqdurham/reddit?utm_medium=social", nil).Once()
+
+				return m
+			},
+			want: "https://github.com/jqdurham/reddit",
+		},
+		{
+			name:   "Falls back to shortened link when resolution fails",
+			rawURL: "https://bit.ly/abc?utm_campaign=x",
+			resolver: func(t *testing.T) links.Resolver {
+				t.Helper()
+				m := mocks.NewResolver(t)
+				m.On("Resolve", mock.Anything, "https://bit.ly/abc").Return("", errMockedFailure).Once()
+
+				return m
+			},
+			want: "https://bit.ly/abc",
+		},
+		{
+			name:   "Rejects relative links",
+			rawURL: "/r/golang/comments/abc",
+			errMsg: "parse url: missing host: /r/golang/comments/abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resolver links.Resolver = mocks.NewResolver(t)
+			if tt.resolver != nil {
+				resolver = tt.resolver(t)
+			}
+
+			n := links.NewNormalizer([]string{"bit.ly", "www.t.co"}, resolver)
+
+			got, err := n.Normalize(context.Background(), tt.rawURL)
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				require.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNormalizer_WithoutResolver(t *testing.T) {
+	t.Parallel()
+
+	n := links.NewNormalizer(links.DefaultShorteners(), nil)
+
+	got, err := n.Normalize(context.Background(), "https://www.bit.ly/abc?utm_source=reddit")
+	require.NoError(t, err)
+	require.Equal(t, "https://bit.ly/abc", got.String())
+}
+
+func TestNormalizer_CachesResolvedLinks(t *testing.T) {
+	t.Parallel()
+
+	m := mocks.NewResolver(t)
+	m.On("Resolve", mock.Anything, "https://bit.ly/abc").Return("https://example.com/", nil).Once()
+
+	n := links.NewNormalizer(links.DefaultShorteners(), m)
+
+	for range 2 {
+		domain, err := n.Domain(context.Background(), "https://bit.ly/abc")
+		require.NoError(t, err)
+		require.Equal(t, "example.com", domain)
+	}
+}
+
+func TestNormalizer_CachesResolutionFailures(t *testing.T) {
+	t.Parallel()
+
+	m := mocks.NewResolver(t)
+	m.On("Resolve", mock.Anything, "https://bit.ly/abc").Return("", errMockedFailure).Once()
+
+	n := links.NewNormalizer(links.DefaultShorteners(), m)
+
+	// The failure is not retried right away, so the shortened link is kept without resolving again.
+	for range 2 {
+		domain, err := n.Domain(context.Background(), "https://bit.ly/abc")
+		require.NoError(t, err)
+		require.Equal(t, "bit.ly", domain)
+	}
+}
@@ -0,0 +1,46 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPResolver resolves shortened links by requesting them without following the redirect.
+type HTTPResolver struct {
+	httpClient *http.Client
+}
+
+// NewHTTPResolver creates a resolver whose requests give up after the provided timeout.
+func NewHTTPResolver(timeout time.Duration) *HTTPResolver {
+	return &HTTPResolver{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Resolve returns the location a shortened link redirects to.
+func (r *HTTPResolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	location, err := res.Location()
+	if err != nil {
+		return "", NewNotRedirectedError(rawURL, res.StatusCode)
+	}
+
+	return location.String(), nil
+}
@@ -0,0 +1,38 @@
+package links_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/links"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPResolver_Resolve(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+
+		if r.URL.Path == "/abc" {
+			http.Redirect(w, r, "https://example.com/destination", http.StatusMovedPermanently)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	r := links.NewHTTPResolver(time.Second)
+
+	got, err := r.Resolve(context.Background(), srv.URL+"/abc")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/destination", got)
+
+	_, err = r.Resolve(context.Background(), srv.URL+"/missing")
+	require.EqualError(t, err, "no redirect from "+srv.URL+"/missing (status 404)")
+}
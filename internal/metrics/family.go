@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// labelSeparator joins label values into series keys; it cannot appear in valid UTF-8.
+const labelSeparator = "\xff"
+
+// family is a named metric and its series, one per distinct set of label values.
+type family struct {
+	name, help string
+	kind       kind
+	labels     []string
+	buckets    []float64
+	series     map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+	// counts holds the cumulative observations per bucket upper bound of a histogram.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newFamily(name, help string, k kind, labels ...string) *family {
+	f := &family{name: name, help: help, kind: k, labels: labels, series: map[string]*series{}}
+
+	// Unlabelled metrics are exposed before their first observation.
+	if len(labels) == 0 {
+		f.with()
+	}
+
+	return f
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *family {
+	f := newFamily(name, help, kindHistogram, labels...)
+	f.buckets = buckets
+
+	return f
+}
+
+// with returns the series for the label values, creating it on first use.
+func (f *family) with(values ...string) *series {
+	key := strings.Join(values, labelSeparator)
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: values, counts: make([]uint64, len(f.buckets))}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+func (f *family) observe(value float64, labels ...string) {
+	s := f.with(labels...)
+	s.sum += value
+	s.count++
+
+	for i, bound := range f.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+}
+
+// write renders the family in the Prometheus text exposition format.
+func (f *family) write(sb *strings.Builder) {
+	if len(f.series) == 0 {
+		return
+	}
+
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f.writeSeries(sb, f.series[key])
+	}
+}
+
+func (f *family) writeSeries(sb *strings.Builder, s *series) {
+	if f.kind != kindHistogram {
+		fmt.Fprintf(sb, "%s%s %s\n", f.name, labelPairs(f.labels, s.labels), formatFloat(s.value))
+
+		return
+	}
+
+	names := append(slices.Clip(f.labels), "le")
+
+	for i, bound := range f.buckets {
+		fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name, labelPairs(names, append(slices.Clip(s.labels), formatFloat(bound))),
+			s.counts[i])
+	}
+
+	fmt.Fprintf(sb, "%s_bucket%s %d\n", f.name, labelPairs(names, append(slices.Clip(s.labels), "+Inf")), s.count)
+	fmt.Fprintf(sb, "%s_sum%s %s\n", f.name, labelPairs(f.labels, s.labels), formatFloat(s.sum))
+	fmt.Fprintf(sb, "%s_count%s %d\n", f.name, labelPairs(f.labels, s.labels), s.count)
+}
+
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
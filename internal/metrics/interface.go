@@ -0,0 +1,26 @@
+package metrics
+
+import "time"
+
+// Recorder receives measurements from the client, orchestrator and services.
+//
+//go:generate mockery --name Recorder
+type Recorder interface {
+	// ObserveRequest records a Reddit API request. A status of zero means no response was received.
+	ObserveRequest(endpoint string, status int, dur time.Duration)
+	// SetRateLimit records the latest X-Ratelimit-Remaining, X-Ratelimit-Used and X-Ratelimit-Reset.
+	SetRateLimit(remaining, used float64, reset time.Duration)
+	IncLogin()
+	IncTokenRefresh()
+	IncRateLimited()
+	// ObserveJob records a job run and whether it failed.
+	ObserveJob(job string, dur time.Duration, err error)
+	AddPostsIngested(subreddit string, num int)
+	// SetStage records how a pipeline stage is configured: its workers, queue capacity and what
+	// happens to items submitted while its queue is full.
+	SetStage(stage, policy string, workers, capacity int)
+	// SetQueueDepth records how many items are waiting in a pipeline stage's queue.
+	SetQueueDepth(stage string, depth int)
+	// IncDropped records an item dropped because a pipeline stage's queue was full.
+	IncDropped(stage string)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import "time"
+
+// Multi forwards every measurement to several recorders.
+type Multi struct {
+	recorders []Recorder
+}
+
+// NewMulti creates a Multi recorder.
+func NewMulti(recorders ...Recorder) *Multi {
+	return &Multi{recorders: recorders}
+}
+
+func (m *Multi) ObserveRequest(endpoint string, status int, dur time.Duration) {
+	for _, r := range m.recorders {
+		r.ObserveRequest(endpoint, status, dur)
+	}
+}
+
+func (m *Multi) SetRateLimit(remaining, used float64, reset time.Duration) {
+	for _, r := range m.recorders {
+		r.SetRateLimit(remaining, used, reset)
+	}
+}
+
+func (m *Multi) IncLogin() {
+	for _, r := range m.recorders {
+		r.IncLogin()
+	}
+}
+
+func (m *Multi) IncTokenRefresh() {
+	for _, r := range m.recorders {
+		r.IncTokenRefresh()
+	}
+}
+
+func (m *Multi) IncRateLimited() {
+	for _, r := range m.recorders {
+		r.IncRateLimited()
+	}
+}
+
+func (m *Multi) ObserveJob(job string, dur time.Duration, err error) {
+	for _, r := range m.recorders {
+		r.ObserveJob(job, dur, err)
+	}
+}
+
+func (m *Multi) AddPostsIngested(subreddit string, num int) {
+	for _, r := range m.recorders {
+		r.AddPostsIngested(subreddit, num)
+	}
+}
+
+func (m *Multi) SetStage(stage, policy string, workers, capacity int) {
+	for _, r := range m.recorders {
+		r.SetStage(stage, policy, workers, capacity)
+	}
+}
+
+func (m *Multi) SetQueueDepth(stage string, depth int) {
+	for _, r := range m.recorders {
+		r.SetQueueDepth(stage, depth)
+	}
+}
+
+func (m *Multi) IncDropped(stage string) {
+	for _, r := range m.recorders {
+		r.IncDropped(stage)
+	}
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "time"
+
+// Nop discards every measurement.
+type Nop struct{}
+
+func (Nop) ObserveRequest(string, int, time.Duration)    {}
+func (Nop) SetRateLimit(float64, float64, time.Duration) {}
+func (Nop) IncLogin()                                    {}
+func (Nop) IncTokenRefresh()                             {}
+func (Nop) IncRateLimited()                              {}
+func (Nop) ObserveJob(string, time.Duration, error)      {}
+func (Nop) AddPostsIngested(string, int)                 {}
+func (Nop) SetStage(string, string, int, int)            {}
+func (Nop) SetQueueDepth(string, int)                    {}
+func (Nop) IncDropped(string)                            {}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestBuckets are upper bounds, in seconds, for Reddit API request latencies.
+//
+//nolint:gochecknoglobals // read-only bucket layout
+var requestBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// jobBuckets are upper bounds, in seconds, for job runs which may page through full listings.
+//
+//nolint:gochecknoglobals // read-only bucket layout
+var jobBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Registry is a Recorder which exposes its measurements in the Prometheus text exposition format.
+type Registry struct {
+	mu sync.Mutex
+
+	requests, requestDuration,
+	rateRemaining, rateUsed, rateReset,
+	logins, refreshes, rateLimited,
+	jobRuns, jobErrors, jobDuration,
+	postsIngested,
+	stageWorkers, queueCapacity, queueDepth, dropped *family
+}
+
+// NewRegistry creates a Registry with every metric at its zero value.
+func NewRegistry() *Registry {
+	return &Registry{
+		requests: newFamily("reddit_api_requests_total",
+			"Reddit API requests by endpoint and response status.", kindCounter, "endpoint", "status"),
+		requestDuration: newHistogram("reddit_api_request_duration_seconds",
+			"Reddit API request latency by endpoint.", requestBuckets, "endpoint"),
+		rateRemaining: newFamily("reddit_ratelimit_remaining",
+			"Requests remaining in the current rate limit period (X-Ratelimit-Remaining).", kindGauge),
+		rateUsed: newFamily("reddit_ratelimit_used",
+			"Requests used in the current rate limit period (X-Ratelimit-Used).", kindGauge),
+		rateReset: newFamily("reddit_ratelimit_reset_seconds",
+			"Seconds until the rate limit period resets (X-Ratelimit-Reset).", kindGauge),
+		logins: newFamily("reddit_logins_total",
+			"Successful logins.", kindCounter),
+		refreshes: newFamily("reddit_token_refreshes_total",
+			"Access tokens refreshed before expiring.", kindCounter),
+		rateLimited: newFamily("reddit_rate_limited_total",
+			"Requests rejected with 429 Too Many Requests.", kindCounter),
+		jobRuns: newFamily("orchestrator_job_runs_total",
+			"Completed job runs.", kindCounter, "job"),
+		jobErrors: newFamily("orchestrator_job_errors_total",
+			"Job runs which returned an error.", kindCounter, "job"),
+		jobDuration: newHistogram("orchestrator_job_duration_seconds",
+			"Job run duration.", jobBuckets, "job"),
+		postsIngested: newFamily("reddit_posts_ingested_total",
+			"Posts ingested per subreddit.", kindCounter, "subreddit"),
+		stageWorkers: newFamily("pipeline_stage_workers",
+			"Workers consuming each pipeline stage's queue.", kindGauge, "stage"),
+		queueCapacity: newFamily("pipeline_queue_capacity",
+			"Capacity of each pipeline stage's queue, by what happens to items submitted while it is full.",
+			kindGauge, "stage", "policy"),
+		queueDepth: newFamily("pipeline_queue_depth",
+			"Items waiting in each pipeline stage's queue.", kindGauge, "stage"),
+		dropped: newFamily("pipeline_dropped_total",
+			"Items dropped because a pipeline stage's queue was full.", kindCounter, "stage"),
+	}
+}
+
+func (r *Registry) ObserveRequest(endpoint string, status int, dur time.Duration) {
+	statusLabel := "error"
+	if status != 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests.with(endpoint, statusLabel).value++
+	r.requestDuration.observe(dur.Seconds(), endpoint)
+}
+
+func (r *Registry) SetRateLimit(remaining, used float64, reset time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rateRemaining.with().value = remaining
+	r.rateUsed.with().value = used
+	r.rateReset.with().value = reset.Seconds()
+}
+
+func (r *Registry) IncLogin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logins.with().value++
+}
+
+func (r *Registry) IncTokenRefresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refreshes.with().value++
+}
+
+func (r *Registry) IncRateLimited() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rateLimited.with().value++
+}
+
+func (r *Registry) ObserveJob(job string, dur time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobRuns.with(job).value++
+	r.jobDuration.observe(dur.Seconds(), job)
+
+	if err != nil {
+		r.jobErrors.with(job).value++
+	}
+}
+
+func (r *Registry) AddPostsIngested(subreddit string, num int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.postsIngested.with(subreddit).value += float64(num)
+}
+
+func (r *Registry) SetStage(stage, policy string, workers, capacity int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stageWorkers.with(stage).value = float64(workers)
+	r.queueCapacity.with(stage, policy).value = float64(capacity)
+}
+
+func (r *Registry) SetQueueDepth(stage string, depth int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.queueDepth.with(stage).value = float64(depth)
+}
+
+func (r *Registry) IncDropped(stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropped.with(stage).value++
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	sb := &strings.Builder{}
+
+	r.mu.Lock()
+	for _, f := range []*family{
+		r.requests, r.requestDuration, r.rateRemaining, r.rateUsed, r.rateReset, r.logins, r.refreshes,
+		r.rateLimited, r.jobRuns, r.jobErrors, r.jobDuration, r.postsIngested, r.stageWorkers, r.queueCapacity,
+		r.queueDepth, r.dropped,
+	} {
+		f.write(sb)
+	}
+	r.mu.Unlock()
+
+	n, err := io.WriteString(w, sb.String())
+	if err != nil {
+		return int64(n), fmt.Errorf("write metrics: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// ServeHTTP exposes the metrics for scraping.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = r.WriteTo(w)
+}
@@ -0,0 +1,139 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	registry.ObserveRequest("/r/golang/top", http.StatusOK, 30*time.Millisecond)
+	registry.ObserveRequest("/r/golang/top", http.StatusTooManyRequests, 2*time.Second)
+	registry.ObserveRequest(`/r/"quoted"/top`, 0, time.Millisecond)
+	registry.SetRateLimit(598, 2, 5*time.Minute)
+	registry.IncLogin()
+	registry.IncRateLimited()
+	registry.ObserveJob("top-posts:golang", 3*time.Second, nil)
+	registry.ObserveJob("top-posts:golang", 200*time.Millisecond, errors.New("boom"))
+	registry.AddPostsIngested("golang", 25)
+	registry.AddPostsIngested("golang", 5)
+	registry.SetStage("parse", "block", 4, 64)
+	registry.SetQueueDepth("parse", 3)
+	registry.IncDropped("report")
+
+	buf := &bytes.Buffer{}
+	_, err := registry.WriteTo(buf)
+	require.NoError(t, err)
+
+	require.Equal(t, `# HELP reddit_api_requests_total Reddit API requests by endpoint and response status.
+# TYPE reddit_api_requests_total counter
+reddit_api_requests_total{endpoint="/r/\"quoted\"/top",status="error"} 1
+reddit_api_requests_total{endpoint="/r/golang/top",status="200"} 1
+reddit_api_requests_total{endpoint="/r/golang/top",status="429"} 1
+# HELP reddit_api_request_duration_seconds Reddit API request latency by endpoint.
+# TYPE reddit_api_request_duration_seconds histogram
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.005"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.01"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.025"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.05"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.1"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.25"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="0.5"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="1"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="2.5"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="5"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="10"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/\"quoted\"/top",le="+Inf"} 1
+reddit_api_request_duration_seconds_sum{endpoint="/r/\"quoted\"/top"} 0.001
+reddit_api_request_duration_seconds_count{endpoint="/r/\"quoted\"/top"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.005"} 0
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.01"} 0
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.025"} 0
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.05"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.1"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.25"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="0.5"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="1"} 1
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="2.5"} 2
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="5"} 2
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="10"} 2
+reddit_api_request_duration_seconds_bucket{endpoint="/r/golang/top",le="+Inf"} 2
+reddit_api_request_duration_seconds_sum{endpoint="/r/golang/top"} 2.03
+reddit_api_request_duration_seconds_count{endpoint="/r/golang/top"} 2
+# HELP reddit_ratelimit_remaining Requests remaining in the current rate limit period (X-Ratelimit-Remaining).
+# TYPE reddit_ratelimit_remaining gauge
+reddit_ratelimit_remaining 598
+# HELP reddit_ratelimit_used Requests used in the current rate limit period (X-Ratelimit-Used).
+# TYPE reddit_ratelimit_used gauge
+reddit_ratelimit_used 2
+# HELP reddit_ratelimit_reset_seconds Seconds until the rate limit period resets (X-Ratelimit-Reset).
+# TYPE reddit_ratelimit_reset_seconds gauge
+reddit_ratelimit_reset_seconds 300
+# HELP reddit_logins_total Successful logins.
+# TYPE reddit_logins_total counter
+reddit_logins_total 1
+# HELP reddit_token_refreshes_total Access tokens refreshed before expiring.
+# TYPE reddit_token_refreshes_total counter
+reddit_token_refreshes_total 0
+# HELP reddit_rate_limited_total Requests rejected with 429 Too Many Requests.
+# TYPE reddit_rate_limited_total counter
+reddit_rate_limited_total 1
+# HELP orchestrator_job_runs_total Completed job runs.
+# TYPE orchestrator_job_runs_total counter
+orchestrator_job_runs_total{job="top-posts:golang"} 2
+# HELP orchestrator_job_errors_total Job runs which returned an error.
+# TYPE orchestrator_job_errors_total counter
+orchestrator_job_errors_total{job="top-posts:golang"} 1
+# HELP orchestrator_job_duration_seconds Job run duration.
+# TYPE orchestrator_job_duration_seconds histogram
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="0.1"} 0
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="0.5"} 1
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="1"} 1
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="2.5"} 1
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="5"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="10"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="30"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="60"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="120"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="300"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="600"} 2
+orchestrator_job_duration_seconds_bucket{job="top-posts:golang",le="+Inf"} 2
+orchestrator_job_duration_seconds_sum{job="top-posts:golang"} 3.2
+orchestrator_job_duration_seconds_count{job="top-posts:golang"} 2
+# HELP reddit_posts_ingested_total Posts ingested per subreddit.
+# TYPE reddit_posts_ingested_total counter
+reddit_posts_ingested_total{subreddit="golang"} 30
+# HELP pipeline_stage_workers Workers consuming each pipeline stage's queue.
+# TYPE pipeline_stage_workers gauge
+pipeline_stage_workers{stage="parse"} 4
+# HELP pipeline_queue_capacity Capacity of each pipeline stage's queue, by what happens to items submitted while it is full.
+# TYPE pipeline_queue_capacity gauge
+pipeline_queue_capacity{stage="parse",policy="block"} 64
+# HELP pipeline_queue_depth Items waiting in each pipeline stage's queue.
+# TYPE pipeline_queue_depth gauge
+pipeline_queue_depth{stage="parse"} 3
+# HELP pipeline_dropped_total Items dropped because a pipeline stage's queue was full.
+# TYPE pipeline_dropped_total counter
+pipeline_dropped_total{stage="report"} 1
+`, buf.String())
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	metrics.NewRegistry().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), "reddit_logins_total 0\n")
+}
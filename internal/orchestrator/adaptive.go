@@ -0,0 +1,70 @@
+package orchestrator
+
+import (
+	"sync"
+	"time"
+)
+
+// adaptiveSmoothing weighs the latest arrival rate against the previous estimate, so one unusually
+// busy or quiet poll does not swing the interval.
+const adaptiveSmoothing = 0.5
+
+// Adaptive paces polling to how often new items arrive: it picks the interval expected to find
+// Target new items per poll, within Min and Max. Busy sources are polled often enough not to miss
+// items, and quiet ones rarely, freeing the request budget. It may pace several jobs polling the
+// same source.
+type Adaptive struct {
+	min, max time.Duration
+	target   float64
+
+	mu       sync.Mutex
+	interval time.Duration
+	// rate is the smoothed arrival rate in items per second, valid once observed is set.
+	rate     float64
+	observed bool
+}
+
+// NewAdaptive creates an Adaptive starting at initial, clamped to min and max, until the first
+// observation.
+func NewAdaptive(initial, minInterval, maxInterval time.Duration, target int) *Adaptive {
+	return &Adaptive{
+		min:      minInterval,
+		max:      maxInterval,
+		target:   float64(target),
+		interval: min(max(initial, minInterval), maxInterval),
+	}
+}
+
+// Observe records that arrived new items were found by a poll elapsed after the previous one, and
+// returns the interval chosen for the next polls.
+func (a *Adaptive) Observe(arrived int, elapsed time.Duration) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if elapsed <= 0 {
+		return a.interval
+	}
+
+	rate := float64(arrived) / elapsed.Seconds()
+	if a.observed {
+		rate = adaptiveSmoothing*rate + (1-adaptiveSmoothing)*a.rate
+	}
+
+	a.rate, a.observed = rate, true
+
+	if rate <= 0 {
+		a.interval = a.max
+	} else {
+		a.interval = min(max(time.Duration(a.target/rate*float64(time.Second)), a.min), a.max)
+	}
+
+	return a.interval
+}
+
+// Interval returns the current interval.
+func (a *Adaptive) Interval() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.interval
+}
@@ -0,0 +1,71 @@
+package orchestrator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptive_Observe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		initial time.Duration
+		// polls are the new items found by successive polls a minute apart.
+		polls []int
+		want  []time.Duration
+	}{
+		{
+			name:    "Starts within bounds",
+			initial: time.Hour,
+			want:    nil,
+		},
+		{
+			name:    "Targets new items per poll",
+			initial: 5 * time.Minute,
+			polls:   []int{10, 10},
+			want:    []time.Duration{time.Minute, time.Minute},
+		},
+		{
+			name:    "Smooths changes in the arrival rate",
+			initial: 5 * time.Minute,
+			polls:   []int{10, 30},
+			want:    []time.Duration{time.Minute, 30 * time.Second},
+		},
+		{
+			name:    "Busy sources are polled no faster than the minimum",
+			initial: 5 * time.Minute,
+			polls:   []int{100},
+			want:    []time.Duration{15 * time.Second},
+		},
+		{
+			name:    "Quiet sources are polled no slower than the maximum",
+			initial: 5 * time.Minute,
+			polls:   []int{0, 1},
+			want:    []time.Duration{30 * time.Minute, 20 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			adaptive := orchestrator.NewAdaptive(tt.initial, 15*time.Second, 30*time.Minute, 10)
+			if tt.want == nil {
+				assert.Equal(t, 30*time.Minute, adaptive.Interval())
+
+				return
+			}
+
+			got := make([]time.Duration, len(tt.polls))
+			for i, arrived := range tt.polls {
+				got[i] = adaptive.Observe(arrived, time.Minute)
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want[len(tt.want)-1], adaptive.Interval())
+		})
+	}
+}
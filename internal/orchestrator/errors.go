@@ -0,0 +1,46 @@
+package orchestrator
+
+import "fmt"
+
+// JobError is sent on the error channel when a job fails with an error its policy escalates.
+type JobError struct {
+	Job      string
+	Category Category
+	Err      error
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("job %s failed (%s): %v", e.Job, e.Category, e.Err)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+func NewJobError(job string, category Category, err error) *JobError {
+	return &JobError{Job: job, Category: category, Err: err}
+}
+
+// DuplicateJobError is returned when adding a job under a name already registered.
+type DuplicateJobError struct {
+	Name string
+}
+
+func (e *DuplicateJobError) Error() string {
+	return "job already registered: " + e.Name
+}
+
+func NewDuplicateJobError(name string) *DuplicateJobError {
+	return &DuplicateJobError{Name: name}
+}
+
+// StoppedError is returned when adding a job once the runner has stopped scheduling jobs.
+type StoppedError struct{}
+
+func (e *StoppedError) Error() string {
+	return "runner stopped"
+}
+
+func NewStoppedError() *StoppedError {
+	return &StoppedError{}
+}
@@ -1,4 +1,114 @@
 package orchestrator
 
-// Job represents a unit of work to run continuously.
-type Job func() error
+import (
+	"context"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/metrics"
+)
+
+// Job represents a unit of work to run continuously. The context is cancelled when a shutdown
+// times out waiting for the run to finish.
+type Job func(ctx context.Context) error
+
+// Spec registers a Job under a unique name with its schedule. A zero Interval runs the job again
+// as soon as the previous run finishes.
+type Spec struct {
+	Name string
+	Job  Job
+	// Interval is the pause between the end of one run and the start of the next.
+	Interval time.Duration
+	// Jitter adds a random delay of up to Jitter to every pause, so jobs sharing an interval drift
+	// apart rather than contend for the rate limiter at once.
+	Jitter time.Duration
+	// InitialDelay postpones the first run.
+	InitialDelay time.Duration
+	// Policy handles the job's failures.
+	Policy Policy
+	// Adaptive, when set, replaces Interval with one paced to how often new items arrive.
+	Adaptive *Adaptive
+	// Waiting, when set, reports whether the job is waiting for its turn to make a request, e.g. on
+	// the rate limiter.
+	Waiting func() bool
+}
+
+// Instrument wraps a Job so each run's duration and outcome is recorded under the provided name.
+func Instrument(name string, recorder metrics.Recorder, job Job) Job {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := job(ctx)
+		recorder.ObserveJob(name, time.Since(start), err)
+
+		return err
+	}
+}
+
+// Summary describes a job's runs and current state.
+type Summary struct {
+	Name string
+	// Interval is the job's current pause between runs.
+	Interval time.Duration
+	// Runs counts runs, each including its retries, and Failures counts those that failed.
+	Runs, Failures int
+	// ConsecutiveFailures counts the failed runs since the latest successful one.
+	ConsecutiveFailures int
+	// LastStart is when the latest run started, and LastDuration how long it took.
+	LastStart    time.Time
+	LastDuration time.Duration
+	// LastErr is the error of the latest failed run.
+	LastErr error
+	// Running is set while a run is in progress, and Waiting while that run waits for its turn to
+	// make a request.
+	Running, Waiting bool
+	// NextRun is when the next run is scheduled; it is zero while running and once stopped.
+	NextRun time.Time
+	// Disabled is set when the job stopped after too many consecutive failed runs.
+	Disabled bool
+	// Interrupted is set when the latest failed run was cancelled by a shutdown.
+	Interrupted bool
+}
+
+// Status is the JSON view of a Summary.
+type Status struct {
+	Name                string     `json:"name"`
+	IntervalSeconds     float64    `json:"interval_seconds"`
+	Runs                int        `json:"runs"`
+	Failures            int        `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastStart           *time.Time `json:"last_start,omitempty"`
+	LastDurationSeconds float64    `json:"last_duration_seconds"`
+	LastError           string     `json:"last_error,omitempty"`
+	Running             bool       `json:"running"`
+	Waiting             bool       `json:"waiting"`
+	NextRun             *time.Time `json:"next_run,omitempty"`
+	Disabled            bool       `json:"disabled"`
+}
+
+// NewStatus converts a Summary to its JSON view.
+func NewStatus(s Summary) Status {
+	status := Status{
+		Name:                s.Name,
+		IntervalSeconds:     s.Interval.Seconds(),
+		Runs:                s.Runs,
+		Failures:            s.Failures,
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		LastDurationSeconds: s.LastDuration.Seconds(),
+		Running:             s.Running,
+		Waiting:             s.Waiting,
+		Disabled:            s.Disabled,
+	}
+
+	if !s.LastStart.IsZero() {
+		status.LastStart = &s.LastStart
+	}
+
+	if !s.NextRun.IsZero() {
+		status.NextRun = &s.NextRun
+	}
+
+	if s.LastErr != nil {
+		status.LastError = s.LastErr.Error()
+	}
+
+	return status
+}
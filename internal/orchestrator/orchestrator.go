@@ -2,27 +2,386 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/reddit"
 )
 
-// Run executes provided Jobs perpetually, sending errors back to caller.
-func Run(ctx context.Context, errCh chan<- error, jobs ...Job) {
+// Runner is a handle on running jobs, which may be added and removed while running.
+type Runner struct {
+	// ctx ends scheduling of new runs and runCtx is passed to each run; stop and cancel cancel them.
+	ctx, runCtx  context.Context
+	stop, cancel context.CancelFunc
+	errCh        chan<- error
+	wg           sync.WaitGroup
+
+	mu sync.Mutex
+	// names lists the jobs in the order they were added.
+	names []string
+	jobs  map[string]*job
+}
+
+// job is a registered Spec and its state.
+type job struct {
+	spec    Spec
+	trigger chan struct{}
+	// remove ends scheduling of the job's runs.
+	remove  context.CancelFunc
+	summary Summary
+}
+
+// Add registers and starts a job. Job names must be unique.
+func (r *Runner) Add(spec Spec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ctx.Err() != nil {
+		return NewStoppedError()
+	}
+
+	if _, ok := r.jobs[spec.Name]; ok {
+		return NewDuplicateJobError(spec.Name)
+	}
+
+	ctx, remove := context.WithCancel(r.ctx)
+	j := &job{
+		spec:    spec,
+		trigger: make(chan struct{}, 1),
+		remove:  remove,
+		summary: Summary{Name: spec.Name, NextRun: time.Now().Add(spec.InitialDelay)},
+	}
+	r.jobs[spec.Name] = j
+	r.names = append(r.names, spec.Name)
+
+	r.wg.Add(1)
+
+	go r.loop(ctx, j)
+
+	return nil
+}
+
+// Remove unregisters the named job so it is no longer scheduled; a run in flight is left to
+// finish. It reports whether the job existed.
+func (r *Runner) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[name]
+	if !ok {
+		return false
+	}
+
+	j.remove()
+	delete(r.jobs, name)
+	r.names = slices.DeleteFunc(r.names, func(n string) bool { return n == name })
+
+	return true
+}
+
+// RunNow wakes the named job so it runs without waiting for the rest of its pause. A job that is
+// already running runs again as soon as it finishes. It reports whether the job exists.
+func (r *Runner) RunNow(name string) bool {
+	r.mu.Lock()
+	j, ok := r.jobs[name]
+	r.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// Wait blocks until every job has stopped, either because the context passed to Run was cancelled
+// or because the job was disabled or removed, and returns a summary of each job still registered
+// in the order they were added. Runs in flight when the context is cancelled are left to finish.
+func (r *Runner) Wait() []Summary {
+	r.wg.Wait()
+
+	return r.Summaries()
+}
+
+// Shutdown stops scheduling new runs and waits up to timeout for runs in flight to finish, after
+// which they are cancelled. It returns a summary of each job still registered in the order they
+// were added.
+func (r *Runner) Shutdown(timeout time.Duration) []Summary {
+	r.mu.Lock()
+	r.stop()
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		r.wg.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		r.cancel()
+		<-done
+	}
+
+	return r.Summaries()
+}
+
+// Summaries returns a summary of each registered job so far, in the order they were added.
+func (r *Runner) Summaries() []Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Summary, len(r.names))
+	for i, name := range r.names {
+		out[i] = r.jobs[name].status()
+	}
+
+	return out
+}
+
+// Summary returns a summary of the named job so far, reporting whether it is registered.
+func (r *Runner) Summary(name string) (Summary, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[name]
+	if !ok {
+		return Summary{}, false
+	}
+
+	return j.status(), true
+}
+
+// ServeHTTP writes a summary of each registered job as JSON.
+func (r *Runner) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	summaries := r.Summaries()
+
+	jobs := make([]Status, len(summaries))
+	for i, s := range summaries {
+		jobs[i] = NewStatus(s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"jobs": jobs})
+}
+
+// status returns the job's summary with its current interval and whether it is waiting.
+func (j *job) status() Summary {
+	s := j.summary
+	s.Interval = interval(j.spec)
+	s.Waiting = s.Running && j.spec.Waiting != nil && j.spec.Waiting()
+
+	return s
+}
+
+// record updates a job's summary.
+func (r *Runner) record(j *job, update func(s *Summary)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	update(&j.summary)
+}
+
+// Run executes provided Jobs perpetually on their schedules, handling failures as each job's
+// Policy says and sending escalated errors back to caller as *JobError. Jobs stop being scheduled
+// once the context is cancelled; the context passed to each run is only cancelled by
+// Runner.Shutdown. Specs reusing a name are logged and skipped.
+func Run(ctx context.Context, errCh chan<- error, specs ...Spec) *Runner {
+	runner := &Runner{errCh: errCh, jobs: make(map[string]*job, len(specs))}
+	runner.runCtx, runner.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	runner.ctx, runner.stop = context.WithCancel(ctx)
+
+	for _, spec := range specs {
+		if err := runner.Add(spec); err != nil {
+			logger.FromContext(ctx).Error(err.Error())
+		}
+	}
+
+	return runner
+}
+
+// loop runs a job on its schedule until its context is cancelled or it is disabled.
+func (r *Runner) loop(ctx context.Context, j *job) {
+	defer r.wg.Done()
+	defer r.record(j, func(s *Summary) { s.NextRun = time.Time{} })
+
+	if !sleep(ctx, j.spec.InitialDelay, j.trigger) {
+		return
+	}
+
+	for failures := 0; ; {
+		start := time.Now()
+		r.record(j, func(s *Summary) {
+			s.Running, s.LastStart, s.NextRun = true, start, time.Time{}
+		})
+
+		err := execute(ctx, r.runCtx, j.spec)
+
+		// A rejected run, e.g. by an open circuit, did no work, so it neither counts towards disabling
+		// the job nor is reported again; the circuit retries the subreddit once it cools down.
+		turnedAway := rejected(err)
+
+		switch {
+		case err == nil:
+			failures = 0
+		case !turnedAway && ctx.Err() == nil:
+			failures++
+		}
+
+		r.record(j, func(s *Summary) {
+			s.Running, s.LastDuration = false, time.Since(start)
+			s.Runs++
+			s.ConsecutiveFailures = failures
+			if err != nil {
+				s.Failures++
+				s.LastErr = err
+				s.Interrupted = r.runCtx.Err() != nil
+			}
+		})
+
+		if err != nil && !turnedAway && ctx.Err() == nil && !fail(ctx, j.spec, err, failures, r.errCh) {
+			r.record(j, func(s *Summary) { s.Disabled = true })
+
+			return
+		}
+
+		wait := pause(j.spec)
+		r.record(j, func(s *Summary) { s.NextRun = time.Now().Add(wait) })
+
+		if !sleep(ctx, wait, j.trigger) {
+			return
+		}
+	}
+}
+
+// execute runs the job, waiting out rate limits and retrying transient failures as its policy
+// allows, and returns the error the run finally failed with. Waits end early once scheduling stops.
+func execute(ctx, runCtx context.Context, spec Spec) error {
 	logr := logger.FromContext(ctx)
 
-	for i := range jobs {
-		go func(ctx context.Context, job Job) {
-			for {
-				select {
-				case <-ctx.Done():
-					errCh <- ctx.Err()
-				default:
-					if err := job(); err != nil {
-						logr.Error(err.Error())
-						errCh <- err
-					}
-				}
+	for retry := 0; ; {
+		err := spec.Job(runCtx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		category := Classify(err)
+		if spec.Policy.escalates(category) {
+			return err
+		}
+
+		var wait time.Duration
+
+		switch category {
+		case CategoryRateLimited:
+			wait = spec.Policy.Backoff
+
+			// A reset that is imminent still waits the base backoff, so a misreported limit does not
+			// spin, and a distant one is checked again once the backoff is exhausted.
+			var rateLimited *reddit.RateLimitExceededError
+			if errors.As(err, &rateLimited) {
+				wait = max(wait, rateLimited.ResetsIn)
+			}
+
+			wait = min(wait, maxBackoff)
+		case CategoryTransient:
+			if retry >= spec.Policy.Retries {
+				return err
 			}
-		}(ctx, jobs[i])
+
+			wait = spec.Policy.backoff(retry)
+			retry++
+		default:
+			return err
+		}
+
+		logr.Warn("job will run again", "job", spec.Name, "category", category, "wait", wait, "err", err.Error())
+
+		if !sleep(ctx, wait, nil) {
+			return err
+		}
 	}
 }
+
+// fail handles a failed run, returning false when the job should stop.
+func fail(ctx context.Context, spec Spec, err error, failures int, errCh chan<- error) bool {
+	logr := logger.FromContext(ctx)
+
+	jobErr := NewJobError(spec.Name, Classify(err), err)
+	logr.Error(jobErr.Error())
+
+	if spec.Policy.escalates(jobErr.Category) {
+		select {
+		case errCh <- jobErr:
+		case <-ctx.Done():
+		}
+	}
+
+	if spec.Policy.DisableAfter > 0 && failures >= spec.Policy.DisableAfter {
+		logr.Error("job disabled", "job", spec.Name, "consecutive_failures", failures)
+
+		return false
+	}
+
+	return true
+}
+
+// interval returns the job's current pause between runs, before jitter.
+func interval(spec Spec) time.Duration {
+	if spec.Adaptive != nil {
+		return spec.Adaptive.Interval()
+	}
+
+	return spec.Interval
+}
+
+// pause returns how long to wait before the job's next run.
+func pause(spec Spec) time.Duration {
+	if spec.Jitter <= 0 {
+		return interval(spec)
+	}
+
+	return interval(spec) + rand.N(spec.Jitter+1) //nolint:gosec // jitter needs no cryptographic randomness.
+}
+
+// sleep waits for d to elapse or the job to be triggered, returning false if the context is
+// cancelled first.
+func sleep(ctx context.Context, d time.Duration, trigger <-chan struct{}) bool {
+	if d <= 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	case <-trigger:
+	}
+
+	return true
+}
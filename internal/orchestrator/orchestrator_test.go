@@ -2,28 +2,40 @@ package orchestrator_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	metricsmocks "github.com/jqdurham/reddit/internal/metrics/mocks"
 	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/reddit"
 	testmocks "github.com/jqdurham/reddit/test/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-var errMockedFailure = errors.New("mocked failure")
+var (
+	errMockedFailure = errors.New("mocked failure")
+	escalateAll      = orchestrator.Policy{Escalate: orchestrator.Categories()}
+)
 
 func TestRun(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name   string
-		jobs   func(t *testing.T, m *testmocks.Errorer) []orchestrator.Job
+		jobs   func(t *testing.T, m *testmocks.Errorer) []orchestrator.Spec
 		errMsg string
 	}{
 		{
 			name: "Error on 5th iteration of single job",
-			jobs: func(t *testing.T, m *testmocks.Errorer) []orchestrator.Job {
+			jobs: func(t *testing.T, m *testmocks.Errorer) []orchestrator.Spec {
 				t.Helper()
 				m.On("Err").Return(nil).Times(4)
 				m.On("Err").Return(errMockedFailure).Once()
@@ -31,15 +43,15 @@ func TestRun(t *testing.T) {
 				// and there is some delay whilst the error is sent back through errCh,
 				// the context is cancelled, and that cancellation propagates.
 				m.On("Err").Return(nil)
-				job := func() error { return m.Err() }
+				job := func(context.Context) error { return m.Err() }
 
-				return []orchestrator.Job{job}
+				return []orchestrator.Spec{{Name: "job", Job: job, Policy: escalateAll}}
 			},
-			errMsg: errMockedFailure.Error(),
+			errMsg: "job job failed (transient): mocked failure",
 		},
 		{
 			name: "Error on 4th iteration of the 2nd job",
-			jobs: func(t *testing.T, m *testmocks.Errorer) []orchestrator.Job {
+			jobs: func(t *testing.T, m *testmocks.Errorer) []orchestrator.Spec {
 				t.Helper()
 				m.On("Err").Return(nil)
 				m.On("Err2").Return(nil).Times(3)
@@ -49,23 +61,28 @@ func TestRun(t *testing.T) {
 				// the context is cancelled, and that cancellation propagates.
 				m.On("Err2").Return(nil)
 
-				job1 := func() error { return m.Err() }
-				job2 := func() error { return m.Err2() }
+				// The 2nd job waits for the 1st to have run, otherwise it may fail before the 1st is
+				// ever scheduled.
+				started := make(chan struct{})
+				var once sync.Once
 
-				return []orchestrator.Job{job1, job2}
-			},
-			errMsg: errMockedFailure.Error(),
-		},
-		{
-			name: "Context cancellation exits runner",
-			jobs: func(t *testing.T, m *testmocks.Errorer) []orchestrator.Job {
-				t.Helper()
-				m.On("Err").Return(nil)
-				job := func() error { return m.Err() }
+				job1 := func(context.Context) error {
+					once.Do(func() { close(started) })
+
+					return m.Err()
+				}
+				job2 := func(context.Context) error {
+					<-started
+
+					return m.Err2()
+				}
 
-				return []orchestrator.Job{job}
+				return []orchestrator.Spec{
+					{Name: "job1", Job: job1, Policy: escalateAll},
+					{Name: "job2", Job: job2, Policy: escalateAll},
+				}
 			},
-			errMsg: "context deadline exceeded",
+			errMsg: "job job2 failed (transient): mocked failure",
 		},
 	}
 	for _, tt := range tests {
@@ -90,3 +107,453 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_Schedule(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errCh   = make(chan error)
+		ran     = make(chan time.Time, 8)
+		started = time.Now()
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	runner := orchestrator.Run(ctx, errCh, orchestrator.Spec{
+		Name:         "job",
+		Interval:     40 * time.Millisecond,
+		Jitter:       10 * time.Millisecond,
+		InitialDelay: 20 * time.Millisecond,
+		Job: func(context.Context) error {
+			ran <- time.Now()
+
+			return nil
+		},
+	})
+
+	first, second := <-ran, <-ran
+	assert.GreaterOrEqual(t, first.Sub(started), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, second.Sub(first), 40*time.Millisecond)
+
+	cancel()
+	assert.GreaterOrEqual(t, runner.Wait()[0].Runs, 2)
+}
+
+func TestRunner_Wait(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ran := make(chan struct{}, 1)
+	runner := orchestrator.Run(ctx, make(chan error), orchestrator.Spec{
+		Name:     "job",
+		Interval: 10 * time.Millisecond,
+		Job: func(context.Context) error {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+
+			return nil
+		},
+	})
+
+	<-ran
+	cancel()
+
+	got := runner.Wait()
+	require.Len(t, got, 1)
+	assert.Equal(t, "job", got[0].Name)
+	assert.Positive(t, got[0].Runs)
+	assert.Zero(t, got[0].Failures)
+
+	// Wait also returns once every job is disabled.
+	runner = orchestrator.Run(context.Background(), make(chan error), orchestrator.Spec{
+		Name:   "disabled",
+		Policy: orchestrator.Policy{DisableAfter: 2},
+		Job: func(context.Context) error {
+			return reddit.NewMissingInputError("subreddit")
+		},
+	})
+
+	assert.Equal(t, []orchestrator.Summary{{
+		Name: "disabled", Runs: 2, Failures: 2, ConsecutiveFailures: 2, LastErr: reddit.NewMissingInputError("subreddit"),
+		Disabled: true,
+	}}, untimed(runner.Wait()))
+}
+
+func TestRunner_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		job     func(ctx context.Context) error
+		timeout time.Duration
+		want    orchestrator.Summary
+	}{
+		{
+			name: "In-flight run finishes",
+			job: func(context.Context) error {
+				time.Sleep(30 * time.Millisecond)
+
+				return nil
+			},
+			timeout: time.Second,
+			want:    orchestrator.Summary{Name: "job", Interval: time.Hour, Runs: 1},
+		},
+		{
+			name: "In-flight run is cancelled after the timeout",
+			job: func(ctx context.Context) error {
+				<-ctx.Done()
+
+				return ctx.Err()
+			},
+			timeout: 20 * time.Millisecond,
+			want: orchestrator.Summary{
+				Name: "job", Interval: time.Hour, Runs: 1, Failures: 1, LastErr: context.Canceled, Interrupted: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			started := make(chan struct{})
+			runner := orchestrator.Run(context.Background(), make(chan error), orchestrator.Spec{
+				Name:     "job",
+				Interval: time.Hour,
+				Job: func(ctx context.Context) error {
+					close(started)
+
+					return tt.job(ctx)
+				},
+			})
+
+			<-started
+			assert.Equal(t, []orchestrator.Summary{tt.want}, untimed(runner.Shutdown(tt.timeout)))
+		})
+	}
+}
+
+func TestRun_Adaptive(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var (
+		adaptive = orchestrator.NewAdaptive(time.Hour, 10*time.Millisecond, time.Hour, 1)
+		runs     atomic.Int32
+	)
+
+	runner := orchestrator.Run(ctx, make(chan error), orchestrator.Spec{
+		Name:     "job",
+		Interval: time.Hour,
+		Adaptive: adaptive,
+		Job: func(context.Context) error {
+			// A busy source shortens the pause from the hour the job started with.
+			adaptive.Observe(100, time.Second)
+			runs.Add(1)
+
+			return nil
+		},
+	})
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, runner.Summaries()[0].Interval)
+}
+
+func TestRunner_AddRemove(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var (
+		runs   atomic.Int32
+		ran    = make(chan struct{}, 1)
+		runner = orchestrator.Run(ctx, make(chan error))
+		spec   = orchestrator.Spec{
+			Name:     "top-posts:golang",
+			Interval: 5 * time.Millisecond,
+			Job: func(context.Context) error {
+				runs.Add(1)
+
+				select {
+				case ran <- struct{}{}:
+				default:
+				}
+
+				return nil
+			},
+		}
+	)
+
+	require.NoError(t, runner.Add(spec))
+	<-ran
+
+	var dupErr *orchestrator.DuplicateJobError
+	require.ErrorAs(t, runner.Add(spec), &dupErr)
+	assert.Equal(t, "top-posts:golang", dupErr.Name)
+	assert.Equal(t, []string{"top-posts:golang"}, summaryNames(runner.Summaries()))
+
+	assert.False(t, runner.Remove("top-posts:rust"))
+	assert.True(t, runner.Remove("top-posts:golang"))
+	assert.False(t, runner.RunNow("top-posts:golang"))
+	assert.Empty(t, runner.Summaries())
+
+	// A run in flight may still finish, but no more are scheduled.
+	time.Sleep(20 * time.Millisecond)
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+
+	// The name may be registered again.
+	require.NoError(t, runner.Add(spec))
+	<-ran
+
+	runner.Shutdown(time.Second)
+
+	var stoppedErr *orchestrator.StoppedError
+	require.ErrorAs(t, runner.Add(orchestrator.Spec{Name: "late"}), &stoppedErr)
+}
+
+func TestRunner_Summary(t *testing.T) {
+	t.Parallel()
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		runner  = orchestrator.Run(context.Background(), make(chan error), orchestrator.Spec{
+			Name:     "top-posts:golang",
+			Interval: time.Hour,
+			Waiting:  func() bool { return true },
+			Job: func(context.Context) error {
+				close(started)
+				<-release
+
+				return errMockedFailure
+			},
+		})
+	)
+
+	t.Cleanup(func() { runner.Shutdown(time.Second) })
+
+	_, ok := runner.Summary("top-posts:rust")
+	assert.False(t, ok)
+
+	<-started
+
+	running, ok := runner.Summary("top-posts:golang")
+	require.True(t, ok)
+	assert.True(t, running.Running)
+	assert.True(t, running.Waiting)
+	assert.False(t, running.LastStart.IsZero())
+	assert.True(t, running.NextRun.IsZero())
+
+	close(release)
+
+	require.Eventually(t, func() bool {
+		s, _ := runner.Summary("top-posts:golang")
+
+		return !s.NextRun.IsZero()
+	}, time.Second, time.Millisecond)
+
+	done, _ := runner.Summary("top-posts:golang")
+	assert.False(t, done.Running)
+	assert.False(t, done.Waiting)
+	assert.Equal(t, 1, done.ConsecutiveFailures)
+	assert.WithinDuration(t, done.LastStart.Add(done.LastDuration).Add(time.Hour), done.NextRun, 100*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	runner.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Jobs []orchestrator.Status `json:"jobs"`
+	}
+
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Jobs, 1)
+	assert.Equal(t, "top-posts:golang", body.Jobs[0].Name)
+	assert.Equal(t, "mocked failure", body.Jobs[0].LastError)
+	assert.Equal(t, 1, body.Jobs[0].ConsecutiveFailures)
+	assert.Equal(t, 3600.0, body.Jobs[0].IntervalSeconds)
+	assert.NotNil(t, body.Jobs[0].NextRun)
+}
+
+func summaryNames(summaries []orchestrator.Summary) []string {
+	names := make([]string, len(summaries))
+	for i, s := range summaries {
+		names[i] = s.Name
+	}
+
+	return names
+}
+
+func TestRunner_RunNow(t *testing.T) {
+	t.Parallel()
+
+	var (
+		errCh = make(chan error)
+		runs  atomic.Int32
+		ran   = make(chan struct{}, 8)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	runner := orchestrator.Run(ctx, errCh, orchestrator.Spec{
+		Name:         "job",
+		Interval:     time.Hour,
+		InitialDelay: time.Hour,
+		Job: func(context.Context) error {
+			runs.Add(1)
+			ran <- struct{}{}
+
+			return nil
+		},
+	})
+
+	assert.False(t, runner.RunNow("unknown"))
+	assert.True(t, runner.RunNow("job"))
+	<-ran
+	assert.True(t, runner.RunNow("job"))
+	<-ran
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+func TestRun_Policy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		errs   []error
+		policy orchestrator.Policy
+		// runs is how many times the job runs before it is disabled or waits for its next interval.
+		runs     int32
+		minDur   time.Duration
+		category orchestrator.Category
+	}{
+		{
+			name:   "Retries transient failures with backoff",
+			errs:   []error{errMockedFailure, reddit.NewUnexpectedStatusError("GET", "/r/golang", 503), nil},
+			policy: orchestrator.Policy{Retries: 2, Backoff: 10 * time.Millisecond, DisableAfter: 1},
+			runs:   3,
+			minDur: 30 * time.Millisecond,
+		},
+		{
+			name:   "Waits for rate limit to reset",
+			errs:   []error{reddit.NewRateLimitExceededError(30 * time.Millisecond), nil},
+			policy: orchestrator.Policy{DisableAfter: 1},
+			runs:   2,
+			minDur: 30 * time.Millisecond,
+		},
+		{
+			name:   "Waits at least the backoff for a rate limit",
+			errs:   []error{reddit.NewRateLimitExceededError(time.Millisecond), nil},
+			policy: orchestrator.Policy{Backoff: 30 * time.Millisecond, DisableAfter: 1},
+			runs:   2,
+			minDur: 30 * time.Millisecond,
+		},
+		{
+			name:   "Disables after consecutive failed runs without retrying fatal errors",
+			errs:   []error{reddit.NewUnexpectedStatusError("GET", "/r/private", 403)},
+			policy: orchestrator.Policy{Retries: 5, DisableAfter: 3},
+			runs:   3,
+		},
+		{
+			name:   "Keeps running while runs are rejected",
+			errs:   []error{rejectedError{}, rejectedError{}, nil},
+			policy: orchestrator.Policy{Retries: 5, DisableAfter: 1},
+			runs:   3,
+		},
+		{
+			name:     "Escalates",
+			errs:     []error{reddit.NewNotAuthenticatedError()},
+			policy:   orchestrator.Policy{Retries: 5, Escalate: []orchestrator.Category{orchestrator.CategoryAuth}},
+			runs:     1,
+			category: orchestrator.CategoryAuth,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				errCh = make(chan error)
+				runs  atomic.Int32
+				start = time.Now()
+				done  = make(chan time.Duration, 1)
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			orchestrator.Run(ctx, errCh, orchestrator.Spec{
+				Name:     "job",
+				Interval: time.Millisecond,
+				Policy:   tt.policy,
+				Job: func(context.Context) error {
+					n := runs.Add(1)
+					if n == tt.runs {
+						done <- time.Since(start)
+					}
+
+					err := tt.errs[min(int(n), len(tt.errs))-1]
+					if err == nil {
+						// Stop the job so no further runs are counted.
+						cancel()
+					}
+
+					return err
+				},
+			})
+
+			if tt.category != "" {
+				var jobErr *orchestrator.JobError
+				require.ErrorAs(t, <-errCh, &jobErr)
+				assert.Equal(t, tt.category, jobErr.Category)
+				assert.Equal(t, "job", jobErr.Job)
+				cancel()
+			}
+
+			assert.GreaterOrEqual(t, <-done, tt.minDur)
+
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, tt.runs, runs.Load())
+		})
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	t.Parallel()
+
+	recorder := metricsmocks.NewRecorder(t)
+	recorder.On("ObserveJob", "top-posts:golang", mock.AnythingOfType("time.Duration"), nil).Return().Once()
+	recorder.On("ObserveJob", "top-posts:golang", mock.AnythingOfType("time.Duration"), errMockedFailure).Return().Once()
+
+	results := []error{nil, errMockedFailure}
+	job := orchestrator.Instrument("top-posts:golang", recorder, func(context.Context) error {
+		err := results[0]
+		results = results[1:]
+
+		return err
+	})
+
+	assert.NoError(t, job(context.Background()))
+	assert.ErrorIs(t, job(context.Background()), errMockedFailure)
+}
+
+// untimed clears the times of each summary, which vary between runs.
+func untimed(summaries []orchestrator.Summary) []orchestrator.Summary {
+	for i := range summaries {
+		summaries[i].LastStart, summaries[i].LastDuration, summaries[i].NextRun = time.Time{}, 0, time.Time{}
+	}
+
+	return summaries
+}
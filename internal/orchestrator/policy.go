@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/reddit"
+)
+
+// Category groups job errors by how they should be handled.
+type Category string
+
+const (
+	// CategoryTransient errors, such as network failures and 5xx responses, may succeed on retry.
+	CategoryTransient Category = "transient"
+	// CategoryRateLimited errors succeed once the rate limit resets.
+	CategoryRateLimited Category = "rate-limited"
+	// CategoryAuth errors mean the client's credentials were rejected.
+	CategoryAuth Category = "auth"
+	// CategoryFatal errors, such as a missing input or a forbidden subreddit, fail again on retry.
+	CategoryFatal Category = "fatal"
+)
+
+// maxBackoff caps the exponential backoff between retries and the wait for a rate limit to reset.
+const maxBackoff = 5 * time.Minute
+
+// Categories lists the error categories.
+func Categories() []Category {
+	return []Category{CategoryTransient, CategoryRateLimited, CategoryAuth, CategoryFatal}
+}
+
+// Rejecter is implemented by errors of runs turned away before doing any work, such as requests
+// rejected by an open circuit breaker.
+type Rejecter interface {
+	Rejected() bool
+}
+
+// rejected reports whether the run was turned away without doing any work.
+func rejected(err error) bool {
+	var rejecter Rejecter
+
+	return errors.As(err, &rejecter) && rejecter.Rejected()
+}
+
+// Classify categorizes an error returned by a job. Errors not returned by the reddit client, such
+// as network failures, are assumed to be transient. A rejected run is fatal, as retrying at once,
+// e.g. before an open circuit cools down, only rejects it again.
+func Classify(err error) Category {
+	var (
+		rateLimited *reddit.RateLimitExceededError
+		unauth      *reddit.NotAuthenticatedError
+		status      *reddit.UnexpectedStatusError
+		missing     *reddit.MissingInputError
+		uninit      *reddit.NotInitializedError
+	)
+
+	switch {
+	case rejected(err):
+		return CategoryFatal
+	case errors.As(err, &rateLimited):
+		return CategoryRateLimited
+	case errors.As(err, &unauth):
+		return CategoryAuth
+	case errors.As(err, &status):
+		switch {
+		case status.Status == http.StatusUnauthorized:
+			return CategoryAuth
+		case status.Status == http.StatusTooManyRequests:
+			return CategoryRateLimited
+		case status.Status == http.StatusRequestTimeout || status.Status >= http.StatusInternalServerError:
+			return CategoryTransient
+		default:
+			return CategoryFatal
+		}
+	case errors.As(err, &missing), errors.As(err, &uninit):
+		return CategoryFatal
+	default:
+		return CategoryTransient
+	}
+}
+
+// Policy decides how a job's failures are handled. Rate limited runs wait for the limit to reset
+// and run again, transient failures are retried, and a run that still fails counts towards
+// disabling the job. The zero Policy only logs failures.
+type Policy struct {
+	// Retries is how many times a run failing with a transient error is retried before it fails.
+	Retries int
+	// Backoff is the wait before the first retry, doubling for each retry after it. It is also the
+	// wait when a rate limit does not say when it resets.
+	Backoff time.Duration
+	// DisableAfter stops running the job after that many consecutive failed runs; zero never does.
+	DisableAfter int
+	// Escalate lists the categories of errors sent on the error channel, which usually shuts the
+	// process down. They are neither retried nor waited out.
+	Escalate []Category
+}
+
+func (p Policy) escalates(category Category) bool {
+	return slices.Contains(p.Escalate, category)
+}
+
+// backoff returns the wait before the given retry, counting from zero.
+func (p Policy) backoff(retry int) time.Duration {
+	d := p.Backoff
+	for range retry {
+		if d >= maxBackoff {
+			break
+		}
+
+		d *= 2
+	}
+
+	return min(d, maxBackoff)
+}
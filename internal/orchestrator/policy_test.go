@@ -0,0 +1,64 @@
+package orchestrator_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/orchestrator"
+	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want orchestrator.Category
+	}{
+		{name: "Rate limit", err: reddit.NewRateLimitExceededError(time.Minute), want: orchestrator.CategoryRateLimited},
+		{
+			name: "Wrapped rate limit",
+			err:  fmt.Errorf("fetch listing: %w", reddit.NewRateLimitExceededError(time.Minute)),
+			want: orchestrator.CategoryRateLimited,
+		},
+		{name: "Not authenticated", err: reddit.NewNotAuthenticatedError(), want: orchestrator.CategoryAuth},
+		{
+			name: "Unauthorized",
+			err:  reddit.NewUnexpectedStatusError(http.MethodGet, "/r/golang", http.StatusUnauthorized),
+			want: orchestrator.CategoryAuth,
+		},
+		{
+			name: "Server error",
+			err:  reddit.NewUnexpectedStatusError(http.MethodGet, "/r/golang", http.StatusBadGateway),
+			want: orchestrator.CategoryTransient,
+		},
+		{
+			name: "Forbidden",
+			err:  reddit.NewUnexpectedStatusError(http.MethodGet, "/r/private", http.StatusForbidden),
+			want: orchestrator.CategoryFatal,
+		},
+		{name: "Missing input", err: reddit.NewMissingInputError("username"), want: orchestrator.CategoryFatal},
+		{name: "Not initialized", err: reddit.NewNotInitializedError(), want: orchestrator.CategoryFatal},
+		{name: "Rejected", err: fmt.Errorf("fetch: %w", rejectedError{}), want: orchestrator.CategoryFatal},
+		{name: "Unknown", err: context.DeadlineExceeded, want: orchestrator.CategoryTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, orchestrator.Classify(tt.err))
+		})
+	}
+}
+
+// rejectedError stands in for errors of runs turned away, such as by an open circuit breaker.
+type rejectedError struct{}
+
+func (rejectedError) Error() string { return "rejected" }
+
+func (rejectedError) Rejected() bool { return true }
@@ -0,0 +1,27 @@
+package pipeline
+
+// DroppedError is returned when an item is submitted to a full stage whose policy drops it.
+type DroppedError struct {
+	Stage string
+}
+
+func (e *DroppedError) Error() string {
+	return "queue full, item dropped: " + e.Stage
+}
+
+func NewDroppedError(stage string) *DroppedError {
+	return &DroppedError{Stage: stage}
+}
+
+// ClosedError is returned when an item is submitted to a stage which has been closed.
+type ClosedError struct {
+	Stage string
+}
+
+func (e *ClosedError) Error() string {
+	return "stage closed: " + e.Stage
+}
+
+func NewClosedError(stage string) *ClosedError {
+	return &ClosedError{Stage: stage}
+}
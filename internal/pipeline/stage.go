@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/jqdurham/reddit/internal/metrics"
+)
+
+// Policy decides what happens to an item submitted while a stage's queue is full.
+type Policy string
+
+const (
+	// PolicyBlock makes the producer wait for room, slowing it down to the pace of the stage.
+	PolicyBlock Policy = "block"
+	// PolicyDrop discards the item so the producer never waits.
+	PolicyDrop Policy = "drop"
+)
+
+// Policies lists every Policy.
+func Policies() []Policy {
+	return []Policy{PolicyBlock, PolicyDrop}
+}
+
+// Config sizes a stage.
+type Config struct {
+	// Workers handle items concurrently; at least one is started.
+	Workers int
+	// Queue bounds how many items may wait for a worker.
+	Queue  int
+	Policy Policy
+}
+
+// Stage is a bounded queue of items handled by a pool of workers. Stages are chained by handlers
+// submitting their results to the next stage, so a slow stage pushes back on those feeding it or
+// drops their items, as its policy decides.
+type Stage[T any] struct {
+	name    string
+	cfg     Config
+	handle  func(ctx context.Context, item T)
+	key     func(item T) string
+	metrics metrics.Recorder
+
+	// mu guards closing the queues against concurrent submits. Submitters waiting for room hold it
+	// for reading, so closing first closes done to turn them away.
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+	queues    []chan T
+	wg        sync.WaitGroup
+}
+
+// Option customizes a Stage.
+type Option[T any] func(s *Stage[T])
+
+// WithKey partitions items between workers by key, so items with the same key are handled one at
+// a time in the order submitted. The queue is split evenly between the workers.
+func WithKey[T any](key func(item T) string) Option[T] {
+	return func(s *Stage[T]) {
+		s.key = key
+	}
+}
+
+// WithMetrics records the stage's configuration, queue depth and dropped items.
+func WithMetrics[T any](recorder metrics.Recorder) Option[T] {
+	return func(s *Stage[T]) {
+		s.metrics = recorder
+	}
+}
+
+// NewStage creates a Stage calling handle for each item submitted once started.
+func NewStage[T any](name string, cfg Config, handle func(ctx context.Context, item T), opts ...Option[T]) *Stage[T] {
+	s := &Stage[T]{name: name, cfg: cfg, handle: handle, metrics: metrics.Nop{}, done: make(chan struct{})}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.cfg.Workers = max(s.cfg.Workers, 1)
+	s.cfg.Queue = max(s.cfg.Queue, 0)
+
+	queues, size := 1, s.cfg.Queue
+	if s.key != nil {
+		queues, size = s.cfg.Workers, (s.cfg.Queue+s.cfg.Workers-1)/s.cfg.Workers
+	}
+
+	s.queues = make([]chan T, queues)
+	for i := range s.queues {
+		s.queues[i] = make(chan T, size)
+	}
+
+	s.metrics.SetStage(name, string(s.cfg.Policy), s.cfg.Workers, size*queues)
+
+	return s
+}
+
+// Start starts the workers, which handle items with ctx until the stage is closed.
+func (s *Stage[T]) Start(ctx context.Context) {
+	for i := range s.cfg.Workers {
+		queue := s.queues[i%len(s.queues)]
+
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+
+			for item := range queue {
+				s.metrics.SetQueueDepth(s.name, s.depth())
+				s.handle(ctx, item)
+			}
+		}()
+	}
+}
+
+// Submit queues an item, waiting for room unless the stage drops items while full. It fails when
+// the item is dropped, the stage is closed, even while waiting, or ctx ends while waiting.
+func (s *Stage[T]) Submit(ctx context.Context, item T) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return NewClosedError(s.name)
+	}
+
+	queue := s.queues[0]
+	if s.key != nil {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(s.key(item)))
+		queue = s.queues[h.Sum32()%uint32(len(s.queues))]
+	}
+
+	defer func() {
+		s.metrics.SetQueueDepth(s.name, s.depth())
+	}()
+
+	if s.cfg.Policy == PolicyDrop {
+		select {
+		case queue <- item:
+			return nil
+		default:
+			s.metrics.IncDropped(s.name)
+
+			return NewDroppedError(s.name)
+		}
+	}
+
+	select {
+	case queue <- item:
+		return nil
+	case <-s.done:
+		return NewClosedError(s.name)
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // the caller's own context
+	}
+}
+
+// Close stops accepting items, turning away submitters still waiting for room, and waits for the
+// workers to handle those already queued. Stages should be closed in order, after every stage
+// feeding them.
+func (s *Stage[T]) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+
+		for _, queue := range s.queues {
+			close(queue)
+		}
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Stage[T]) depth() int {
+	depth := 0
+	for _, queue := range s.queues {
+		depth += len(queue)
+	}
+
+	return depth
+}
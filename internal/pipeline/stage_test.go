@@ -0,0 +1,129 @@
+package pipeline_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/metrics/mocks"
+	"github.com/jqdurham/reddit/internal/pipeline"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStage_Keyed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		handled = map[string][]string{}
+	)
+
+	stage := pipeline.NewStage("aggregate", pipeline.Config{Workers: 3, Queue: 6, Policy: pipeline.PolicyBlock},
+		func(_ context.Context, item string) {
+			key, _, _ := strings.Cut(item, "/")
+
+			mu.Lock()
+			handled[key] = append(handled[key], item)
+			mu.Unlock()
+		},
+		pipeline.WithKey(func(item string) string {
+			key, _, _ := strings.Cut(item, "/")
+
+			return key
+		}))
+
+	stage.Start(context.Background())
+
+	want := map[string][]string{}
+
+	for i := range 20 {
+		for _, key := range []string{"golang", "rust", "python"} {
+			item := key + "/" + string(rune('a'+i))
+			want[key] = append(want[key], item)
+
+			require.NoError(t, stage.Submit(context.Background(), item))
+		}
+	}
+
+	stage.Close()
+
+	require.Equal(t, want, handled)
+
+	var closed *pipeline.ClosedError
+
+	require.ErrorAs(t, stage.Submit(context.Background(), "golang/z"), &closed)
+	require.EqualError(t, closed, "stage closed: aggregate")
+}
+
+func TestStage_Policy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Drop", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := mocks.NewRecorder(t)
+		recorder.On("SetStage", "report", "drop", 1, 1).Once()
+		recorder.On("SetQueueDepth", "report", mock.Anything)
+		recorder.On("IncDropped", "report").Once()
+
+		var handled []int
+
+		stage := pipeline.NewStage("report", pipeline.Config{Workers: 1, Queue: 1, Policy: pipeline.PolicyDrop},
+			func(_ context.Context, item int) { handled = append(handled, item) },
+			pipeline.WithMetrics[int](recorder))
+
+		require.NoError(t, stage.Submit(context.Background(), 1))
+
+		var dropped *pipeline.DroppedError
+
+		require.ErrorAs(t, stage.Submit(context.Background(), 2), &dropped)
+		require.EqualError(t, dropped, "queue full, item dropped: report")
+
+		stage.Start(context.Background())
+		stage.Close()
+
+		require.Equal(t, []int{1}, handled)
+		recorder.AssertCalled(t, "SetQueueDepth", "report", 1)
+		recorder.AssertCalled(t, "SetQueueDepth", "report", 0)
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		t.Parallel()
+
+		stage := pipeline.NewStage("parse", pipeline.Config{Workers: 1, Queue: 1, Policy: pipeline.PolicyBlock},
+			func(context.Context, int) {})
+
+		require.NoError(t, stage.Submit(context.Background(), 1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, stage.Submit(ctx, 2), context.Canceled)
+
+		stage.Start(context.Background())
+		stage.Close()
+	})
+
+	t.Run("Close turns away blocked submitters", func(t *testing.T) {
+		t.Parallel()
+
+		stage := pipeline.NewStage("report", pipeline.Config{Workers: 1, Queue: 1, Policy: pipeline.PolicyBlock},
+			func(context.Context, int) {})
+
+		require.NoError(t, stage.Submit(context.Background(), 1))
+
+		submitted := make(chan error)
+
+		go func() {
+			submitted <- stage.Submit(context.Background(), 2)
+		}()
+
+		stage.Close()
+
+		var closed *pipeline.ClosedError
+
+		require.ErrorAs(t, <-submitted, &closed)
+	})
+}
@@ -7,51 +7,110 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/metrics"
 )
 
 const (
 	unauthenticatedHost = "www.reddit.com"
 	authenticatedHost   = "oauth.reddit.com"
+	// tokenRefreshMargin is how long before expiring the bearer token is replaced.
+	tokenRefreshMargin = 5 * time.Minute
 )
 
 // Client provides a mechanism to interact with Reddit's API.
 type Client struct {
 	clientID, secret string
-	token            string
 	httpClient       *http.Client
 	limiter          Waiter
+	metrics          metrics.Recorder
+	now              func() time.Time
+
+	// mu guards the bearer token and the credentials used to refresh it. It is not held while
+	// logging in, refreshMu lets a single request refresh the token while the others wait.
+	mu                 sync.Mutex
+	refreshMu          sync.Mutex
+	token              string
+	expiresAt          time.Time
+	username, password string
+
+	rateMu sync.Mutex
+	rate   RateStatus
+}
+
+// Option customizes a Client.
+type Option func(c *Client)
+
+// WithMetrics records requests, rate limit status and logins.
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(c *Client) {
+		c.metrics = recorder
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.now = now
+	}
 }
 
 // NewClient creates and prepares a Client for interactions with Reddit's API.
-func NewClient(clientID, secret string, httpClient *http.Client, limiter Waiter) *Client {
-	return &Client{
+func NewClient(clientID, secret string, httpClient *http.Client, limiter Waiter, opts ...Option) *Client {
+	c := &Client{
 		clientID:   clientID,
 		secret:     secret,
 		httpClient: httpClient,
 		limiter:    limiter,
+		metrics:    metrics.Nop{},
+		now:        time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
-// Login exchanges the provided credentials for a bearer token to be used with protected APIs.
+// Login exchanges the provided credentials for a bearer token to be used with protected APIs. The
+// credentials are retained so the token can be refreshed before it expires.
 func (c *Client) Login(ctx context.Context, username, password string) error {
+	token, expiresAt, err := c.login(ctx, username, password)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.token, c.expiresAt = token, expiresAt
+	c.username, c.password = username, password
+	c.mu.Unlock()
+
+	c.metrics.IncLogin()
+
+	return nil
+}
+
+// login fetches a bearer token and the time it expires, zero when it does not.
+func (c *Client) login(ctx context.Context, username, password string) (string, time.Time, error) {
 	uri := &url.URL{Scheme: "https", Host: unauthenticatedHost, Path: "/api/v1/access_token"}
 
 	req, err := c.prepareLoginRequest(ctx, uri, username, password)
 	if err != nil {
-		return err
+		return "", time.Time{}, err
 	}
 
 	res, err := c.send(ctx, req)
 	if err != nil {
-		return fmt.Errorf("fetch token: %w", err)
+		return "", time.Time{}, fmt.Errorf("fetch token: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return NewUnexpectedStatusError(http.MethodPost, uri.String(), res.StatusCode)
+		return "", time.Time{}, NewUnexpectedStatusError(http.MethodPost, uri.String(), res.StatusCode)
 	}
 
 	type accessTokenResponse struct {
@@ -64,12 +123,15 @@ func (c *Client) Login(ctx context.Context, username, password string) error {
 	response := &accessTokenResponse{}
 
 	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
-		return fmt.Errorf("token response decoding: %w", err)
+		return "", time.Time{}, fmt.Errorf("token response decoding: %w", err)
 	}
 
-	c.token = response.AccessToken
+	var expiresAt time.Time
+	if response.ExpiresIn > 0 {
+		expiresAt = c.now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
 
-	return nil
+	return response.AccessToken, expiresAt, nil
 }
 
 // FetchListing interacts with APIs that return listings.
@@ -112,6 +174,14 @@ func (c *Client) FetchAllListings(ctx context.Context, path string) ([]*Listing,
 	return out, nil
 }
 
+// RateStatus returns the rate limit status reported by the most recent listing response.
+func (c *Client) RateStatus() RateStatus {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rate
+}
+
 func (c *Client) uninitialized() bool {
 	return c.clientID == "" || c.secret == ""
 }
@@ -154,16 +224,56 @@ func (c *Client) prepareLoginRequest(ctx context.Context, uri *url.URL, username
 	return req, nil
 }
 
-func (c *Client) authenticated() error {
+// bearer returns the current token, logging in again when it is about to expire.
+func (c *Client) bearer(ctx context.Context) (string, error) {
+	token, stale, err := c.currentToken()
+	if err != nil || !stale {
+		return token, err
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	// Another request may have refreshed the token while this one waited.
+	token, stale, err = c.currentToken()
+	if err != nil || !stale {
+		return token, err
+	}
+
+	c.mu.Lock()
+	username, password := c.username, c.password
+	c.mu.Unlock()
+
+	token, expiresAt, err := c.login(ctx, username, password)
+	if err != nil {
+		return "", fmt.Errorf("refresh token: %w", err)
+	}
+
+	c.mu.Lock()
+	c.token, c.expiresAt = token, expiresAt
+	c.mu.Unlock()
+
+	c.metrics.IncTokenRefresh()
+	logger.FromContext(ctx).Debug("refreshed token", "expires", expiresAt)
+
+	return token, nil
+}
+
+// currentToken returns the bearer token and whether it is about to expire.
+func (c *Client) currentToken() (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.token == "" {
-		return NewNotAuthenticatedError()
+		return "", false, NewNotAuthenticatedError()
 	}
 
-	return nil
+	return c.token, !c.expiresAt.IsZero() && c.now().After(c.expiresAt.Add(-tokenRefreshMargin)), nil
 }
 
 func (c *Client) prepareAuthenticatedRequest(ctx context.Context, path string, page *Page) (*http.Request, error) {
-	if err := c.authenticated(); err != nil {
+	token, err := c.bearer(ctx)
+	if err != nil {
 		return nil, err
 	}
 
@@ -179,7 +289,7 @@ func (c *Client) prepareAuthenticatedRequest(ctx context.Context, path string, p
 		return nil, fmt.Errorf("create request: %s | %w", path, err)
 	}
 
-	req.Header = stdHeaders(withBearer(c.token))
+	req.Header = stdHeaders(withBearer(token))
 
 	return req, nil
 }
@@ -191,11 +301,21 @@ func (c *Client) send(ctx context.Context, r *http.Request) (*http.Response, err
 		}
 	}
 
+	start := time.Now()
+
 	res, err := c.httpClient.Do(r)
 	if err != nil {
+		c.metrics.ObserveRequest(route(r.URL.Path), 0, time.Since(start))
+
 		return nil, fmt.Errorf("send request: %w", err)
 	}
 
+	c.metrics.ObserveRequest(route(r.URL.Path), res.StatusCode, time.Since(start))
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		c.metrics.IncRateLimited()
+	}
+
 	return res, nil
 }
 
@@ -219,6 +339,11 @@ func (c *Client) fetchListing(ctx context.Context, path string, listing *Listing
 	}
 
 	logr.Debug("rate status", "used", rate.Used, "remaining", rate.Remaining, "reset", rate.Reset)
+	c.metrics.SetRateLimit(float64(rate.Remaining), float64(rate.Used), time.Duration(rate.Reset)*time.Second)
+
+	c.rateMu.Lock()
+	c.rate = *rate
+	c.rateMu.Unlock()
 
 	switch res.StatusCode {
 	case http.StatusOK:
@@ -233,3 +358,23 @@ func (c *Client) fetchListing(ctx context.Context, path string, listing *Listing
 		return NewUnexpectedStatusError(http.MethodGet, path, res.StatusCode)
 	}
 }
+
+// route returns the template of a path, replacing subreddit, user and post names with placeholders
+// so request metrics are labelled by a bounded set of endpoints.
+func route(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i := 0; i < len(segments)-1; i++ {
+		switch segments[i] {
+		case "r":
+			segments[i+1] = "{subreddit}"
+		case "u", "user":
+			segments[i+1] = "{user}"
+		case "comments":
+			// Anything after the post ID is its title slug and comment IDs.
+			segments = append(segments[:i+1], "{id}")
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
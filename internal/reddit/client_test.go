@@ -10,10 +10,15 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	metricsmocks "github.com/jqdurham/reddit/internal/metrics/mocks"
 	"github.com/jqdurham/reddit/internal/reddit"
 	"github.com/jqdurham/reddit/internal/reddit/mocks"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -366,6 +371,113 @@ func TestClient_Login(t *testing.T) {
 	}
 }
 
+func TestClient_Metrics(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		now    = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		logins int
+	)
+
+	httpClient := &http.Client{
+		Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+			switch r.URL.String() {
+			case loginURL:
+				logins++
+				res.Body = io.NopCloser(strings.NewReader(tokenJSON))
+			case listingURL:
+				res.Header.Set("X-Ratelimit-Remaining", "598.0")
+				res.Header.Set("X-Ratelimit-Used", "2")
+				res.Header.Set("X-Ratelimit-Reset", "120")
+				res.Body = io.NopCloser(strings.NewReader(firstListingJSON))
+			default:
+				res.StatusCode = http.StatusTooManyRequests
+				res.Header.Set("X-Ratelimit-Reset", "30")
+				res.Body = io.NopCloser(strings.NewReader("{}"))
+			}
+
+			return res, nil
+		}),
+	}
+
+	recorder := metricsmocks.NewRecorder(t)
+	recorder.On("IncLogin").Return().Once()
+	recorder.On("IncTokenRefresh").Return().Once()
+	recorder.On("IncRateLimited").Return().Once()
+	recorder.On("ObserveRequest", "/api/v1/access_token", http.StatusOK, mock.Anything).Return().Twice()
+	recorder.On("ObserveRequest", "/unit-test", http.StatusOK, mock.Anything).Return().Once()
+	// Requests are labelled by route rather than by path.
+	recorder.On("ObserveRequest", "/r/{subreddit}/comments/{id}", http.StatusTooManyRequests, mock.Anything).
+		Return().Once()
+	recorder.On("SetRateLimit", float64(598), float64(2), 2*time.Minute).Return().Once()
+	recorder.On("SetRateLimit", float64(0), float64(0), 30*time.Second).Return().Once()
+
+	c := reddit.NewClient("clientID", "secret", httpClient, nil, reddit.WithMetrics(recorder),
+		reddit.WithClock(func() time.Time { return now }))
+
+	require.NoError(t, c.Login(ctx, testUsername, testPassword))
+
+	_, err := c.FetchListing(ctx, "/unit-test")
+	require.NoError(t, err)
+	require.Equal(t, reddit.RateStatus{Remaining: 598, Used: 2, Reset: 120}, c.RateStatus())
+
+	// The token expires in a day, so it is refreshed shortly beforehand.
+	now = now.Add(24*time.Hour - time.Minute)
+
+	_, err = c.FetchListing(ctx, "/r/golang/comments/abc/range_over_func")
+	require.EqualError(t, err, "rate limit exceeded, resets in 30s")
+	require.Equal(t, 2, logins)
+}
+
+func TestClient_RefreshesTokenOnce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx    = context.Background()
+		now    = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		logins atomic.Int32
+	)
+
+	httpClient := &http.Client{
+		Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			body := firstListingJSON
+			if r.URL.String() == loginURL {
+				logins.Add(1)
+
+				body = tokenJSON
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+
+	c := reddit.NewClient("clientID", "secret", httpClient, nil, reddit.WithClock(func() time.Time { return now }))
+	require.NoError(t, c.Login(ctx, testUsername, testPassword))
+
+	// The token is about to expire, concurrent requests share a single refresh.
+	now = now.Add(24*time.Hour - time.Minute)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := c.FetchListing(ctx, "/unit-test")
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(2), logins.Load())
+}
+
 func makeListing(data string) *reddit.Listing {
 	listing := &reddit.Listing{}
 	_ = json.Unmarshal([]byte(data), listing)
@@ -10,12 +10,22 @@ type Segment struct {
 }
 
 type Children []struct {
-	Post Post `json:"data,omitempty"`
+	Kind string `json:"kind,omitempty"`
+	Post Post   `json:"data,omitempty"`
 }
 
+// Post holds the fields used from a listing child. Links (t3) populate Title and Selftext while
+// comments (t1) populate Body.
 type Post struct {
-	Name   string `json:"name"`
-	Title  string `json:"title"`
-	Ups    int    `json:"ups"`
-	Author string `json:"author"`
+	Name        string  `json:"name"`
+	Title       string  `json:"title"`
+	Ups         int     `json:"ups"`
+	Author      string  `json:"author"`
+	Selftext    string  `json:"selftext"`
+	Body        string  `json:"body"`
+	CreatedUTC  float64 `json:"created_utc"`
+	URL         string  `json:"url"`
+	IsSelf      bool    `json:"is_self"`
+	Permalink   string  `json:"permalink"`
+	NumComments int     `json:"num_comments"`
 }
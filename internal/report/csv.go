@@ -0,0 +1,50 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// CSV writes each report as a header record followed by one record per row. Every record is
+// prefixed with the report's generation time, kind and subreddit in the generated_at, report and
+// report_subreddit columns. Because reports have different columns, readers should allow a variable
+// number of fields per record.
+type CSV struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewCSV creates a CSV reporter.
+func NewCSV(w io.Writer) *CSV {
+	return &CSV{w: w}
+}
+
+// Report writes the report as CSV records.
+func (c *CSV) Report(r *Report) error {
+	var (
+		buf    = &bytes.Buffer{}
+		writer = csv.NewWriter(buf)
+		prefix = []string{r.GeneratedAt.Format(time.RFC3339), r.Kind, r.Subreddit}
+	)
+
+	records := [][]string{append([]string{"generated_at", "report", "report_subreddit"}, r.Columns...)}
+
+	for _, row := range r.Rows {
+		record := append([]string{}, prefix...)
+		for _, v := range row.Values() {
+			record = append(record, formatValue(v))
+		}
+
+		records = append(records, record)
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("encode csv: %w", err)
+	}
+
+	return write(&c.mu, c.w, buf.Bytes())
+}
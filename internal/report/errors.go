@@ -0,0 +1,16 @@
+package report
+
+import "strings"
+
+// UnsupportedFormatError is returned when a reporter is requested for an unknown format.
+type UnsupportedFormatError struct {
+	format string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	return "unsupported report format: " + e.format + " (must be: " + strings.Join(Formats(), ", ") + ")"
+}
+
+func NewUnsupportedFormatError(format string) *UnsupportedFormatError {
+	return &UnsupportedFormatError{format: format}
+}
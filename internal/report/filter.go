@@ -0,0 +1,23 @@
+package report
+
+import "slices"
+
+// Filter withholds reports of some kinds from another reporter.
+type Filter struct {
+	next    Reporter
+	exclude []string
+}
+
+// NewFilter creates a Filter delivering every report to next except those of the excluded kinds.
+func NewFilter(next Reporter, exclude ...string) *Filter {
+	return &Filter{next: next, exclude: exclude}
+}
+
+// Report delivers the report unless its kind is excluded.
+func (f *Filter) Report(r *Report) error {
+	if slices.Contains(f.exclude, r.Kind) {
+		return nil
+	}
+
+	return f.next.Report(r)
+}
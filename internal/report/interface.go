@@ -0,0 +1,8 @@
+package report
+
+// Reporter delivers reports to a destination in a particular format.
+//
+//go:generate mockery --name Reporter
+type Reporter interface {
+	Report(r *Report) error
+}
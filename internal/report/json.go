@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// JSON writes each report as an indented JSON document.
+type JSON struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSON creates a JSON reporter.
+func NewJSON(w io.Writer) *JSON {
+	return &JSON{w: w}
+}
+
+type jsonReport struct {
+	Kind        string   `json:"kind,omitempty"`
+	Title       string   `json:"title"`
+	Subreddit   string   `json:"subreddit,omitempty"`
+	GeneratedAt string   `json:"generated_at"`
+	Columns     []string `json:"columns"`
+	Rows        []object `json:"rows"`
+}
+
+// Report writes the report as a JSON document with one object per row.
+func (j *JSON) Report(r *Report) error {
+	out, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+
+	return write(&j.mu, j.w, append(out, '\n'))
+}
+
+// MarshalJSON encodes the report as a document with one object per row, keyed by column.
+func (r *Report) MarshalJSON() ([]byte, error) {
+	doc := jsonReport{
+		Kind:        r.Kind,
+		Title:       r.Title,
+		Subreddit:   r.Subreddit,
+		GeneratedAt: r.GeneratedAt.Format(time.RFC3339),
+		Columns:     r.Columns,
+		Rows:        make([]object, len(r.Rows)),
+	}
+
+	for i, row := range r.Rows {
+		doc.Rows[i] = newObject(r.Columns, row.Values())
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal report: %w", err)
+	}
+
+	return out, nil
+}
+
+// NDJSON writes one JSON object per row, each tagged with its report's kind, subreddit and
+// generation time.
+type NDJSON struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewNDJSON creates an NDJSON reporter.
+func NewNDJSON(w io.Writer) *NDJSON {
+	return &NDJSON{w: w}
+}
+
+type ndjsonRow struct {
+	Report      string `json:"report"`
+	Subreddit   string `json:"subreddit,omitempty"`
+	GeneratedAt string `json:"generated_at"`
+	Row         object `json:"row"`
+}
+
+// Report writes each row of the report on its own line.
+func (n *NDJSON) Report(r *Report) error {
+	buf := &bytes.Buffer{}
+
+	for _, row := range r.Rows {
+		out, err := json.Marshal(ndjsonRow{
+			Report:      r.Kind,
+			Subreddit:   r.Subreddit,
+			GeneratedAt: r.GeneratedAt.Format(time.RFC3339),
+			Row:         newObject(r.Columns, row.Values()),
+		})
+		if err != nil {
+			return fmt.Errorf("marshal row: %w", err)
+		}
+
+		buf.Write(out)
+		buf.WriteByte('\n')
+	}
+
+	return write(&n.mu, n.w, buf.Bytes())
+}
+
+// object is a JSON object that preserves the order of its keys.
+type object struct {
+	keys   []string
+	values []any
+}
+
+func newObject(keys []string, values []any) object {
+	return object{keys: keys, values: values}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, fmt.Errorf("marshal key: %w", err)
+		}
+
+		var value any
+		if i < len(o.values) {
+			value = o.values[i]
+		}
+
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", key, err)
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
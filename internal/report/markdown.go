@@ -0,0 +1,225 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MarkdownLimit is the most characters Reddit accepts in a self post body.
+	MarkdownLimit = 40000
+	redditURL     = "https://www.reddit.com"
+	deletedAuthor = "[deleted]"
+	// truncationReserve keeps room for the note explaining that content was omitted.
+	truncationReserve = 120
+)
+
+// markdownEscaper escapes characters with a meaning in Reddit-flavored Markdown or tables.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `|`, `\|`, `*`, `\*`, `_`, `\_`, `~`, `\~`, `^`, `\^`, "`", "\\`", `[`, `\[`, `]`, `\]`,
+	`>`, `\>`, "\r\n", " ", "\n", " ",
+)
+
+// Markdown writes each report as a Reddit-flavored Markdown table, truncated to MarkdownLimit so it
+// can be posted as is.
+type Markdown struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewMarkdown creates a Markdown reporter.
+func NewMarkdown(w io.Writer) *Markdown {
+	return &Markdown{w: w}
+}
+
+// Report writes the report as a Markdown section.
+func (m *Markdown) Report(r *Report) error {
+	b := &markdownBuilder{limit: MarkdownLimit}
+	b.section(r)
+
+	return write(&m.mu, m.w, []byte(b.String()))
+}
+
+// MarkdownDocument renders reports as a titled Markdown document of at most limit characters, e.g.
+// a digest file or the body of a self post. Title cells link to the row's permalink column, which
+// is not shown itself, and author and subreddit cells link to the user or subreddit. When the
+// reports do not fit, trailing rows and then whole reports are omitted, and a note says so.
+func MarkdownDocument(title string, reports []*Report, limit int) string {
+	b := &markdownBuilder{limit: limit}
+
+	generated := time.Time{}
+	for _, r := range reports {
+		if r.GeneratedAt.After(generated) {
+			generated = r.GeneratedAt
+		}
+	}
+
+	b.add("# " + markdownEscaper.Replace(title) + "\n\n")
+
+	if !generated.IsZero() {
+		b.add("*Generated " + generated.UTC().Format(time.DateTime) + " UTC*\n\n")
+	}
+
+	for i, r := range reports {
+		if !b.section(r) {
+			b.note(fmt.Sprintf("*%d more reports omitted to fit Reddit's %d character limit.*\n", len(reports)-i, limit))
+
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// markdownBuilder accumulates Markdown while counting characters against a limit.
+type markdownBuilder struct {
+	strings.Builder
+	size, limit int
+}
+
+// fits reports whether s can be added while keeping room for a truncation note.
+func (b *markdownBuilder) fits(s string) bool {
+	return b.size+utf8.RuneCountInString(s)+truncationReserve <= b.limit
+}
+
+func (b *markdownBuilder) add(s string) {
+	b.WriteString(s)
+	b.size += utf8.RuneCountInString(s)
+}
+
+// note adds a truncation note, using the room kept for it.
+func (b *markdownBuilder) note(s string) {
+	if b.size+utf8.RuneCountInString(s) <= b.limit {
+		b.add(s)
+	}
+}
+
+// section adds the report's heading and as many of its rows as fit, returning false when not even
+// the heading and table header fit.
+func (b *markdownBuilder) section(r *Report) bool {
+	var (
+		permalink = slices.Index(r.Columns, "permalink")
+		linked    = slices.IndexFunc(r.Columns, func(col string) bool { return col == "title" || col == "text" })
+		columns   = make([]int, 0, len(r.Columns))
+	)
+
+	for i := range r.Columns {
+		if i != permalink {
+			columns = append(columns, i)
+		}
+	}
+
+	heading := "## " + markdownEscaper.Replace(r.Title)
+	if r.Subreddit != "" {
+		heading += " (" + subredditLink(r.Subreddit) + ")"
+	}
+
+	header := &strings.Builder{}
+	header.WriteString(heading + "\n\n")
+
+	if len(r.Rows) == 0 {
+		header.WriteString("*No results.*\n\n")
+
+		if !b.fits(header.String()) {
+			return false
+		}
+
+		b.add(header.String())
+
+		return true
+	}
+
+	values := r.Rows[0].Values()
+
+	for _, i := range columns {
+		header.WriteString("| " + markdownEscaper.Replace(strings.ReplaceAll(r.Columns[i], "_", " ")) + " ")
+	}
+
+	header.WriteString("|\n")
+
+	for _, i := range columns {
+		header.WriteString("|" + alignment(at(values, i)))
+	}
+
+	header.WriteString("|\n")
+
+	if !b.fits(header.String()) {
+		return false
+	}
+
+	b.add(header.String())
+
+	for n, row := range r.Rows {
+		values := row.Values()
+
+		line := &strings.Builder{}
+		for _, i := range columns {
+			cell := markdownCell(r.Columns[i], at(values, i))
+			if i == linked && permalink >= 0 {
+				if link, ok := at(values, permalink).(string); ok && link != "" {
+					cell = "[" + cell + "](" + redditURL + link + ")"
+				}
+			}
+
+			line.WriteString("| " + cell + " ")
+		}
+
+		line.WriteString("|\n")
+
+		if !b.fits(line.String()) {
+			b.note(fmt.Sprintf("\n*%d more rows omitted.*\n", len(r.Rows)-n))
+
+			break
+		}
+
+		b.add(line.String())
+	}
+
+	b.add("\n")
+
+	return true
+}
+
+func markdownCell(column string, v any) string {
+	s, ok := v.(string)
+	if !ok {
+		return markdownEscaper.Replace(formatValue(v))
+	}
+
+	switch {
+	case column == "author" && s != "" && s != deletedAuthor:
+		return "[u/" + markdownEscaper.Replace(s) + "](" + redditURL + "/user/" + url.PathEscape(s) + "/)"
+	case column == "subreddit" && s != "":
+		return subredditLink(s)
+	default:
+		return markdownEscaper.Replace(s)
+	}
+}
+
+func subredditLink(name string) string {
+	return "[r/" + markdownEscaper.Replace(name) + "](" + redditURL + "/r/" + url.PathEscape(name) + "/)"
+}
+
+// alignment right-aligns numeric columns.
+func alignment(v any) string {
+	switch v.(type) {
+	case int, int64, float64:
+		return "--:"
+	default:
+		return ":--"
+	}
+}
+
+func at(values []any, i int) any {
+	if i >= 0 && i < len(values) {
+		return values[i]
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package report_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/stretchr/testify/require"
+)
+
+type linkedRow struct {
+	ups                      int
+	title, author, permalink string
+}
+
+func (r linkedRow) String() string {
+	return fmt.Sprintf("(%d) - %s \n", r.ups, r.title)
+}
+
+func (r linkedRow) Values() []any {
+	return []any{r.ups, r.title, r.author, r.permalink}
+}
+
+func linkedReport(rows int) *report.Report {
+	rep := &report.Report{
+		Kind:        "top-posts",
+		Title:       "Top Posts",
+		Subreddit:   "golang",
+		Columns:     []string{"ups", "title", "author", "permalink"},
+		GeneratedAt: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	for i := range rows {
+		rep.Rows = append(rep.Rows, linkedRow{
+			ups:       1000 - i,
+			title:     fmt.Sprintf("Post *%d* | [draft]", i),
+			author:    "go_pher",
+			permalink: fmt.Sprintf("/r/golang/comments/%d/post/", i),
+		})
+	}
+
+	return rep
+}
+
+func TestMarkdownDocument(t *testing.T) {
+	t.Parallel()
+
+	authors := &report.Report{
+		Title:     "Top 1 Authors",
+		Subreddit: "golang",
+		Columns:   []string{"posts", "author"},
+		Rows:      []report.Row{testRow{ups: 2, title: "[deleted]"}},
+	}
+
+	require.Equal(t, "# Weekly Digest\n\n"+
+		"*Generated 2024-04-01 12:00:00 UTC*\n\n"+
+		"## Top Posts ([r/golang](https://www.reddit.com/r/golang/))\n\n"+
+		"| ups | title | author |\n"+
+		"|--:|:--|:--|\n"+
+		"| 1000 | [Post \\*0\\* \\| \\[draft\\]](https://www.reddit.com/r/golang/comments/0/post/) | "+
+		"[u/go\\_pher](https://www.reddit.com/user/go_pher/) |\n\n"+
+		"## Top 1 Authors ([r/golang](https://www.reddit.com/r/golang/))\n\n"+
+		"| posts | author |\n"+
+		"|--:|:--|\n"+
+		"| 2 | \\[deleted\\] |\n\n",
+		report.MarkdownDocument("Weekly Digest", []*report.Report{linkedReport(1), authors}, report.MarkdownLimit))
+}
+
+func TestMarkdownDocument_Truncates(t *testing.T) {
+	t.Parallel()
+
+	reports := []*report.Report{linkedReport(500), linkedReport(1), linkedReport(1)}
+
+	doc := report.MarkdownDocument("Weekly Digest", reports, report.MarkdownLimit)
+
+	require.LessOrEqual(t, utf8.RuneCountInString(doc), report.MarkdownLimit)
+	require.Contains(t, doc, "| 1000 | [Post \\*0\\*")
+	require.Regexp(t, `\n\*\d+ more rows omitted\.\*\n\n\*2 more reports omitted to fit Reddit's 40000 character limit\.\*\n$`, doc)
+	require.Equal(t, 1, strings.Count(doc, "## Top Posts"))
+}
@@ -0,0 +1,26 @@
+package report
+
+import "errors"
+
+// Multi delivers each report to several reporters.
+type Multi struct {
+	reporters []Reporter
+}
+
+// NewMulti creates a Multi reporter. Every reporter receives each report even when another fails.
+func NewMulti(reporters ...Reporter) *Multi {
+	return &Multi{reporters: reporters}
+}
+
+// Report delivers the report to every reporter, joining their errors.
+func (m *Multi) Report(r *Report) error {
+	errs := make([]error, 0, len(m.reporters))
+
+	for _, reporter := range m.reporters {
+		if err := reporter.Report(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
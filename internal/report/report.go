@@ -0,0 +1,110 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported output formats.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatCSV      = "csv"
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the output formats accepted by New.
+func Formats() []string {
+	return []string{FormatText, FormatJSON, FormatNDJSON, FormatCSV, FormatTable, FormatMarkdown}
+}
+
+// Report kinds identify each statistic reported by the post service, e.g. for subscribers filtering
+// live updates.
+const (
+	KindTopPosts            = "top-posts"
+	KindTopAuthors          = "top-authors"
+	KindTopPostsChanges     = "top-posts-changes"
+	KindTopAuthorsChanges   = "top-authors-changes"
+	KindAuthorOverlap       = "author-overlap"
+	KindSubredditSimilarity = "subreddit-similarity"
+	KindMood                = "mood"
+	KindPositivePosts       = "positive-posts"
+	KindNegativePosts       = "negative-posts"
+	KindDomains             = "domains"
+	KindActivityPosts       = "activity-posts"
+	KindActivityComments    = "activity-comments"
+	KindActivityScores      = "activity-scores"
+	KindRecentRemovals      = "recent-removals"
+	KindRemovalRates        = "removal-rates"
+	KindAuthorRemovalRates  = "author-removal-rates"
+	KindRuleMatches         = "rule-matches"
+)
+
+// Row is a single entry of a report. Values align with the report's Columns while String renders
+// the row as a line of text.
+type Row interface {
+	fmt.Stringer
+	Values() []any
+}
+
+// Report is a titled set of rows produced by a statistic update. Kind is a stable identifier of
+// the statistic, unlike Title which may embed parameters. Subreddit is empty for reports spanning
+// every tracked subreddit.
+type Report struct {
+	Kind        string
+	Title       string
+	Subreddit   string
+	Columns     []string
+	Rows        []Row
+	GeneratedAt time.Time
+}
+
+// Heading returns the title, qualified with the subreddit when present.
+func (r *Report) Heading() string {
+	if r.Subreddit == "" {
+		return r.Title
+	}
+
+	return fmt.Sprintf("%s (%s)", r.Title, r.Subreddit)
+}
+
+// New creates a Reporter writing the provided format to w.
+//
+//nolint:ireturn // callers select the implementation by configuration.
+func New(format string, w io.Writer) (Reporter, error) {
+	switch strings.ToLower(format) {
+	case FormatText:
+		return NewText(w), nil
+	case FormatJSON:
+		return NewJSON(w), nil
+	case FormatNDJSON:
+		return NewNDJSON(w), nil
+	case FormatCSV:
+		return NewCSV(w), nil
+	case FormatTable:
+		return NewTable(w), nil
+	case FormatMarkdown:
+		return NewMarkdown(w), nil
+	}
+
+	return nil, NewUnsupportedFormatError(format)
+}
+
+// formatValue renders a row value for text-based formats.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case []string:
+		return strings.Join(val, ";")
+	default:
+		return fmt.Sprint(val)
+	}
+}
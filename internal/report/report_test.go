@@ -0,0 +1,180 @@
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/report/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var errMockedFailure = errors.New("mocked failure")
+
+type testRow struct {
+	ups   int
+	title string
+	tags  []string
+}
+
+func (r testRow) String() string {
+	return fmt.Sprintf("(%d) - %s \n", r.ups, r.title)
+}
+
+func (r testRow) Values() []any {
+	return []any{r.ups, r.title, r.tags}
+}
+
+func testReport() *report.Report {
+	return &report.Report{
+		Kind:      "top-posts",
+		Title:     "Top Posts",
+		Subreddit: "golang",
+		Columns:   []string{"ups", "title", "tags"},
+		Rows: []report.Row{
+			testRow{ups: 1337, title: "Go 1.22 released", tags: []string{"release"}},
+			testRow{ups: 42, title: `Generics, "finally"`},
+		},
+		GeneratedAt: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		want   string
+		errMsg string
+	}{
+		{
+			format: "text",
+			want: "\n" +
+				"Top Posts (golang)\n" +
+				"--------------------------------------------------------------------------------\n" +
+				"(1337) - Go 1.22 released \n" +
+				"(42) - Generics, \"finally\" \n\n",
+		},
+		{
+			format: "JSON",
+			want: `{
+  "kind": "top-posts",
+  "title": "Top Posts",
+  "subreddit": "golang",
+  "generated_at": "2024-04-01T12:00:00Z",
+  "columns": [
+    "ups",
+    "title",
+    "tags"
+  ],
+  "rows": [
+    {
+      "ups": 1337,
+      "title": "Go 1.22 released",
+      "tags": [
+        "release"
+      ]
+    },
+    {
+      "ups": 42,
+      "title": "Generics, \"finally\"",
+      "tags": null
+    }
+  ]
+}
+`,
+		},
+		{
+			format: "ndjson",
+			want: `{"report":"top-posts","subreddit":"golang","generated_at":"2024-04-01T12:00:00Z",` +
+				`"row":{"ups":1337,"title":"Go 1.22 released","tags":["release"]}}` + "\n" +
+				`{"report":"top-posts","subreddit":"golang","generated_at":"2024-04-01T12:00:00Z",` +
+				`"row":{"ups":42,"title":"Generics, \"finally\"","tags":null}}` + "\n",
+		},
+		{
+			format: "csv",
+			want: "generated_at,report,report_subreddit,ups,title,tags\n" +
+				"2024-04-01T12:00:00Z,top-posts,golang,1337,Go 1.22 released,release\n" +
+				"2024-04-01T12:00:00Z,top-posts,golang,42,\"Generics, \"\"finally\"\"\",\n",
+		},
+		{
+			format: "table",
+			want: "\n" +
+				"Top Posts (golang) @ 2024-04-01 12:00:00\n" +
+				"UPS   TITLE                TAGS\n" +
+				"1337  Go 1.22 released     release\n" +
+				"42    Generics, \"finally\"  \n",
+		},
+		{
+			format: "markdown",
+			want: "## Top Posts ([r/golang](https://www.reddit.com/r/golang/))\n\n" +
+				"| ups | title | tags |\n" +
+				"|--:|:--|:--|\n" +
+				"| 1337 | Go 1.22 released | release |\n" +
+				"| 42 | Generics, \"finally\" |  |\n\n",
+		},
+		{
+			format: "xml",
+			errMsg: "unsupported report format: xml (must be: text, json, ndjson, csv, table, markdown)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+
+			reporter, err := report.New(tt.format, buf)
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				require.Nil(t, reporter)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, reporter.Report(testReport()))
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestMulti_Report(t *testing.T) {
+	t.Parallel()
+
+	var (
+		rep    = testReport()
+		first  = mocks.NewReporter(t)
+		second = mocks.NewReporter(t)
+		third  = mocks.NewReporter(t)
+	)
+
+	first.On("Report", rep).Return(errMockedFailure)
+	second.On("Report", rep).Return(nil)
+	third.On("Report", rep).Return(errMockedFailure)
+
+	err := report.NewMulti(first, second, third).Report(rep)
+	require.EqualError(t, err, "mocked failure\nmocked failure")
+}
+
+func TestFilter_Report(t *testing.T) {
+	t.Parallel()
+
+	var (
+		kept    = testReport()
+		dropped = testReport()
+		next    = mocks.NewReporter(t)
+	)
+
+	kept.Kind = "top-posts"
+	dropped.Kind = "top-posts-changes"
+
+	next.On("Report", kept).Return(errMockedFailure).Once()
+
+	filter := report.NewFilter(next, "top-posts-changes")
+	require.NoError(t, filter.Report(dropped))
+	require.ErrorIs(t, filter.Report(kept), errMockedFailure)
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 4
+	tablePadding  = 2
+)
+
+// Table writes each report as a titled table with aligned columns.
+type Table struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTable creates a Table reporter.
+func NewTable(w io.Writer) *Table {
+	return &Table{w: w}
+}
+
+// Report writes the report as an aligned table.
+func (t *Table) Report(r *Report) error {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "\n%s @ %s\n", r.Heading(), r.GeneratedAt.Format(time.DateTime))
+
+	tw := tabwriter.NewWriter(buf, tableMinWidth, tableTabWidth, tablePadding, ' ', 0)
+
+	header := make([]string, len(r.Columns))
+	for i, col := range r.Columns {
+		header[i] = strings.ToUpper(col)
+	}
+
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range r.Rows {
+		values := row.Values()
+
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = formatValue(v)
+		}
+
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("align table: %w", err)
+	}
+
+	return write(&t.mu, t.w, buf.Bytes())
+}
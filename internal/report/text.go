@@ -0,0 +1,47 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Text writes each report as a banner followed by one line per row.
+type Text struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewText creates a Text reporter.
+func NewText(w io.Writer) *Text {
+	return &Text{w: w}
+}
+
+// Report writes the report as human-readable text.
+func (t *Text) Report(r *Report) error {
+	buf := bytes.NewBufferString("\n")
+	buf.WriteString(r.Heading() + "\n")
+	buf.WriteString(strings.Repeat("-", 80) + "\n")
+
+	for _, row := range r.Rows {
+		buf.WriteString(row.String())
+	}
+
+	buf.WriteString("\n")
+
+	return write(&t.mu, t.w, buf.Bytes())
+}
+
+// write sends a fully rendered report in a single call so concurrent reports do not interleave.
+func write(mu *sync.Mutex, w io.Writer, p []byte) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := w.Write(p); err != nil {
+		return fmt.Errorf("error writing msg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"sync"
+	"time"
+)
+
+// firedRetention is how long an item is remembered after matching a rule, so that it does not
+// match the same rule again on every crawl that observes it.
+const firedRetention = 24 * time.Hour
+
+// Match is an item which satisfied a rule.
+type Match struct {
+	Rule    string   `json:"rule"`
+	Actions []Action `json:"actions"`
+	Item    Item     `json:"item"`
+}
+
+// Engine evaluates ingested items against rules. Each item matches a rule at most once, and a rule
+// matches at most once per cooldown so a burst of similar items does not cause an alert storm.
+type Engine struct {
+	rules []Rule
+	now   func() time.Time
+
+	mu sync.Mutex
+	// lastMatch maps rule -> time of its latest match.
+	lastMatch map[string]time.Time
+	// fired maps rule -> item name -> time it matched.
+	fired map[string]map[string]time.Time
+}
+
+// Option customizes an Engine.
+type Option func(e *Engine)
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		e.now = now
+	}
+}
+
+// NewEngine creates an Engine evaluating the rules in order.
+func NewEngine(rules []Rule, opts ...Option) *Engine {
+	e := &Engine{
+		rules:     rules,
+		now:       time.Now,
+		lastMatch: map[string]time.Time{},
+		fired:     map[string]map[string]time.Time{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Evaluate returns the rules matched by the items, in item order.
+func (e *Engine) Evaluate(items ...Item) []Match {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.forget(now)
+
+	var matches []Match
+
+	for _, item := range items {
+		for _, rule := range e.rules {
+			if _, ok := e.fired[rule.Name][item.Name]; ok || !rule.match(item, now) {
+				continue
+			}
+
+			if last, ok := e.lastMatch[rule.Name]; ok && now.Before(last.Add(rule.Cooldown)) {
+				continue
+			}
+
+			if _, ok := e.fired[rule.Name]; !ok {
+				e.fired[rule.Name] = map[string]time.Time{}
+			}
+
+			e.fired[rule.Name][item.Name] = now
+			e.lastMatch[rule.Name] = now
+
+			matches = append(matches, Match{Rule: rule.Name, Actions: rule.Actions, Item: item})
+		}
+	}
+
+	return matches
+}
+
+// forget drops items which matched long enough ago to be unlikely to be observed again.
+func (e *Engine) forget(now time.Time) {
+	for _, items := range e.fired {
+		for name, at := range items {
+			if now.Sub(at) > firedRetention {
+				delete(items, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package rules_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := rules.Parse(strings.NewReader(`watchlist mods = Spez
+rule rising: score > 500 within 30m => webhook cooldown 10m
+rule watched: author in mods => log
+rule outage: body matches /outage/i => report`))
+	require.NoError(t, err)
+
+	var (
+		now    = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		engine = rules.NewEngine(parsed, rules.WithClock(func() time.Time { return now }))
+		fresh  = rules.Item{Kind: rules.KindPost, Name: "t3_fresh", Author: "gopher", Score: 501, Created: now.Add(-time.Minute)}
+		stale  = rules.Item{Kind: rules.KindPost, Name: "t3_stale", Author: "gopher", Score: 9000, Created: now.Add(-time.Hour)}
+		rival  = rules.Item{Kind: rules.KindPost, Name: "t3_rival", Author: "gopher", Score: 600, Created: now.Add(-time.Minute)}
+		mod    = rules.Item{Kind: rules.KindComment, Name: "t1_mod", Author: "spez", Body: "Known OUTAGE", Created: now}
+	)
+
+	names := func(matches []rules.Match) []string {
+		var out []string
+		for _, match := range matches {
+			out = append(out, match.Rule+":"+match.Item.Name)
+		}
+
+		return out
+	}
+
+	require.Equal(t, []string{"rising:t3_fresh", "watched:t1_mod", "outage:t1_mod"},
+		names(engine.Evaluate(fresh, stale, rival, mod)))
+
+	// Items never match the same rule twice, and rising is cooling down.
+	now = now.Add(5 * time.Minute)
+	require.Empty(t, engine.Evaluate(fresh, rival, mod))
+
+	now = now.Add(5 * time.Minute)
+	require.Equal(t, []string{"rising:t3_rival"}, names(engine.Evaluate(fresh, rival)))
+}
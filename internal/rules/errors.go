@@ -0,0 +1,22 @@
+package rules
+
+import "fmt"
+
+// SyntaxError is returned when a rules definition cannot be parsed.
+type SyntaxError struct {
+	Line   int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("rules syntax: line %d reason: %s", e.Line, e.Reason)
+}
+
+func NewSyntaxError(line int, reason string) *SyntaxError {
+	return &SyntaxError{Line: line, Reason: reason}
+}
+
+// syntaxErrorf describes a syntax error whose line is filled in by Parse.
+func syntaxErrorf(format string, args ...any) *SyntaxError {
+	return &SyntaxError{Reason: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,358 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fieldScore     = "score"
+	fieldComments  = "comments"
+	fieldAuthor    = "author"
+	fieldSubreddit = "subreddit"
+	fieldTitle     = "title"
+	fieldBody      = "body"
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	operators   = []string{">", ">=", "<", "<=", "==", "!="}
+)
+
+// Parse reads rule definitions, one per line. Blank lines and lines starting with # are ignored.
+//
+//	watchlist mods = spez, kn0thing
+//	rule rising: score > 500 within 30m => webhook, report cooldown 10m
+//	rule watched: author in mods => log
+//	rule outage: title matches /outage|down/i and comments >= 10 => webhook, log cooldown 1h
+//
+// Conditions compare score or comments, check the author or subreddit against a watchlist, or match
+// the title or body against a regular expression; the i flag ignores case. Every condition joined
+// with "and" must hold for the rule to match. Actions are log, webhook and report. Watchlists may be
+// declared anywhere in the file.
+func Parse(r io.Reader) ([]Rule, error) {
+	type line struct {
+		num  int
+		text string
+	}
+
+	var (
+		lines      []line
+		watchlists = map[string][]string{}
+		scanner    = bufio.NewScanner(r)
+	)
+
+	for num := 1; scanner.Scan(); num++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(text, "watchlist "); ok {
+			name, members, err := parseWatchlist(rest)
+			if err != nil {
+				err.Line = num
+
+				return nil, err
+			}
+
+			watchlists[name] = members
+
+			continue
+		}
+
+		lines = append(lines, line{num: num, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(lines))
+
+	for _, l := range lines {
+		rest, ok := strings.CutPrefix(l.text, "rule ")
+		if !ok {
+			return nil, NewSyntaxError(l.num, "expected rule or watchlist")
+		}
+
+		rule, err := parseRule(rest, watchlists)
+		if err != nil {
+			err.Line = l.num
+
+			return nil, err
+		}
+
+		if slices.ContainsFunc(rules, func(r Rule) bool { return r.Name == rule.Name }) {
+			return nil, NewSyntaxError(l.num, "duplicate rule: "+rule.Name)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseWatchlist(text string) (string, []string, *SyntaxError) {
+	name, list, ok := strings.Cut(text, "=")
+	if !ok {
+		return "", nil, syntaxErrorf("expected = after watchlist name")
+	}
+
+	name = strings.TrimSpace(name)
+	if !namePattern.MatchString(name) {
+		return "", nil, syntaxErrorf("invalid watchlist name: %q", name)
+	}
+
+	var members []string
+
+	for _, member := range strings.Split(list, ",") {
+		if member = strings.TrimSpace(member); member != "" {
+			members = append(members, member)
+		}
+	}
+
+	return name, members, nil
+}
+
+func parseRule(text string, watchlists map[string][]string) (Rule, *SyntaxError) {
+	name, rest, ok := strings.Cut(text, ":")
+	if !ok {
+		return Rule{}, syntaxErrorf("expected : after rule name")
+	}
+
+	rule := Rule{Name: strings.TrimSpace(name)}
+	if !namePattern.MatchString(rule.Name) {
+		return Rule{}, syntaxErrorf("invalid rule name: %q", rule.Name)
+	}
+
+	// Actions never contain "=>", unlike a regular expression in the conditions might.
+	arrow := strings.LastIndex(rest, "=>")
+	if arrow < 0 {
+		return Rule{}, syntaxErrorf("expected => before actions")
+	}
+
+	var err *SyntaxError
+
+	if rule.Conditions, err = parseConditions(rest[:arrow], watchlists); err != nil {
+		return Rule{}, err
+	}
+
+	if rule.Actions, rule.Cooldown, err = parseActions(rest[arrow+len("=>"):]); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func parseActions(text string) ([]Action, time.Duration, *SyntaxError) {
+	var (
+		actions  []Action
+		cooldown time.Duration
+		words    = strings.Fields(strings.ReplaceAll(text, ",", " "))
+	)
+
+	for i := 0; i < len(words); i++ {
+		if words[i] == "cooldown" {
+			if i != len(words)-2 {
+				return nil, 0, syntaxErrorf("expected a duration after cooldown, at the end of the rule")
+			}
+
+			d, parseErr := time.ParseDuration(words[i+1])
+			if parseErr != nil || d < 0 {
+				return nil, 0, syntaxErrorf("invalid cooldown: %s", words[i+1])
+			}
+
+			cooldown = d
+
+			break
+		}
+
+		action := Action(words[i])
+		if !slices.Contains(Actions(), action) {
+			return nil, 0, syntaxErrorf("unknown action: %s", words[i])
+		}
+
+		actions = append(actions, action)
+	}
+
+	if len(actions) == 0 {
+		return nil, 0, syntaxErrorf("expected at least one action")
+	}
+
+	return actions, cooldown, nil
+}
+
+func parseConditions(text string, watchlists map[string][]string) ([]Condition, *SyntaxError) {
+	var (
+		conds []Condition
+		sc    = &condScanner{text: text}
+	)
+
+	for {
+		cond, err := sc.condition(watchlists)
+		if err != nil {
+			return nil, err
+		}
+
+		conds = append(conds, cond)
+
+		switch word := sc.word(); word {
+		case "":
+			return conds, nil
+		case "and":
+		default:
+			return nil, syntaxErrorf("expected and, got %q", word)
+		}
+	}
+}
+
+// condScanner reads the words and regular expressions of a rule's conditions.
+type condScanner struct {
+	text string
+	pos  int
+}
+
+func (sc *condScanner) skipSpace() {
+	for sc.pos < len(sc.text) && (sc.text[sc.pos] == ' ' || sc.text[sc.pos] == '\t') {
+		sc.pos++
+	}
+}
+
+func (sc *condScanner) word() string {
+	sc.skipSpace()
+
+	return sc.token()
+}
+
+// token reads up to the next space.
+func (sc *condScanner) token() string {
+	start := sc.pos
+	for sc.pos < len(sc.text) && sc.text[sc.pos] != ' ' && sc.text[sc.pos] != '\t' {
+		sc.pos++
+	}
+
+	return sc.text[start:sc.pos]
+}
+
+// peek returns the next word without consuming it.
+func (sc *condScanner) peek() string {
+	pos := sc.pos
+	word := sc.word()
+	sc.pos = pos
+
+	return word
+}
+
+// regexp reads /pattern/flags, where a slash in the pattern is escaped with a backslash.
+func (sc *condScanner) regexp() (*regexp.Regexp, *SyntaxError) {
+	sc.skipSpace()
+
+	if sc.pos >= len(sc.text) || sc.text[sc.pos] != '/' {
+		return nil, syntaxErrorf("expected /pattern/ after matches")
+	}
+
+	var pattern strings.Builder
+
+	for sc.pos++; ; sc.pos++ {
+		if sc.pos >= len(sc.text) {
+			return nil, syntaxErrorf("unterminated pattern")
+		}
+
+		c := sc.text[sc.pos]
+		if c == '/' {
+			sc.pos++
+
+			break
+		}
+
+		if c == '\\' && sc.pos+1 < len(sc.text) && sc.text[sc.pos+1] == '/' {
+			sc.pos++
+			c = '/'
+		}
+
+		pattern.WriteByte(c)
+	}
+
+	expr := pattern.String()
+
+	// Flags immediately follow the closing slash.
+	switch flags := sc.token(); flags {
+	case "":
+	case "i":
+		expr = "(?i)" + expr
+	default:
+		return nil, syntaxErrorf("unknown pattern flags: %s", flags)
+	}
+
+	re, compileErr := regexp.Compile(expr)
+	if compileErr != nil {
+		return nil, syntaxErrorf("invalid pattern: %v", compileErr)
+	}
+
+	return re, nil
+}
+
+func (sc *condScanner) condition(watchlists map[string][]string) (Condition, *SyntaxError) {
+	switch field := sc.word(); field {
+	case fieldScore, fieldComments:
+		cond := Compare{Field: field, Op: sc.word()}
+		if !slices.Contains(operators, cond.Op) {
+			return nil, syntaxErrorf("expected one of %s after %s", strings.Join(operators, " "), field)
+		}
+
+		value, convErr := strconv.Atoi(sc.word())
+		if convErr != nil {
+			return nil, syntaxErrorf("expected a number after %s %s", field, cond.Op)
+		}
+
+		cond.Value = value
+
+		if sc.peek() == "within" {
+			sc.word()
+
+			window, parseErr := time.ParseDuration(sc.word())
+			if parseErr != nil || window <= 0 {
+				return nil, syntaxErrorf("expected a positive duration after within")
+			}
+
+			cond.Within = window
+		}
+
+		return cond, nil
+	case fieldAuthor, fieldSubreddit:
+		if sc.word() != "in" {
+			return nil, syntaxErrorf("expected in after %s", field)
+		}
+
+		name := sc.word()
+
+		members, ok := watchlists[name]
+		if !ok {
+			return nil, syntaxErrorf("unknown watchlist: %q", name)
+		}
+
+		return In{Field: field, Watchlist: name, Members: members}, nil
+	case fieldTitle, fieldBody:
+		if sc.word() != "matches" {
+			return nil, syntaxErrorf("expected matches after %s", field)
+		}
+
+		re, err := sc.regexp()
+		if err != nil {
+			return nil, err
+		}
+
+		return Matches{Field: field, Re: re}, nil
+	case "":
+		return nil, syntaxErrorf("expected a condition")
+	default:
+		return nil, syntaxErrorf("unknown field: %q", field)
+	}
+}
@@ -0,0 +1,127 @@
+package rules_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		want   []string
+		errMsg string
+	}{
+		{
+			name: "Parses rules, watchlists and comments",
+			input: `# Rules evaluated on every ingested post and comment.
+rule rising: score > 500 within 30m => webhook, report cooldown 10m
+
+rule watched: author in mods => log
+rule outage: title matches /outage|down\/up/i and comments >= 10 => webhook,log cooldown 1h
+watchlist mods = spez, kn0thing`,
+			want: []string{
+				"rising: score > 500 within 30m0s => [webhook report] cooldown 10m0s",
+				"watched: author in mods => [log] cooldown 0s",
+				"outage: title matches /(?i)outage|down/up/ and comments >= 10 => [webhook log] cooldown 1h0m0s",
+			},
+		},
+		{
+			name:  "Pattern may contain an arrow",
+			input: `rule arrow: body matches /a => b/ => log`,
+			want:  []string{"arrow: body matches /a => b/ => [log] cooldown 0s"},
+		},
+		{
+			name:   "Unknown watchlist",
+			input:  "rule watched: author in admins => log",
+			errMsg: `rules syntax: line 1 reason: unknown watchlist: "admins"`,
+		},
+		{
+			name:   "Unknown field",
+			input:  "\nrule big: ups > 5 => log",
+			errMsg: `rules syntax: line 2 reason: unknown field: "ups"`,
+		},
+		{
+			name:   "Missing operator",
+			input:  "rule big: score 5 => log",
+			errMsg: `rules syntax: line 1 reason: expected one of > >= < <= == != after score`,
+		},
+		{
+			name:   "Conditions must be joined with and",
+			input:  "rule big: score > 5 or comments > 5 => log",
+			errMsg: `rules syntax: line 1 reason: expected and, got "or"`,
+		},
+		{
+			name:   "Unknown action",
+			input:  "rule big: score > 5 => page",
+			errMsg: `rules syntax: line 1 reason: unknown action: page`,
+		},
+		{
+			name:   "Missing actions",
+			input:  "rule big: score > 5 => cooldown 1m",
+			errMsg: `rules syntax: line 1 reason: expected at least one action`,
+		},
+		{
+			name:   "Invalid pattern",
+			input:  "rule bad: title matches /(/ => log",
+			errMsg: "rules syntax: line 1 reason: invalid pattern: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:   "Unterminated pattern",
+			input:  "rule bad: title matches /outage => log",
+			errMsg: `rules syntax: line 1 reason: unterminated pattern`,
+		},
+		{
+			name:   "Duplicate rule",
+			input:  "rule big: score > 5 => log\nrule big: score > 50 => log",
+			errMsg: `rules syntax: line 2 reason: duplicate rule: big`,
+		},
+		{
+			name:   "Not a rule",
+			input:  "score > 5 => log",
+			errMsg: `rules syntax: line 1 reason: expected rule or watchlist`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := rules.Parse(strings.NewReader(tt.input))
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				require.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			described := make([]string, len(got))
+			for i, rule := range got {
+				conds := make([]string, len(rule.Conditions))
+				for j, cond := range rule.Conditions {
+					conds[j] = cond.String()
+				}
+
+				described[i] = rule.Name + ": " + strings.Join(conds, " and ") + " => [" +
+					strings.Join(actionNames(rule.Actions), " ") + "] cooldown " + rule.Cooldown.String()
+			}
+
+			require.Equal(t, tt.want, described)
+		})
+	}
+}
+
+func actionNames(actions []rules.Action) []string {
+	out := make([]string, len(actions))
+	for i, action := range actions {
+		out[i] = string(action)
+	}
+
+	return out
+}
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Item kinds evaluated by rules.
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// Action is performed when a rule matches.
+type Action string
+
+const (
+	ActionLog     Action = "log"
+	ActionWebhook Action = "webhook"
+	ActionReport  Action = "report"
+)
+
+// Actions lists the supported actions.
+func Actions() []Action {
+	return []Action{ActionLog, ActionWebhook, ActionReport}
+}
+
+// Item is an ingested post or comment. Comments have no title.
+type Item struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Subreddit string    `json:"subreddit"`
+	Author    string    `json:"author"`
+	Title     string    `json:"title,omitempty"`
+	Body      string    `json:"body,omitempty"`
+	Permalink string    `json:"permalink,omitempty"`
+	Score     int       `json:"score"`
+	Comments  int       `json:"comments"`
+	Created   time.Time `json:"created"`
+}
+
+// Rule performs its actions on items satisfying every condition, at most once per Cooldown.
+type Rule struct {
+	Name       string
+	Conditions []Condition
+	Actions    []Action
+	Cooldown   time.Duration
+}
+
+// Condition is a predicate on an item, evaluated at a point in time.
+type Condition interface {
+	Match(item Item, now time.Time) bool
+	String() string
+}
+
+func (r Rule) match(item Item, now time.Time) bool {
+	for _, cond := range r.Conditions {
+		if !cond.Match(item, now) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Compare is satisfied when a numeric field compares to Value, e.g. "score > 500". When Within is
+// set, only items created that recently are considered.
+type Compare struct {
+	Field  string
+	Op     string
+	Value  int
+	Within time.Duration
+}
+
+func (c Compare) Match(item Item, now time.Time) bool {
+	if c.Within > 0 && item.Created.Before(now.Add(-c.Within)) {
+		return false
+	}
+
+	got := item.Score
+	if c.Field == fieldComments {
+		got = item.Comments
+	}
+
+	switch c.Op {
+	case ">":
+		return got > c.Value
+	case ">=":
+		return got >= c.Value
+	case "<":
+		return got < c.Value
+	case "<=":
+		return got <= c.Value
+	case "!=":
+		return got != c.Value
+	default:
+		return got == c.Value
+	}
+}
+
+func (c Compare) String() string {
+	s := c.Field + " " + c.Op + " " + strconv.Itoa(c.Value)
+	if c.Within > 0 {
+		s += " within " + c.Within.String()
+	}
+
+	return s
+}
+
+// In is satisfied when the author or subreddit belongs to a watchlist, ignoring case.
+type In struct {
+	Field     string
+	Watchlist string
+	Members   []string
+}
+
+func (c In) Match(item Item, _ time.Time) bool {
+	got := item.Author
+	if c.Field == fieldSubreddit {
+		got = item.Subreddit
+	}
+
+	return slices.ContainsFunc(c.Members, func(member string) bool {
+		return strings.EqualFold(member, got)
+	})
+}
+
+func (c In) String() string {
+	return c.Field + " in " + c.Watchlist
+}
+
+// Matches is satisfied when the title or body matches a regular expression.
+type Matches struct {
+	Field string
+	Re    *regexp.Regexp
+}
+
+func (c Matches) Match(item Item, _ time.Time) bool {
+	if c.Field == fieldBody {
+		return c.Re.MatchString(item.Body)
+	}
+
+	return c.Re.MatchString(item.Title)
+}
+
+func (c Matches) String() string {
+	return c.Field + " matches /" + c.Re.String() + "/"
+}
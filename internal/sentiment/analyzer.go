@@ -0,0 +1,301 @@
+package sentiment
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Scoring constants follow the empirically derived values published with VADER
+// (Hutto & Gilbert, 2014).
+const (
+	boosterIncrement    = 0.293
+	boosterDecrement    = -0.293
+	capsIncrement       = 0.733
+	negationScalar      = -0.74
+	normalizationAlpha  = 15
+	exclamationWeight   = 0.292
+	maxExclamations     = 4
+	questionWeight      = 0.18
+	maxQuestionBonus    = 0.96
+	maxQuestionMarks    = 3
+	lookBehind          = 3
+	secondWordDampening = 0.95
+	thirdWordDampening  = 0.9
+	butBeforeScalar     = 0.5
+	butAfterScalar      = 1.5
+)
+
+// boosters scale the valence of the word that follows them.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var boosters = map[string]float64{
+	"absolutely": boosterIncrement, "amazingly": boosterIncrement, "completely": boosterIncrement,
+	"considerably": boosterIncrement, "deeply": boosterIncrement, "enormously": boosterIncrement,
+	"entirely": boosterIncrement, "especially": boosterIncrement, "exceptionally": boosterIncrement,
+	"extremely": boosterIncrement, "fully": boosterIncrement, "greatly": boosterIncrement,
+	"highly": boosterIncrement, "hugely": boosterIncrement, "incredibly": boosterIncrement,
+	"insanely": boosterIncrement, "most": boosterIncrement, "particularly": boosterIncrement,
+	"purely": boosterIncrement, "quite": boosterIncrement, "really": boosterIncrement,
+	"remarkably": boosterIncrement, "so": boosterIncrement, "super": boosterIncrement,
+	"thoroughly": boosterIncrement, "totally": boosterIncrement, "tremendously": boosterIncrement,
+	"truly": boosterIncrement, "utterly": boosterIncrement, "very": boosterIncrement,
+	"almost": boosterDecrement, "barely": boosterDecrement, "hardly": boosterDecrement,
+	"kinda": boosterDecrement, "less": boosterDecrement, "little": boosterDecrement,
+	"marginally": boosterDecrement, "occasionally": boosterDecrement, "partly": boosterDecrement,
+	"scarcely": boosterDecrement, "slightly": boosterDecrement, "somewhat": boosterDecrement,
+	"sorta": boosterDecrement,
+}
+
+// negations flip and dampen the valence of the words that follow them.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var negations = map[string]struct{}{
+	"aint": {}, "arent": {}, "cannot": {}, "cant": {}, "couldnt": {}, "darent": {}, "didnt": {},
+	"doesnt": {}, "dont": {}, "hadnt": {}, "hasnt": {}, "havent": {}, "isnt": {}, "mightnt": {},
+	"mustnt": {}, "neither": {}, "never": {}, "no": {}, "nobody": {}, "none": {}, "nope": {},
+	"nor": {}, "not": {}, "nothing": {}, "nowhere": {}, "shouldnt": {}, "wasnt": {}, "werent": {},
+	"without": {}, "wont": {}, "wouldnt": {},
+}
+
+// Score is the sentiment of a piece of text. Compound is normalized to [-1, 1] while Positive,
+// Negative and Neutral are the proportions of the text falling in each category.
+type Score struct {
+	Compound, Positive, Negative, Neutral float64
+}
+
+// Analyzer scores text using a lexicon with negation, intensifier and emphasis handling.
+type Analyzer struct {
+	lexicon Lexicon
+}
+
+// NewAnalyzer creates an Analyzer backed by the provided lexicon.
+func NewAnalyzer(lexicon Lexicon) *Analyzer {
+	return &Analyzer{lexicon: lexicon}
+}
+
+// Score calculates the sentiment of the provided text.
+func (a *Analyzer) Score(text string) Score {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return Score{}
+	}
+
+	capsDiff := hasCapsDifferential(tokens)
+	valences := make([]float64, len(tokens))
+
+	for i, token := range tokens {
+		valences[i] = a.valence(tokens, i, token, capsDiff)
+	}
+
+	applyButCheck(tokens, valences)
+
+	return score(valences, punctuationEmphasis(text))
+}
+
+func (a *Analyzer) valence(tokens []string, i int, token string, capsDiff bool) float64 {
+	lower := strings.ToLower(token)
+	if _, ok := boosters[lower]; ok {
+		return 0
+	}
+
+	valence, ok := a.lexicon[lower]
+	if !ok {
+		return 0
+	}
+
+	if capsDiff && isUpper(token) {
+		valence += math.Copysign(capsIncrement, valence)
+	}
+
+	negated := false
+
+	for distance := 1; distance <= lookBehind && i-distance >= 0; distance++ {
+		prev := tokens[i-distance]
+		prevLower := strings.ToLower(prev)
+
+		if _, inLexicon := a.lexicon[prevLower]; !inLexicon {
+			valence += boost(prev, valence, capsDiff) * distanceDampening(distance)
+		}
+
+		if isNegation(prevLower) {
+			negated = true
+		}
+	}
+
+	if negated {
+		valence *= negationScalar
+	}
+
+	return valence
+}
+
+func boost(word string, valence float64, capsDiff bool) float64 {
+	scalar, ok := boosters[strings.ToLower(word)]
+	if !ok {
+		return 0
+	}
+
+	if valence < 0 {
+		scalar *= -1
+	}
+
+	if capsDiff && isUpper(word) {
+		scalar += math.Copysign(capsIncrement, valence)
+	}
+
+	return scalar
+}
+
+func distanceDampening(distance int) float64 {
+	switch distance {
+	case 1:
+		return 1
+	case lookBehind - 1:
+		return secondWordDampening
+	default:
+		return thirdWordDampening
+	}
+}
+
+func isNegation(lower string) bool {
+	if strings.Contains(lower, "n't") {
+		return true
+	}
+
+	_, ok := negations[strings.ReplaceAll(lower, "'", "")]
+
+	return ok
+}
+
+// applyButCheck shifts emphasis to the clause following "but".
+func applyButCheck(tokens []string, valences []float64) {
+	for i, token := range tokens {
+		if strings.ToLower(token) != "but" {
+			continue
+		}
+
+		for j := range valences {
+			switch {
+			case j < i:
+				valences[j] *= butBeforeScalar
+			case j > i:
+				valences[j] *= butAfterScalar
+			}
+		}
+
+		return
+	}
+}
+
+func punctuationEmphasis(text string) float64 {
+	exclamations := min(strings.Count(text, "!"), maxExclamations)
+	emphasis := float64(exclamations) * exclamationWeight
+
+	questions := strings.Count(text, "?")
+	if questions > 1 {
+		if questions <= maxQuestionMarks {
+			emphasis += float64(questions) * questionWeight
+		} else {
+			emphasis += maxQuestionBonus
+		}
+	}
+
+	return emphasis
+}
+
+func score(valences []float64, emphasis float64) Score {
+	var sum, pos, neg, neu float64
+
+	for _, v := range valences {
+		sum += v
+
+		switch {
+		case v > 0:
+			pos += v + 1
+		case v < 0:
+			neg += v - 1
+		default:
+			neu++
+		}
+	}
+
+	switch {
+	case sum > 0:
+		sum += emphasis
+	case sum < 0:
+		sum -= emphasis
+	}
+
+	switch {
+	case pos > math.Abs(neg):
+		pos += emphasis
+	case pos < math.Abs(neg):
+		neg -= emphasis
+	}
+
+	total := pos + math.Abs(neg) + neu
+
+	return Score{
+		Compound: normalize(sum),
+		Positive: round(math.Abs(pos / total)),
+		Negative: round(math.Abs(neg / total)),
+		Neutral:  round(math.Abs(neu / total)),
+	}
+}
+
+func normalize(sum float64) float64 {
+	norm := sum / math.Sqrt(sum*sum+normalizationAlpha)
+
+	return round(max(-1, min(1, norm)))
+}
+
+func round(f float64) float64 {
+	const precision = 1000
+
+	return math.Round(f*precision) / precision
+}
+
+func tokenize(text string) []string {
+	fields := strings.Fields(text)
+	tokens := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		token := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+func hasCapsDifferential(tokens []string) bool {
+	upper := 0
+
+	for _, token := range tokens {
+		if isUpper(token) {
+			upper++
+		}
+	}
+
+	return upper > 0 && upper < len(tokens)
+}
+
+func isUpper(token string) bool {
+	hasLetter := false
+
+	for _, r := range token {
+		if unicode.IsLower(r) {
+			return false
+		}
+
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+
+	return hasLetter && len([]rune(token)) > 1
+}
@@ -0,0 +1,70 @@
+package sentiment_test
+
+import (
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/sentiment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Score(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want sentiment.Score
+	}{
+		{
+			name: "Empty text is neutral",
+			text: "",
+			want: sentiment.Score{},
+		},
+		{
+			name: "Unknown words are neutral",
+			text: "nothing here",
+			want: sentiment.Score{Neutral: 1},
+		},
+		{
+			name: "Positive word",
+			text: "Go is good",
+			want: sentiment.Score{Compound: 0.44, Positive: 0.592, Neutral: 0.408},
+		},
+		{
+			name: "Intensifier boosts valence",
+			text: "Go is very good",
+			want: sentiment.Score{Compound: 0.493, Positive: 0.516, Neutral: 0.484},
+		},
+		{
+			name: "Negation flips valence",
+			text: "Go is not good",
+			want: sentiment.Score{Compound: -0.341, Negative: 0.445, Neutral: 0.555},
+		},
+		{
+			name: "Contracted negation flips valence",
+			text: "I don't love it",
+			want: sentiment.Score{Compound: -0.522, Negative: 0.529, Neutral: 0.471},
+		},
+		{
+			name: "Capitalization and exclamations add emphasis",
+			text: "Go is GOOD!!!",
+			want: sentiment.Score{Compound: 0.671, Positive: 0.693, Neutral: 0.307},
+		},
+		{
+			name: "Clause after but dominates",
+			text: "The API is great but the docs are terrible",
+			want: sentiment.Score{Compound: -0.382, Positive: 0.186, Negative: 0.303, Neutral: 0.511},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lexicon, err := sentiment.DefaultLexicon()
+			require.NoError(t, err)
+
+			a := sentiment.NewAnalyzer(lexicon)
+			require.Equal(t, tt.want, a.Score(tt.text))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package sentiment
+
+import "fmt"
+
+// InvalidLexiconEntryError is returned when a lexicon line cannot be parsed.
+type InvalidLexiconEntryError struct {
+	Line   int
+	reason string
+}
+
+func (e *InvalidLexiconEntryError) Error() string {
+	return fmt.Sprintf("invalid lexicon entry on line %d: %s", e.Line, e.reason)
+}
+
+func NewInvalidLexiconEntryError(line int, reason string) *InvalidLexiconEntryError {
+	return &InvalidLexiconEntryError{Line: line, reason: reason}
+}
@@ -0,0 +1,64 @@
+package sentiment
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//go:embed lexicon.txt
+var defaultLexicon string
+
+// Lexicon maps lower-cased tokens to a valence between -4 (most negative) and +4 (most positive).
+type Lexicon map[string]float64
+
+// DefaultLexicon returns a copy of the lexicon compiled into the binary.
+func DefaultLexicon() (Lexicon, error) {
+	lex, err := LoadLexicon(strings.NewReader(defaultLexicon))
+	if err != nil {
+		return nil, fmt.Errorf("embedded lexicon: %w", err)
+	}
+
+	return lex, nil
+}
+
+// LoadLexicon parses a VADER-style lexicon: one token per line followed by its mean valence,
+// separated by whitespace. Any trailing columns (standard deviation, raw ratings) are ignored,
+// as are blank lines and lines starting with '#'.
+func LoadLexicon(r io.Reader) (Lexicon, error) {
+	var (
+		lex     = Lexicon{}
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, NewInvalidLexiconEntryError(line, "expected token and valence")
+		}
+
+		valence, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, NewInvalidLexiconEntryError(line, err.Error())
+		}
+
+		lex[strings.ToLower(fields[0])] = valence
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read lexicon: %w", err)
+	}
+
+	return lex, nil
+}
@@ -0,0 +1,52 @@
+package sentiment_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/sentiment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLexicon(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		want   sentiment.Lexicon
+		errMsg string
+	}{
+		{
+			name:  "Parses VADER format, ignoring comments and trailing columns",
+			input: "# comment\n\nGood\t1.9\t0.9\t[2, 2, 1]\nbad -2.5\n",
+			want:  sentiment.Lexicon{"good": 1.9, "bad": -2.5},
+		},
+		{
+			name:   "Missing valence",
+			input:  "good\n",
+			errMsg: "invalid lexicon entry on line 1: expected token and valence",
+		},
+		{
+			name:   "Invalid valence",
+			input:  "good 1.9\nbad worse\n",
+			errMsg: `invalid lexicon entry on line 2: strconv.ParseFloat: parsing "worse": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := sentiment.LoadLexicon(strings.NewReader(tt.input))
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				require.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
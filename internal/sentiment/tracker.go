@@ -0,0 +1,159 @@
+package sentiment
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kind identifies the type of content an Entry was scored from.
+type Kind string
+
+const (
+	KindPost    Kind = "post"
+	KindComment Kind = "comment"
+)
+
+// Entry is a scored post title or comment body.
+type Entry struct {
+	ID      string
+	Kind    Kind
+	Text    string
+	Created time.Time
+	Score   Score
+}
+
+// Bucket summarizes the mood of a subreddit over a period of time.
+type Bucket struct {
+	Start time.Time
+	Mean  float64
+	Count int
+}
+
+// Tracker retains scored entries per subreddit so mood over time and extremes can be reported.
+// Entries are keyed by ID, so observing the same post repeatedly does not skew results.
+type Tracker struct {
+	// retention is how long before a subreddit's newest entry older entries are kept; zero keeps
+	// every entry.
+	retention time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]map[string]Entry
+}
+
+// NewTracker creates an empty Tracker keeping entries created up to retention before the newest
+// entry of their subreddit, e.g. the period mood is reported over. Zero keeps every entry.
+func NewTracker(retention time.Duration) *Tracker {
+	return &Tracker{retention: retention, entries: map[string]map[string]Entry{}}
+}
+
+// Record stores scored entries for a subreddit, replacing prior entries with the same ID, and
+// drops the entries which fell out of retention.
+func (t *Tracker) Record(subreddit string, entries ...Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.entries[subreddit]; !ok {
+		t.entries[subreddit] = map[string]Entry{}
+	}
+
+	for _, entry := range entries {
+		t.entries[subreddit][entry.ID] = entry
+	}
+
+	if t.retention <= 0 {
+		return
+	}
+
+	var newest time.Time
+	for _, entry := range t.entries[subreddit] {
+		if entry.Created.After(newest) {
+			newest = entry.Created
+		}
+	}
+
+	cutoff := newest.Add(-t.retention)
+	for id, entry := range t.entries[subreddit] {
+		if entry.Created.Before(cutoff) {
+			delete(t.entries[subreddit], id)
+		}
+	}
+}
+
+// Forget drops every entry recorded for a subreddit, e.g. once it is no longer tracked.
+func (t *Tracker) Forget(subreddit string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, subreddit)
+}
+
+// Mood groups a subreddit's entries into buckets of the provided width by creation time and
+// returns the most recent buckets, oldest first. A limit of zero or less returns every bucket.
+func (t *Tracker) Mood(subreddit string, width time.Duration, limit int) []Bucket {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	sums := map[time.Time]*Bucket{}
+
+	for _, entry := range t.entries[subreddit] {
+		start := entry.Created.Truncate(width)
+		if _, ok := sums[start]; !ok {
+			sums[start] = &Bucket{Start: start}
+		}
+
+		sums[start].Mean += entry.Score.Compound
+		sums[start].Count++
+	}
+
+	buckets := make([]Bucket, 0, len(sums))
+	for _, bucket := range sums {
+		bucket.Mean = round(bucket.Mean / float64(bucket.Count))
+		buckets = append(buckets, *bucket)
+	}
+
+	slices.SortFunc(buckets, func(a, b Bucket) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	if limit > 0 && len(buckets) > limit {
+		buckets = buckets[len(buckets)-limit:]
+	}
+
+	return buckets
+}
+
+// Extremes returns up to num of the most positive and most negative entries of a kind, none when
+// num is zero or less.
+func (t *Tracker) Extremes(subreddit string, kind Kind, num int) ([]Entry, []Entry) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var positive, negative []Entry
+
+	for _, entry := range t.entries[subreddit] {
+		if entry.Kind != kind {
+			continue
+		}
+
+		switch {
+		case entry.Score.Compound > 0:
+			positive = append(positive, entry)
+		case entry.Score.Compound < 0:
+			negative = append(negative, entry)
+		}
+	}
+
+	slices.SortFunc(positive, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(b.Score.Compound, a.Score.Compound), cmp.Compare(a.ID, b.ID))
+	})
+
+	slices.SortFunc(negative, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Score.Compound, b.Score.Compound), cmp.Compare(a.ID, b.ID))
+	})
+
+	num = max(num, 0)
+
+	return positive[:min(num, len(positive))], negative[:min(num, len(negative))]
+}
@@ -0,0 +1,69 @@
+package sentiment_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/sentiment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	hour := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker := sentiment.NewTracker(0)
+	tracker.Record("golang",
+		sentiment.Entry{ID: "t3_a", Kind: sentiment.KindPost, Created: hour, Score: sentiment.Score{Compound: 0.5}},
+		sentiment.Entry{ID: "t3_b", Kind: sentiment.KindPost, Created: hour.Add(time.Minute), Score: sentiment.Score{Compound: -0.25}},
+		sentiment.Entry{ID: "t1_c", Kind: sentiment.KindComment, Created: hour.Add(time.Hour), Score: sentiment.Score{Compound: -0.9}},
+	)
+	// Observing the same post again replaces, rather than duplicates, the entry.
+	tracker.Record("golang",
+		sentiment.Entry{ID: "t3_a", Kind: sentiment.KindPost, Created: hour, Score: sentiment.Score{Compound: 0.5}},
+	)
+
+	require.Equal(t, []sentiment.Bucket{
+		{Start: hour, Mean: 0.125, Count: 2},
+		{Start: hour.Add(time.Hour), Mean: -0.9, Count: 1},
+	}, tracker.Mood("golang", time.Hour, 0))
+
+	require.Equal(t, []sentiment.Bucket{
+		{Start: hour.Add(time.Hour), Mean: -0.9, Count: 1},
+	}, tracker.Mood("golang", time.Hour, 1))
+
+	positive, negative := tracker.Extremes("golang", sentiment.KindPost, 5)
+	require.Len(t, positive, 1)
+	require.Equal(t, "t3_a", positive[0].ID)
+	require.Len(t, negative, 1)
+	require.Equal(t, "t3_b", negative[0].ID)
+
+	positive, negative = tracker.Extremes("golang", sentiment.KindPost, -1)
+	require.Empty(t, positive)
+	require.Empty(t, negative)
+
+	require.Empty(t, tracker.Mood("python", time.Hour, 0))
+}
+
+func TestTracker_Retention(t *testing.T) {
+	t.Parallel()
+
+	hour := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker := sentiment.NewTracker(2 * time.Hour)
+	tracker.Record("golang",
+		sentiment.Entry{ID: "t3_a", Kind: sentiment.KindPost, Created: hour, Score: sentiment.Score{Compound: 0.5}},
+		sentiment.Entry{ID: "t3_b", Kind: sentiment.KindPost, Created: hour.Add(time.Hour), Score: sentiment.Score{Compound: 0.25}},
+	)
+
+	// Entries falling more than the retention behind the newest are dropped.
+	tracker.Record("golang",
+		sentiment.Entry{ID: "t3_c", Kind: sentiment.KindPost, Created: hour.Add(150 * time.Minute), Score: sentiment.Score{Compound: -0.5}},
+	)
+
+	require.Equal(t, []sentiment.Bucket{
+		{Start: hour.Add(time.Hour), Mean: 0.25, Count: 1},
+		{Start: hour.Add(2 * time.Hour), Mean: -0.5, Count: 1},
+	}, tracker.Mood("golang", time.Hour, 0))
+}
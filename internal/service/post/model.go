@@ -1,17 +1,34 @@
 package post
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+)
 
 // Post represents a topic.
 type Post struct {
-	Title string
-	Ups   int
+	Name      string
+	Title     string
+	Ups       int
+	Permalink string
 }
 
 func (p *Post) String() string {
 	return fmt.Sprintf("(%d) - %s \n", p.Ups, p.Title)
 }
 
+func (p *Post) Values() []any {
+	return []any{p.Ups, p.Title, p.Permalink}
+}
+
+func postColumns() []string {
+	return []string{"ups", "title", "permalink"}
+}
+
 // AuthorPosts represents a count of posts created by a user.
 type AuthorPosts struct {
 	Author string
@@ -21,3 +38,239 @@ type AuthorPosts struct {
 func (a *AuthorPosts) String() string {
 	return fmt.Sprintf("(%d) - %s \n", a.Qty, a.Author)
 }
+
+func (a *AuthorPosts) Values() []any {
+	return []any{a.Qty, a.Author}
+}
+
+func authorPostsColumns() []string {
+	return []string{"posts", "author"}
+}
+
+// Mood represents the average sentiment of a subreddit's posts and comments over a period of time.
+type Mood struct {
+	Start    time.Time
+	Compound float64
+	Qty      int
+}
+
+func (m *Mood) String() string {
+	return fmt.Sprintf("(%+.3f) - %s (%d) \n", m.Compound, m.Start.Format(time.DateTime), m.Qty)
+}
+
+func (m *Mood) Values() []any {
+	return []any{m.Compound, m.Start, m.Qty}
+}
+
+func moodColumns() []string {
+	return []string{"compound", "start", "count"}
+}
+
+// ScoredPost represents a post with its title's sentiment.
+type ScoredPost struct {
+	Title    string
+	Compound float64
+}
+
+func (p *ScoredPost) String() string {
+	return fmt.Sprintf("(%+.3f) - %s \n", p.Compound, p.Title)
+}
+
+func (p *ScoredPost) Values() []any {
+	return []any{p.Compound, p.Title}
+}
+
+func scoredPostColumns() []string {
+	return []string{"compound", "title"}
+}
+
+// AuthorOverlap represents an author who posts in several tracked subreddits.
+type AuthorOverlap struct {
+	Author     string
+	Subreddits []string
+	Qty        int
+}
+
+func (a *AuthorOverlap) String() string {
+	return fmt.Sprintf("(%d) - %s [%s] \n", a.Qty, a.Author, strings.Join(a.Subreddits, ", "))
+}
+
+func (a *AuthorOverlap) Values() []any {
+	return []any{a.Qty, a.Author, a.Subreddits}
+}
+
+func authorOverlapColumns() []string {
+	return []string{"posts", "author", "subreddits"}
+}
+
+// SubredditSimilarity represents the overlap between the authors of two subreddits.
+type SubredditSimilarity struct {
+	A, B    string
+	Jaccard float64
+	Shared  int
+	Bridges []string
+}
+
+func (s *SubredditSimilarity) String() string {
+	return fmt.Sprintf("(%.3f) - %s ~ %s, %d shared [%s] \n", s.Jaccard, s.A, s.B, s.Shared, strings.Join(s.Bridges, ", "))
+}
+
+func (s *SubredditSimilarity) Values() []any {
+	return []any{s.Jaccard, s.A, s.B, s.Shared, s.Bridges}
+}
+
+func subredditSimilarityColumns() []string {
+	return []string{"jaccard", "subreddit_a", "subreddit_b", "shared", "bridges"}
+}
+
+// RemovedPost represents a post detected as removed or deleted.
+type RemovedPost struct {
+	Title, Author, Kind string
+	Detected            time.Time
+}
+
+func (r *RemovedPost) String() string {
+	return fmt.Sprintf("(%s) - %s - %s (%s) \n", r.Kind, r.Detected.Format(time.DateTime), r.Title, r.Author)
+}
+
+func (r *RemovedPost) Values() []any {
+	return []any{r.Kind, r.Detected, r.Title, r.Author}
+}
+
+func removedPostColumns() []string {
+	return []string{"kind", "detected", "title", "author"}
+}
+
+// RemovalRate represents the share of a subreddit's or author's observed posts that were removed.
+type RemovalRate struct {
+	stats.RemovalRate
+}
+
+func (r *RemovalRate) String() string {
+	return fmt.Sprintf("(%.1f%%) - %s %d/%d \n", r.Rate()*100, r.Key, r.Removed, r.Observed)
+}
+
+func (r *RemovalRate) Values() []any {
+	return []any{r.Rate(), r.Key, r.Removed, r.Observed}
+}
+
+// removalRateColumns names the columns of a removal rate report keyed by subreddit or author.
+func removalRateColumns(key string) []string {
+	return []string{"rate", key, "removed", "observed"}
+}
+
+// HeatmapRow represents one day of an activity heatmap, with a value per hour.
+type HeatmapRow struct {
+	Day   string
+	Hours []float64
+}
+
+func (h *HeatmapRow) String() string {
+	var buf strings.Builder
+
+	buf.WriteString(fmt.Sprintf("%-4s", h.Day))
+
+	for _, v := range h.Hours {
+		buf.WriteString(fmt.Sprintf("%4.0f", v))
+	}
+
+	buf.WriteString(" \n")
+
+	return buf.String()
+}
+
+func (h *HeatmapRow) Values() []any {
+	values := make([]any, 0, len(h.Hours)+1)
+	values = append(values, h.Day)
+
+	for _, v := range h.Hours {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+func heatmapColumns() []string {
+	columns := make([]string, 0, hoursPerDay+1)
+	columns = append(columns, "day")
+
+	for hour := range hoursPerDay {
+		columns = append(columns, strconv.Itoa(hour))
+	}
+
+	return columns
+}
+
+// DomainStat represents how often a domain is linked to and how well those posts score.
+type DomainStat struct {
+	Domain   string
+	Qty      int
+	AvgScore float64
+	Top      *Post
+}
+
+func (d *DomainStat) String() string {
+	return fmt.Sprintf("(%d) - %s avg %.1f, top (%d) %s \n", d.Qty, d.Domain, d.AvgScore, d.Top.Ups, d.Top.Title)
+}
+
+func (d *DomainStat) Values() []any {
+	return []any{d.Qty, d.Domain, d.AvgScore, d.Top.Ups, d.Top.Title}
+}
+
+func domainStatColumns() []string {
+	return []string{"posts", "domain", "avg_score", "top_ups", "top_title"}
+}
+
+// LeaderboardChange represents an entry which entered, left, moved on or changed score on a
+// leaderboard since its previous update.
+type LeaderboardChange struct {
+	Change       string `json:"change"`
+	Rank         int    `json:"rank"`
+	PreviousRank int    `json:"previous_rank"`
+	Score        int    `json:"score"`
+	ScoreDelta   int    `json:"score_delta"`
+	Entry        string `json:"entry"`
+}
+
+func (c *LeaderboardChange) String() string {
+	switch c.Change {
+	case string(stats.ChangeEntered):
+		return fmt.Sprintf("entered #%d (%d) - %s \n", c.Rank, c.Score, c.Entry)
+	case string(stats.ChangeLeft):
+		return fmt.Sprintf("left    #%d (%d) - %s \n", c.PreviousRank, c.Score, c.Entry)
+	case string(stats.ChangeMoved):
+		return fmt.Sprintf("moved   #%d -> #%d (%d, %+d) - %s \n", c.PreviousRank, c.Rank, c.Score, c.ScoreDelta, c.Entry)
+	default:
+		return fmt.Sprintf("score   #%d (%d, %+d) - %s \n", c.Rank, c.Score, c.ScoreDelta, c.Entry)
+	}
+}
+
+func (c *LeaderboardChange) Values() []any {
+	return []any{c.Change, c.Rank, c.PreviousRank, c.Score, c.ScoreDelta, c.Entry}
+}
+
+func leaderboardChangeColumns(entry string) []string {
+	return []string{"change", "rank", "previous_rank", "score", "score_delta", entry}
+}
+
+// RuleMatch represents an ingested post or comment which matched an alert rule.
+type RuleMatch struct {
+	Rule      string
+	Kind      string
+	Author    string
+	Score     int
+	Text      string
+	Permalink string
+}
+
+func (m *RuleMatch) String() string {
+	return fmt.Sprintf("[%s] (%d) - %s (%s) \n", m.Rule, m.Score, m.Text, m.Author)
+}
+
+func (m *RuleMatch) Values() []any {
+	return []any{m.Rule, m.Kind, m.Author, m.Score, m.Text, m.Permalink}
+}
+
+func ruleMatchColumns() []string {
+	return []string{"rule", "kind", "author", "score", "text", "permalink"}
+}
@@ -0,0 +1,299 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/pipeline"
+	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/rules"
+	"github.com/jqdurham/reddit/internal/sentiment"
+	"github.com/jqdurham/reddit/internal/stats"
+)
+
+// Pipeline stages between the jobs fetching listings and the reporter.
+const (
+	// StageParse normalizes fetched listings into events, scoring sentiment and resolving links.
+	StageParse = "parse"
+	// StageAggregate records events in the statistics and evaluates alert rules.
+	StageAggregate = "aggregate"
+	// StageReport writes reports to the reporter.
+	StageReport = "report"
+)
+
+// Stages lists the pipeline stages in the order items flow through them.
+func Stages() []string {
+	return []string{StageParse, StageAggregate, StageReport}
+}
+
+// source identifies what a listing was fetched for, which decides the statistics its items feed.
+type source int
+
+const (
+	// sourceTop is a subreddit's top posts.
+	sourceTop source = iota
+	// sourceAll is every post of a subreddit.
+	sourceAll
+	// sourceNew is a subreddit's newest posts, scored for sentiment and compared with the previous
+	// ones for removals.
+	sourceNew
+	// sourceComments is a subreddit's newest comments, scored for sentiment.
+	sourceComments
+)
+
+// listing is raw items fetched by a job.
+type listing struct {
+	subreddit string
+	source    source
+	fetched   time.Time
+	children  reddit.Children
+	// forgets is how often the subreddit had been forgotten when the listing was fetched.
+	forgets int
+	// then runs once the listing has been aggregated, e.g. to report the updated statistics.
+	then func(ctx context.Context) error
+}
+
+// events are the observations parsed from a listing. Each is nil when the listing's source does
+// not feed the statistic.
+type events struct {
+	*listing
+	posts    []stats.PostStat
+	links    []stats.LinkPost
+	entries  []sentiment.Entry
+	activity []stats.ActivityItem
+	observed []stats.Observation
+}
+
+// WithPipeline parses, aggregates and reports fetched listings in stages connected by bounded
+// queues, each sized by the config of its name, instead of within the job which fetched them. Jobs
+// return once their listings are queued, so they are held back only when a full stage blocks, and
+// failures past fetching are logged rather than failing the job. Run must be running.
+func WithPipeline(cfgs map[string]pipeline.Config) Option {
+	return func(s *Service) {
+		s.pipeline = cfgs
+	}
+}
+
+// newStages creates the pipeline stages, keyed by subreddit so each subreddit's listings and
+// reports are handled in the order fetched.
+func (s *Service) newStages() {
+	s.parser = pipeline.NewStage(StageParse, s.pipeline[StageParse], func(ctx context.Context, l *listing) {
+		if err := s.aggregator.Submit(ctx, s.parse(ctx, l)); err != nil {
+			logger.FromContext(ctx).Warn("listing not aggregated", "subreddit", l.subreddit, "err", err.Error())
+		}
+	}, pipeline.WithKey(func(l *listing) string { return l.subreddit }), pipeline.WithMetrics[*listing](s.metrics))
+
+	s.aggregator = pipeline.NewStage(StageAggregate, s.pipeline[StageAggregate], func(ctx context.Context, e *events) {
+		if err := s.aggregate(ctx, e); err != nil {
+			logger.FromContext(ctx).Error(err.Error(), "subreddit", e.subreddit)
+		}
+	}, pipeline.WithKey(func(e *events) string { return e.subreddit }), pipeline.WithMetrics[*events](s.metrics))
+
+	s.sink = pipeline.NewStage(StageReport, s.pipeline[StageReport], func(ctx context.Context, r *report.Report) {
+		if err := s.reporter.Report(r); err != nil {
+			logger.FromContext(ctx).Error(err.Error(), "kind", r.Kind, "subreddit", r.Subreddit)
+		}
+	}, pipeline.WithKey(func(r *report.Report) string { return r.Subreddit }), pipeline.WithMetrics[*report.Report](s.metrics))
+}
+
+// Run starts the pipeline and, once ctx is cancelled, drains it stage by stage before returning.
+// Jobs should be stopped first, as listings fetched afterwards are rejected. Without WithPipeline,
+// it returns immediately.
+func (s *Service) Run(ctx context.Context) {
+	if s.parser == nil {
+		return
+	}
+
+	// Workers outlive ctx to handle the items still queued.
+	workCtx := context.WithoutCancel(ctx)
+
+	s.parser.Start(workCtx)
+	s.aggregator.Start(workCtx)
+	s.sink.Start(workCtx)
+
+	<-ctx.Done()
+
+	s.parser.Close()
+	s.aggregator.Close()
+	s.sink.Close()
+}
+
+// ingest parses and aggregates a fetched listing, queueing it when pipelined. A listing dropped by
+// a full stage is skipped.
+func (s *Service) ingest(ctx context.Context, l *listing) error {
+	if s.parser == nil {
+		return s.aggregate(ctx, s.parse(ctx, l))
+	}
+
+	err := s.parser.Submit(ctx, l)
+
+	var dropped *pipeline.DroppedError
+	if errors.As(err, &dropped) {
+		logger.FromContext(ctx).Warn(err.Error(), "subreddit", l.subreddit)
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("queue listing: %v: %w", l.subreddit, err)
+	}
+
+	return nil
+}
+
+// parse normalizes a listing's items into the events fed by its source.
+func (s *Service) parse(ctx context.Context, l *listing) *events {
+	e := &events{listing: l}
+
+	switch l.source {
+	case sourceTop:
+		e.links = s.linkPosts(ctx, l.subreddit, l.children)
+		e.posts = postStats(l.children)
+	case sourceAll:
+		e.posts = postStats(l.children)
+	case sourceNew, sourceComments:
+		kind, itemKind := sentiment.KindPost, stats.ItemPost
+		if l.source == sourceComments {
+			kind, itemKind = sentiment.KindComment, stats.ItemComment
+		}
+
+		e.entries = make([]sentiment.Entry, 0, len(l.children))
+		e.activity = make([]stats.ActivityItem, 0, len(l.children))
+
+		for _, kid := range l.children {
+			text := kid.Post.Title
+			if kind == sentiment.KindComment {
+				text = kid.Post.Body
+			}
+
+			e.entries = append(e.entries, sentiment.Entry{
+				ID:      kid.Post.Name,
+				Kind:    kind,
+				Text:    text,
+				Created: created(kid.Post),
+				Score:   s.analyzer.Score(text),
+			})
+
+			e.activity = append(e.activity, stats.ActivityItem{
+				Name:    kid.Post.Name,
+				Kind:    itemKind,
+				Created: created(kid.Post),
+				Score:   kid.Post.Ups,
+			})
+		}
+
+		if l.source == sourceNew {
+			e.links = s.linkPosts(ctx, l.subreddit, l.children)
+			e.posts = postStats(l.children)
+			e.observed = observations(l.children)
+		}
+	}
+
+	return e
+}
+
+// aggregate records the events in the statistics, performs the actions of the alert rules they
+// match, then runs the listing's continuation. Events of a subreddit forgotten since they were
+// fetched are discarded.
+func (s *Service) aggregate(ctx context.Context, e *events) error {
+	logr := logger.FromContext(ctx)
+
+	arrived, ok := s.record(ctx, e)
+	if !ok {
+		logr.Debug("listing of forgotten subreddit discarded", "subreddit", e.subreddit)
+
+		return nil
+	}
+
+	// The arrival observer, rules and continuation run without forgetMu: they may wait on the
+	// caller forgetting the subreddit, or on a full report stage.
+	if arrived != nil {
+		s.observeArrivals(e.subreddit, arrived.posts, arrived.elapsed)
+	}
+
+	kind := rules.KindPost
+	if e.source == sourceComments {
+		kind = rules.KindComment
+	}
+
+	if err := s.evaluateRules(ctx, e.subreddit, kind, e.children); err != nil {
+		return err
+	}
+
+	if e.then == nil {
+		return nil
+	}
+
+	return e.then(ctx)
+}
+
+// arrival is the count of posts arriving between polls of the newest posts.
+type arrival struct {
+	posts   int
+	elapsed time.Duration
+}
+
+// record writes the events to the statistics, returning the posts which arrived since the previous
+// poll, if observed. It reports false, writing nothing, when the subreddit was forgotten since the
+// events were fetched.
+func (s *Service) record(ctx context.Context, e *events) (*arrival, bool) {
+	logr := logger.FromContext(ctx)
+
+	s.forgetMu.RLock()
+	defer s.forgetMu.RUnlock()
+
+	if s.forgets[e.subreddit] != e.forgets {
+		return nil, false
+	}
+
+	if e.entries != nil {
+		s.sentiment.Record(e.subreddit, e.entries...)
+	}
+
+	if e.activity != nil {
+		s.activity.Record(e.subreddit, e.activity...)
+	}
+
+	if e.links != nil {
+		s.domains.Record(e.subreddit, e.links...)
+	}
+
+	if e.posts != nil {
+		s.store.RecordPosts(e.subreddit, e.fetched, e.posts...)
+		s.metrics.AddPostsIngested(e.subreddit, len(e.posts))
+	}
+
+	if e.observed != nil {
+		for _, removal := range s.removals.Observe(e.subreddit, e.fetched, e.observed) {
+			logr.Info("post removal detected", "subreddit", e.subreddit, "name", removal.Name,
+				"author", removal.Author, "kind", removal.Kind, "detected", removal.Detected)
+		}
+	}
+
+	if e.source != sourceNew {
+		return nil, true
+	}
+
+	return s.countArrivals(e.subreddit, e.fetched, e.children), true
+}
+
+// observations snapshots the posts of a listing for detecting removals.
+func observations(children reddit.Children) []stats.Observation {
+	observed := make([]stats.Observation, 0, len(children))
+
+	for _, kid := range children {
+		observed = append(observed, stats.Observation{
+			Name:    kid.Post.Name,
+			Title:   kid.Post.Title,
+			Author:  kid.Post.Author,
+			Body:    kid.Post.Selftext,
+			Created: created(kid.Post),
+		})
+	}
+
+	return observed
+}
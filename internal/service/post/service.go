@@ -1,58 +1,258 @@
 package post
 
 import (
-	"bytes"
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jqdurham/reddit/internal/links"
 	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/metrics"
+	"github.com/jqdurham/reddit/internal/pipeline"
 	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/rules"
+	"github.com/jqdurham/reddit/internal/sentiment"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/jqdurham/reddit/internal/webhook"
+)
+
+const (
+	moodBucketWidth   = time.Hour
+	moodBuckets       = 24
+	overlapSubreddits = 2
+	hoursPerDay       = 24
+	// activityRetention is how long activity is kept for the heatmap, spanning several weeks so each
+	// hour of the week is made up of more than one day.
+	activityRetention = 4 * 7 * hoursPerDay * time.Hour
+	// domainRetention is how long link posts are kept for the domain leaderboard.
+	domainRetention = 7 * hoursPerDay * time.Hour
+	// defaultTrendingDelta is the score gain between updates which makes a top post trending.
+	defaultTrendingDelta = 500
 )
 
 type Service struct {
-	client reddit.ListingFetcher
-	writer io.Writer
+	client    reddit.ListingFetcher
+	reporter  report.Reporter
+	analyzer  *sentiment.Analyzer
+	sentiment *sentiment.Tracker
+	store     *stats.Store
+	removals  *stats.Removals
+	activity  *stats.Activity
+	links     *links.Normalizer
+	domains   *stats.Domains
+	boards    *stats.Leaderboards
+	changes   bool
+	notifier  webhook.Notifier
+	trending  int
+	rules     *rules.Engine
+	arrivals  *stats.Arrivals
+	metrics   metrics.Recorder
+	now       func() time.Time
+
+	// pipeline sizes the stages by name; the stages are nil unless pipelined.
+	pipeline   map[string]pipeline.Config
+	parser     *pipeline.Stage[*listing]
+	aggregator *pipeline.Stage[*events]
+	sink       *pipeline.Stage[*report.Report]
+
+	// observeArrivals is called with the posts arriving between polls of the newest posts.
+	observeArrivals func(subreddit string, arrived int, elapsed time.Duration)
+
+	// forgets counts how often each subreddit was forgotten, so listings fetched beforehand are not
+	// aggregated afterwards. Recording statistics holds forgetMu for reading so a subreddit is not
+	// forgotten halfway through.
+	forgetMu sync.RWMutex
+	forgets  map[string]int
+}
+
+// Option customizes a Service.
+type Option func(s *Service)
+
+// WithSentimentAnalyzer replaces the analyzer backed by the embedded default lexicon.
+func WithSentimentAnalyzer(analyzer *sentiment.Analyzer) Option {
+	return func(s *Service) {
+		s.analyzer = analyzer
+	}
+}
+
+// WithStore shares a statistics store with other consumers, such as reporters.
+func WithStore(store *stats.Store) Option {
+	return func(s *Service) {
+		s.store = store
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+// WithLinkNormalizer replaces the normalizer used to group link posts by domain.
+func WithLinkNormalizer(normalizer *links.Normalizer) Option {
+	return func(s *Service) {
+		s.links = normalizer
+	}
+}
+
+// WithMetrics records the posts ingested per subreddit.
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(s *Service) {
+		s.metrics = recorder
+	}
+}
+
+// WithLeaderboardChanges additionally reports how the top posts and authors leaderboards changed
+// since their previous update.
+func WithLeaderboardChanges() Option {
+	return func(s *Service) {
+		s.changes = true
+	}
+}
+
+// WithNotifier sends webhook notifications when a post becomes a subreddit's top post, an author
+// enters the top authors, or a top post's score rises by at least the trending threshold.
+func WithNotifier(notifier webhook.Notifier) Option {
+	return func(s *Service) {
+		s.notifier = notifier
+	}
+}
+
+// WithTrendingThreshold sets the score gain between updates which makes a top post trending.
+func WithTrendingThreshold(delta int) Option {
+	return func(s *Service) {
+		s.trending = delta
+	}
+}
+
+// WithRules evaluates every ingested post and comment against alert rules, performing the actions
+// of those matched.
+func WithRules(engine *rules.Engine) Option {
+	return func(s *Service) {
+		s.rules = engine
+	}
+}
+
+// WithArrivalObserver calls observe after every poll of a subreddit's newest posts but the first,
+// with how many posts arrived since the previous poll and how long ago that was, e.g. to pace
+// polling to the subreddit's activity.
+func WithArrivalObserver(observe func(subreddit string, arrived int, elapsed time.Duration)) Option {
+	return func(s *Service) {
+		s.observeArrivals = observe
+	}
+}
+
+// NewService instantiates a Post service responsible for updating and reporting statistics. It
+// fails when no analyzer is provided and the embedded lexicon cannot be loaded.
+func NewService(client reddit.ListingFetcher, reporter report.Reporter, opts ...Option) (*Service, error) {
+	svc := &Service{
+		client:    client,
+		reporter:  reporter,
+		sentiment: sentiment.NewTracker(moodBucketWidth * moodBuckets),
+		store:     stats.NewStore(),
+		removals:  stats.NewRemovals(),
+		activity:  stats.NewActivity(activityRetention),
+		links:     links.NewNormalizer(links.DefaultShorteners(), nil),
+		domains:   stats.NewDomains(domainRetention),
+		boards:    stats.NewLeaderboards(),
+		arrivals:  stats.NewArrivals(),
+		trending:  defaultTrendingDelta,
+		metrics:   metrics.Nop{},
+		now:       time.Now,
+		forgets:   map[string]int{},
+	}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	if svc.analyzer == nil {
+		lexicon, err := sentiment.DefaultLexicon()
+		if err != nil {
+			return nil, err //nolint:wrapcheck // the sentiment package names the embedded lexicon.
+		}
+
+		svc.analyzer = sentiment.NewAnalyzer(lexicon)
+	}
+
+	if svc.pipeline != nil {
+		svc.newStages()
+	}
+
+	return svc, nil
 }
 
-// NewService instantiates a Post service responsible for updating and reporting statistics.
-func NewService(client reddit.ListingFetcher, writer io.Writer) *Service {
-	return &Service{client: client, writer: writer}
+// Forget drops the statistics gathered for a subreddit, e.g. once it is no longer tracked. Listings
+// of the subreddit fetched beforehand, by runs in flight or still queued, are discarded rather than
+// aggregated.
+func (s *Service) Forget(subreddit string) {
+	s.forgetMu.Lock()
+	defer s.forgetMu.Unlock()
+
+	s.forgets[subreddit]++
+	s.store.Forget(subreddit)
+	s.sentiment.Forget(subreddit)
+	s.removals.Forget(subreddit)
+	s.activity.Forget(subreddit)
+	s.domains.Forget(subreddit)
+	s.arrivals.Forget(subreddit)
+	s.boards.Remove(report.KindTopPostsChanges + ":" + subreddit)
+	s.boards.Remove(report.KindTopAuthorsChanges + ":" + subreddit)
 }
 
-// UpdateTopPosts fetches and reports the top posters for the provided subreddit.
-func (s *Service) UpdateTopPosts(ctx context.Context, subreddit string) error {
+// Then reports statistics fed by an update's listings once they have been aggregated, e.g.
+// UpdateDomains after UpdateTopPosts. When pipelined, it runs after the update has returned.
+type Then func(ctx context.Context) error
+
+// UpdateTopPosts fetches and reports the top posters for the provided subreddit, then runs each of
+// then in order.
+func (s *Service) UpdateTopPosts(ctx context.Context, subreddit string, then ...Then) error {
 	var (
 		logr  = logger.FromContext(ctx)
 		start = time.Now()
-		posts []*Post
-		err   error
+		posts int
 	)
 
 	defer func() {
-		logr.Debug("update top posts", "subreddit", subreddit, "dur", time.Since(start), "posts", len(posts))
+		logr.Debug("update top posts", "subreddit", subreddit, "dur", time.Since(start), "posts", posts)
 	}()
 
-	posts, err = s.fetchTopPosts(ctx, subreddit)
+	top, err := s.fetchTopPosts(ctx, subreddit)
 	if err != nil {
 		return fmt.Errorf("fetch top posts: %v: %w", subreddit, err)
 	}
 
-	out := make([]fmt.Stringer, len(posts))
-	for i, post := range posts {
-		out[i] = post
-	}
+	posts = len(top.children)
 
-	err = s.write(fmt.Sprintf("Top Posts (%s)", subreddit), out)
-	if err != nil {
-		return fmt.Errorf("write: %v: %w", subreddit, err)
+	top.then = func(ctx context.Context) error {
+		out := make([]report.Row, len(top.children))
+		entries := make([]stats.RankedEntry, len(top.children))
+
+		for i, kid := range top.children {
+			post := &Post{Name: kid.Post.Name, Title: kid.Post.Title, Ups: kid.Post.Ups, Permalink: kid.Post.Permalink}
+			out[i] = post
+			entries[i] = stats.RankedEntry{Key: post.Name, Label: post.Title, Score: post.Ups}
+		}
+
+		if err := s.write(ctx, report.KindTopPosts, "Top Posts", subreddit, postColumns(), out); err != nil {
+			return fmt.Errorf("write: %v: %w", subreddit, err)
+		}
+
+		if err := s.writeChanges(ctx, report.KindTopPostsChanges, "Top Posts Changes", subreddit, "title", entries); err != nil {
+			return fmt.Errorf("write: %v: %w", subreddit, err)
+		}
+
+		return runThen(ctx, then)
 	}
 
-	return nil
+	return s.ingest(ctx, top)
 }
 
 // UpdateTopNAuthors fetches all posts in a subreddit to determine the top N most active posters.
@@ -60,37 +260,57 @@ func (s *Service) UpdateTopNAuthors(ctx context.Context, subreddit string, num i
 	var (
 		logr  = logger.FromContext(ctx)
 		start = time.Now()
-		err   error
 	)
 
 	defer func() {
 		logr.Debug("update top n authors", "subreddit", subreddit, "dur", time.Since(start))
 	}()
 
-	counts, err := s.fetchAuthorPostCounts(ctx, subreddit)
+	all, err := s.fetchAllPosts(ctx, subreddit)
 	if err != nil {
 		return fmt.Errorf("fetch top authors: %v: %w", subreddit, err)
 	}
 
+	all.then = func(ctx context.Context) error {
+		return s.reportTopAuthors(ctx, subreddit, num, authorPostCounts(all.children))
+	}
+
+	return s.ingest(ctx, all)
+}
+
+// reportTopAuthors records the number of posts by each author and reports the num most active.
+func (s *Service) reportTopAuthors(ctx context.Context, subreddit string, num int, counts map[string]int) error {
+	s.store.SetAuthorCounts(subreddit, counts)
+
 	authors := make([]string, 0, len(counts))
 	for author := range counts {
 		authors = append(authors, author)
 	}
 
-	slices.SortStableFunc(authors, func(a, b string) int {
-		return cmp.Compare(counts[b], counts[a])
+	// Authors with as many posts are ranked by name, so the leaderboard does not reshuffle between
+	// updates.
+	slices.SortFunc(authors, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), strings.Compare(a, b))
 	})
 
-	authorPosts := make([]fmt.Stringer, 0)
+	authorPosts := make([]report.Row, 0)
+	entries := make([]stats.RankedEntry, 0)
+
 	for i, author := range authors {
 		authorPosts = append(authorPosts, &AuthorPosts{Author: author, Qty: counts[author]})
+		entries = append(entries, stats.RankedEntry{Key: author, Label: author, Score: counts[author]})
 
 		if i+1 == num {
 			break
 		}
 	}
 
-	err = s.write(fmt.Sprintf("Top %d Authors (%s)", num, subreddit), authorPosts)
+	err := s.write(ctx, report.KindTopAuthors, fmt.Sprintf("Top %d Authors", num), subreddit, authorPostsColumns(), authorPosts)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	err = s.writeChanges(ctx, report.KindTopAuthorsChanges, fmt.Sprintf("Top %d Authors Changes", num), subreddit, "author", entries)
 	if err != nil {
 		return fmt.Errorf("write: %w", err)
 	}
@@ -98,61 +318,585 @@ func (s *Service) UpdateTopNAuthors(ctx context.Context, subreddit string, num i
 	return nil
 }
 
-func (s *Service) fetchAuthorPostCounts(ctx context.Context, subreddit string) (map[string]int, error) {
-	listings, err := s.client.FetchAllListings(ctx, "/r/"+subreddit)
+// ReportAuthorOverlap reports the num authors active in the most tracked subreddits, along with the
+// Jaccard similarity of each pair of subreddits' authors and the authors bridging them. It relies on
+// the author counts gathered by UpdateTopNAuthors, and reports nothing until the authors of at least
+// two subreddits are known. A negative num is treated as zero.
+func (s *Service) ReportAuthorOverlap(ctx context.Context, num int) error {
+	num = max(num, 0)
+	known := 0
+
+	for _, summary := range s.store.Subreddits() {
+		if summary.Authors > 0 {
+			known++
+		}
+	}
+
+	if known < overlapSubreddits {
+		return nil
+	}
+
+	overlaps := s.store.AuthorOverlap(overlapSubreddits)
+
+	authors := make([]report.Row, 0, min(num, len(overlaps)))
+	for _, overlap := range overlaps[:min(num, len(overlaps))] {
+		authors = append(authors, &AuthorOverlap{
+			Author:     overlap.Author,
+			Subreddits: overlap.Subreddits,
+			Qty:        overlap.Posts,
+		})
+	}
+
+	err := s.write(ctx, report.KindAuthorOverlap, fmt.Sprintf("Top %d Cross-Subreddit Authors", num), "", authorOverlapColumns(), authors)
 	if err != nil {
-		return nil, fmt.Errorf("fetch all listings: %w", err)
+		return fmt.Errorf("write: %w", err)
 	}
 
-	counts := map[string]int{}
+	similarities := s.store.SubredditSimilarity()
 
-	for _, listing := range listings {
-		for _, kid := range listing.Segment.Children {
-			if _, ok := counts[kid.Post.Author]; !ok {
-				counts[kid.Post.Author] = 0
-			}
+	pairs := make([]report.Row, len(similarities))
+	for i, sim := range similarities {
+		pairs[i] = &SubredditSimilarity{
+			A:       sim.A,
+			B:       sim.B,
+			Jaccard: sim.Jaccard,
+			Bridges: sim.Bridges[:min(num, len(sim.Bridges))],
+			Shared:  len(sim.Bridges),
+		}
+	}
+
+	err = s.write(ctx, report.KindSubredditSimilarity, "Subreddit Author Similarity", "", subredditSimilarityColumns(), pairs)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSentiment scores the titles of new posts and the bodies of new comments, then reports the
+// subreddit's mood over time alongside its num most positive and most negative posts, and runs each
+// of then in order. The new posts are also compared with those of the previous call to detect
+// removals.
+func (s *Service) UpdateSentiment(ctx context.Context, subreddit string, num int, then ...Then) error {
+	var (
+		logr  = logger.FromContext(ctx)
+		start = time.Now()
+	)
+
+	defer func() {
+		logr.Debug("update sentiment", "subreddit", subreddit, "dur", time.Since(start))
+	}()
+
+	posts, err := s.fetch(ctx, subreddit, "/r/"+subreddit+"/new", sourceNew)
+	if err != nil {
+		return fmt.Errorf("score posts: %v: %w", subreddit, err)
+	}
+
+	if err = s.ingest(ctx, posts); err != nil {
+		return fmt.Errorf("score posts: %v: %w", subreddit, err)
+	}
+
+	comments, err := s.fetch(ctx, subreddit, "/r/"+subreddit+"/comments", sourceComments)
+	if err != nil {
+		return fmt.Errorf("score comments: %v: %w", subreddit, err)
+	}
+
+	comments.then = func(ctx context.Context) error {
+		if err := s.reportSentiment(ctx, subreddit, num); err != nil {
+			return err
+		}
 
-			counts[kid.Post.Author]++
+		return runThen(ctx, then)
+	}
+
+	if err = s.ingest(ctx, comments); err != nil {
+		return fmt.Errorf("score comments: %v: %w", subreddit, err)
+	}
+
+	return nil
+}
+
+// reportSentiment reports the subreddit's mood over time alongside its num most positive and most
+// negative posts.
+func (s *Service) reportSentiment(ctx context.Context, subreddit string, num int) error {
+	buckets := s.sentiment.Mood(subreddit, moodBucketWidth, moodBuckets)
+
+	moods := make([]report.Row, len(buckets))
+	for i, bucket := range buckets {
+		moods[i] = &Mood{Start: bucket.Start, Compound: bucket.Mean, Qty: bucket.Count}
+	}
+
+	if err := s.write(ctx, report.KindMood, "Mood", subreddit, moodColumns(), moods); err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
+	}
+
+	positive, negative := s.sentiment.Extremes(subreddit, sentiment.KindPost, num)
+
+	err := s.write(ctx, report.KindPositivePosts, "Most Positive Posts", subreddit, scoredPostColumns(), scoredPosts(positive))
+	if err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
+	}
+
+	err = s.write(ctx, report.KindNegativePosts, "Most Negative Posts", subreddit, scoredPostColumns(), scoredPosts(negative))
+	if err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
+	}
+
+	return nil
+}
+
+// runThen runs the reports following an update, stopping at the first which fails.
+func runThen(ctx context.Context, then []Then) error {
+	for _, next := range then {
+		if err := next(ctx); err != nil {
+			return err
 		}
 	}
 
-	return counts, nil
+	return nil
 }
 
-func (s *Service) fetchTopPosts(ctx context.Context, subreddit string) ([]*Post, error) {
-	listing, err := s.client.FetchListing(ctx, "/r/"+subreddit+"/top")
+// fetch fetches a listing of a subreddit for the source.
+func (s *Service) fetch(ctx context.Context, subreddit, path string, src source) (*listing, error) {
+	forgets := s.forgotten(subreddit)
+
+	fetched, err := s.client.FetchListing(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("fetch post listing: %w", err)
+		return nil, fmt.Errorf("fetch listing: %w", err)
 	}
 
-	posts := make([]*Post, 0, len(listing.Segment.Children))
-	for _, kid := range listing.Segment.Children {
-		posts = append(posts, &Post{
-			Title: kid.Post.Title,
-			Ups:   kid.Post.Ups,
+	return &listing{
+		subreddit: subreddit, source: src, fetched: s.now(), children: fetched.Segment.Children, forgets: forgets,
+	}, nil
+}
+
+// forgotten returns how often the subreddit was forgotten, to be compared once its listing is
+// aggregated.
+func (s *Service) forgotten(subreddit string) int {
+	s.forgetMu.RLock()
+	defer s.forgetMu.RUnlock()
+
+	return s.forgets[subreddit]
+}
+
+func scoredPosts(entries []sentiment.Entry) []report.Row {
+	out := make([]report.Row, len(entries))
+	for i, entry := range entries {
+		out[i] = &ScoredPost{Title: entry.Text, Compound: entry.Score.Compound}
+	}
+
+	return out
+}
+
+// UpdateDomains reports the num domains most linked to by the subreddit's link posts, with their
+// average score and top post. It relies on the posts ingested by UpdateTopPosts and UpdateSentiment.
+func (s *Service) UpdateDomains(ctx context.Context, subreddit string, num int) error {
+	leaderboard := s.domains.Leaderboard(subreddit, num)
+
+	out := make([]report.Row, len(leaderboard))
+	for i, stat := range leaderboard {
+		out[i] = &DomainStat{
+			Domain:   stat.Domain,
+			Qty:      stat.Posts,
+			AvgScore: stat.AvgScore,
+			Top:      &Post{Title: stat.Top.Title, Ups: stat.Top.Score},
+		}
+	}
+
+	if err := s.write(ctx, report.KindDomains, fmt.Sprintf("Top %d Linked Domains", num), subreddit, domainStatColumns(), out); err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
+	}
+
+	return nil
+}
+
+// postStats keeps the latest observation of each post so live statistics can be queried.
+func postStats(children reddit.Children) []stats.PostStat {
+	posts := make([]stats.PostStat, len(children))
+	for i, kid := range children {
+		posts[i] = stats.PostStat{
+			Name:      kid.Post.Name,
+			Title:     kid.Post.Title,
+			Author:    kid.Post.Author,
+			Permalink: kid.Post.Permalink,
+			Score:     kid.Post.Ups,
+			Comments:  kid.Post.NumComments,
+			Created:   created(kid.Post),
+		}
+	}
+
+	return posts
+}
+
+// countArrivals counts the newest posts not seen by the previous poll, returning nil when no
+// observer wants them or the subreddit has not been polled before.
+func (s *Service) countArrivals(subreddit string, at time.Time, children reddit.Children) *arrival {
+	if s.observeArrivals == nil {
+		return nil
+	}
+
+	names := make([]string, len(children))
+	for i, kid := range children {
+		names[i] = kid.Post.Name
+	}
+
+	posts, elapsed, ok := s.arrivals.Observe(subreddit, at, names)
+	if !ok {
+		return nil
+	}
+
+	return &arrival{posts: posts, elapsed: elapsed}
+}
+
+// linkPosts normalizes the destination of each link post to group it by domain. Links that cannot
+// be parsed are skipped.
+func (s *Service) linkPosts(ctx context.Context, subreddit string, children reddit.Children) []stats.LinkPost {
+	logr := logger.FromContext(ctx)
+
+	posts := make([]stats.LinkPost, 0, len(children))
+
+	for _, kid := range children {
+		if kid.Post.IsSelf || kid.Post.URL == "" {
+			continue
+		}
+
+		uri, err := s.links.Normalize(ctx, kid.Post.URL)
+		if err != nil {
+			logr.Debug("skip link post", "subreddit", subreddit, "name", kid.Post.Name, "err", err.Error())
+
+			continue
+		}
+
+		posts = append(posts, stats.LinkPost{
+			Name:    kid.Post.Name,
+			Title:   kid.Post.Title,
+			Domain:  uri.Hostname(),
+			URL:     uri.String(),
+			Score:   kid.Post.Ups,
+			Created: created(kid.Post),
 		})
 	}
 
-	return posts, nil
+	return posts
+}
+
+// UpdateActivity reports the subreddit's post and comment counts, and median post score, by day of
+// week and hour of day (UTC). It relies on the posts and comments ingested by UpdateSentiment.
+func (s *Service) UpdateActivity(ctx context.Context, subreddit string) error {
+	heatmap := s.activity.Heatmap(subreddit)
+
+	grids := []struct {
+		kind, title string
+		value       func(cell stats.HeatmapCell) float64
+	}{
+		{
+			kind: report.KindActivityPosts, title: "Posts by Day and Hour UTC",
+			value: func(cell stats.HeatmapCell) float64 { return float64(cell.Posts) },
+		},
+		{
+			kind: report.KindActivityComments, title: "Comments by Day and Hour UTC",
+			value: func(cell stats.HeatmapCell) float64 { return float64(cell.Comments) },
+		},
+		{
+			kind: report.KindActivityScores, title: "Median Post Score by Day and Hour UTC",
+			value: func(cell stats.HeatmapCell) float64 { return cell.MedianScore },
+		},
+	}
+
+	for _, grid := range grids {
+		rows := make([]report.Row, 0, len(heatmap.Cells))
+
+		for _, day := range heatmap.Cells {
+			row := &HeatmapRow{Day: day[0].Weekday.String()[:3], Hours: make([]float64, len(day))}
+			for hour, cell := range day {
+				row.Hours[hour] = grid.value(cell)
+			}
+
+			rows = append(rows, row)
+		}
+
+		if err := s.write(ctx, grid.kind, grid.title, subreddit, heatmapColumns(), rows); err != nil {
+			return fmt.Errorf("write: %v: %w", subreddit, err)
+		}
+	}
+
+	return nil
 }
 
-// Write prints the results of an update request since we are not persisting statistics,
-// nor the data gathered to formulate them.
-func (s *Service) write(title string, msg []fmt.Stringer) error {
-	buf := bytes.NewBufferString("\n")
-	buf.WriteString(title + "\n")
-	buf.WriteString(strings.Repeat("-", 80) + "\n")
+// ReportRemovals reports the subreddit's num most recent removals along with its authors' removal
+// rates. Removals are detected by comparing each poll of the newest posts made by UpdateSentiment
+// with the previous one.
+func (s *Service) ReportRemovals(ctx context.Context, subreddit string, num int) error {
+	recent := s.removals.Recent(subreddit, num)
+
+	removed := make([]report.Row, len(recent))
+	for i, removal := range recent {
+		removed[i] = &RemovedPost{
+			Title:    removal.Title,
+			Author:   removal.Author,
+			Kind:     string(removal.Kind),
+			Detected: removal.Detected,
+		}
+	}
+
+	if err := s.write(ctx, report.KindRecentRemovals, "Recent Removals", subreddit, removedPostColumns(), removed); err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
+	}
 
-	for _, post := range msg {
-		buf.WriteString(post.String())
+	err := s.write(ctx, report.KindAuthorRemovalRates, fmt.Sprintf("Top %d Removal Rates by Author", num), subreddit,
+		removalRateColumns("author"), removalRates(s.removals.AuthorRates(subreddit), num))
+	if err != nil {
+		return fmt.Errorf("write: %v: %w", subreddit, err)
 	}
 
-	buf.WriteString("\n")
+	return nil
+}
 
-	_, err := s.writer.Write(buf.Bytes())
+// ReportRemovalRates reports the removal rate of every subreddit.
+func (s *Service) ReportRemovalRates(ctx context.Context) error {
+	err := s.write(ctx, report.KindRemovalRates, "Removal Rates", "", removalRateColumns("subreddit"),
+		removalRates(s.removals.SubredditRates(), 0))
 	if err != nil {
-		return fmt.Errorf("error writing msg: %w", err)
+		return fmt.Errorf("write: %w", err)
 	}
 
 	return nil
 }
+
+func removalRates(rates []stats.RemovalRate, num int) []report.Row {
+	if num > 0 && len(rates) > num {
+		rates = rates[:num]
+	}
+
+	out := make([]report.Row, len(rates))
+	for i, rate := range rates {
+		out[i] = &RemovalRate{RemovalRate: rate}
+	}
+
+	return out
+}
+
+// fetchAllPosts fetches every page of a subreddit's posts as one listing.
+func (s *Service) fetchAllPosts(ctx context.Context, subreddit string) (*listing, error) {
+	forgets := s.forgotten(subreddit)
+
+	listings, err := s.client.FetchAllListings(ctx, "/r/"+subreddit)
+	if err != nil {
+		return nil, fmt.Errorf("fetch all listings: %w", err)
+	}
+
+	all := &listing{subreddit: subreddit, source: sourceAll, fetched: s.now(), forgets: forgets}
+	for _, page := range listings {
+		all.children = append(all.children, page.Segment.Children...)
+	}
+
+	return all, nil
+}
+
+func authorPostCounts(children reddit.Children) map[string]int {
+	counts := map[string]int{}
+	for _, kid := range children {
+		counts[kid.Post.Author]++
+	}
+
+	return counts
+}
+
+func (s *Service) fetchTopPosts(ctx context.Context, subreddit string) (*listing, error) {
+	forgets := s.forgotten(subreddit)
+
+	fetched, err := s.client.FetchListing(ctx, "/r/"+subreddit+"/top")
+	if err != nil {
+		return nil, fmt.Errorf("fetch post listing: %w", err)
+	}
+
+	return &listing{
+		subreddit: subreddit, source: sourceTop, fetched: s.now(), children: fetched.Segment.Children, forgets: forgets,
+	}, nil
+}
+
+// write reports the results of an update request, directly or through the report stage of the
+// pipeline when one is running.
+func (s *Service) write(ctx context.Context, kind, title, subreddit string, columns []string, rows []report.Row) error {
+	r := &report.Report{
+		Kind:        kind,
+		Title:       title,
+		Subreddit:   subreddit,
+		Columns:     columns,
+		Rows:        rows,
+		GeneratedAt: s.now(),
+	}
+
+	if s.sink == nil {
+		if err := s.reporter.Report(r); err != nil {
+			return fmt.Errorf("report: %w", err)
+		}
+
+		return nil
+	}
+
+	// A report dropped by the full stage is counted by the stage and skipped.
+	err := s.sink.Submit(ctx, r)
+
+	var dropped *pipeline.DroppedError
+	if errors.As(err, &dropped) {
+		logger.FromContext(ctx).Warn(err.Error(), "kind", kind, "subreddit", subreddit)
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("queue report: %w", err)
+	}
+
+	return nil
+}
+
+// writeChanges reports how a leaderboard changed since its previous update, if at all.
+func (s *Service) writeChanges(ctx context.Context, kind, title, subreddit, entry string, entries []stats.RankedEntry) error {
+	if !s.changes && s.notifier == nil {
+		return nil
+	}
+
+	board := kind + ":" + subreddit
+	seen := s.boards.Has(board)
+	changes := s.boards.Update(board, entries)
+
+	rows := make([]*LeaderboardChange, len(changes))
+	for i, change := range changes {
+		rows[i] = &LeaderboardChange{
+			Change:       string(change.Kind),
+			Rank:         change.Rank,
+			PreviousRank: change.PreviousRank,
+			Score:        change.Score,
+			ScoreDelta:   change.ScoreDelta,
+			Entry:        change.Label,
+		}
+	}
+
+	// Everything enters on a leaderboard's first update, which is not news worth notifying.
+	if seen {
+		s.notify(kind, subreddit, rows)
+	}
+
+	if !s.changes || len(rows) == 0 {
+		return nil
+	}
+
+	out := make([]report.Row, len(rows))
+	for i, row := range rows {
+		out[i] = row
+	}
+
+	return s.write(ctx, kind, title, subreddit, leaderboardChangeColumns(entry), out)
+}
+
+// notify raises webhook notifications for noteworthy leaderboard changes.
+func (s *Service) notify(kind, subreddit string, changes []*LeaderboardChange) {
+	if s.notifier == nil {
+		return
+	}
+
+	for _, change := range changes {
+		n := webhook.Notification{Subreddit: subreddit, Time: s.now(), Data: change}
+
+		switch {
+		case kind == report.KindTopPostsChanges && change.Rank == 1 && change.PreviousRank != 1:
+			n.Event = webhook.EventTopPost
+			n.Text = fmt.Sprintf("New #1 post in r/%s: %s (%d)", subreddit, change.Entry, change.Score)
+		case kind == report.KindTopPostsChanges && change.Rank > 0 && change.ScoreDelta >= s.trending:
+			n.Event = webhook.EventTrendingPost
+			n.Text = fmt.Sprintf("Trending in r/%s: %s (%d, %+d)", subreddit, change.Entry, change.Score, change.ScoreDelta)
+		case kind == report.KindTopAuthorsChanges && change.Change == string(stats.ChangeEntered):
+			n.Event = webhook.EventAuthorEnteredTop
+			n.Text = fmt.Sprintf("u/%s entered the top authors of r/%s at #%d (%d posts)",
+				change.Entry, subreddit, change.Rank, change.Score)
+		default:
+			continue
+		}
+
+		s.notifier.Notify(n)
+	}
+}
+
+// evaluateRules performs the actions of the alert rules matched by ingested posts or comments.
+// Matches requesting a report are reported together.
+func (s *Service) evaluateRules(ctx context.Context, subreddit, kind string, children reddit.Children) error {
+	if s.rules == nil {
+		return nil
+	}
+
+	items := make([]rules.Item, len(children))
+	for i, kid := range children {
+		items[i] = rules.Item{
+			Kind:      kind,
+			Name:      kid.Post.Name,
+			Subreddit: subreddit,
+			Author:    kid.Post.Author,
+			Title:     kid.Post.Title,
+			Body:      kid.Post.Body,
+			Permalink: kid.Post.Permalink,
+			Score:     kid.Post.Ups,
+			Comments:  kid.Post.NumComments,
+			Created:   created(kid.Post),
+		}
+
+		if kind == rules.KindPost {
+			items[i].Body = kid.Post.Selftext
+		}
+	}
+
+	var (
+		logr = logger.FromContext(ctx)
+		rows []report.Row
+	)
+
+	for _, match := range s.rules.Evaluate(items...) {
+		row := &RuleMatch{
+			Rule:      match.Rule,
+			Kind:      match.Item.Kind,
+			Author:    match.Item.Author,
+			Score:     match.Item.Score,
+			Text:      cmp.Or(match.Item.Title, match.Item.Body),
+			Permalink: match.Item.Permalink,
+		}
+
+		for _, action := range match.Actions {
+			switch action {
+			case rules.ActionLog:
+				logr.Info("rule matched", "rule", match.Rule, "subreddit", subreddit, "kind", row.Kind,
+					"name", match.Item.Name, "author", row.Author, "score", row.Score, "text", row.Text)
+			case rules.ActionWebhook:
+				if s.notifier == nil {
+					logr.Debug("rule webhook skipped, no webhooks configured", "rule", match.Rule)
+
+					continue
+				}
+
+				s.notifier.Notify(webhook.Notification{
+					Event:     webhook.EventRuleMatched,
+					Subreddit: subreddit,
+					Text:      fmt.Sprintf("Rule %s matched in r/%s: %s (%s, %d)", match.Rule, subreddit, row.Text, row.Author, row.Score),
+					Time:      s.now(),
+					Data:      match,
+				})
+			case rules.ActionReport:
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := s.write(ctx, report.KindRuleMatches, "Rule Matches", subreddit, ruleMatchColumns(), rows); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return nil
+}
+
+func created(post reddit.Post) time.Time {
+	return time.Unix(int64(post.CreatedUTC), 0).UTC()
+}
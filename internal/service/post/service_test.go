@@ -5,11 +5,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jqdurham/reddit/internal/links"
+	linkmocks "github.com/jqdurham/reddit/internal/links/mocks"
+	"github.com/jqdurham/reddit/internal/pipeline"
 	"github.com/jqdurham/reddit/internal/reddit"
 	"github.com/jqdurham/reddit/internal/reddit/mocks"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/rules"
 	"github.com/jqdurham/reddit/internal/service/post"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/jqdurham/reddit/internal/webhook"
+	webhookmocks "github.com/jqdurham/reddit/internal/webhook/mocks"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -52,6 +63,56 @@ var testListing = func() *reddit.Listing {
 	return listing
 }()
 
+var sentimentListingJSON = `{
+  "data": {
+    "after": "",
+    "children": [
+      {
+        "kind": "t3",
+        "data": {
+          "title": "Go is great",
+          "name": "t3_great",
+          "author": "gopher",
+          "created_utc": 1711972800
+        }
+      },
+      {
+        "kind": "t3",
+        "data": {
+          "title": "Build is broken",
+          "name": "t3_broken",
+          "author": "gopher",
+          "created_utc": 1711973400
+        }
+      }
+    ]
+  }
+}`
+
+var commentListingJSON = `{
+  "data": {
+    "after": "",
+    "children": [
+      {
+        "kind": "t1",
+        "data": {
+          "body": "Not bad at all",
+          "name": "t1_comment",
+          "author": "rustacean",
+          "created_utc": 1711976400
+        }
+      }
+    ]
+  }
+}`
+
+func makeListing(data string) *reddit.Listing {
+	listing := &reddit.Listing{}
+	_ = json.Unmarshal([]byte(data), listing)
+
+	return listing
+}
+
 var errMockedFailure = errors.New("mocked failure")
 
 func TestService_UpdateTopNAuthors(t *testing.T) {
@@ -139,8 +200,9 @@ func TestService_UpdateTopNAuthors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			buf := &bytes.Buffer{}
-			s := post.NewService(tt.fields.client(t), buf)
-			err := s.UpdateTopNAuthors(tt.args.ctx, tt.args.subreddit, tt.args.num)
+			s, err := post.NewService(tt.fields.client(t), report.NewText(buf))
+			require.NoError(t, err)
+			err = s.UpdateTopNAuthors(tt.args.ctx, tt.args.subreddit, tt.args.num)
 			if tt.errMsg != "" {
 				require.EqualError(t, err, tt.errMsg)
 				require.Empty(t, buf)
@@ -215,8 +277,329 @@ func TestService_UpdateTopPosts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			buf := &bytes.Buffer{}
-			s := post.NewService(tt.fields.client(t), buf)
-			err := s.UpdateTopPosts(tt.args.ctx, tt.args.subreddit)
+			s, err := post.NewService(tt.fields.client(t), report.NewText(buf))
+			require.NoError(t, err)
+			err = s.UpdateTopPosts(tt.args.ctx, tt.args.subreddit)
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				require.Empty(t, buf)
+
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.output, buf.String())
+		})
+	}
+}
+
+func TestService_UpdateTopPosts_LeaderboardChanges(t *testing.T) {
+	t.Parallel()
+
+	movedListingJSON := `{"data": {"children": [
+		{"data": {"title": "Greatest shortstop", "name": "The Wizard", "ups": 100000, "author": "Ozzie Smith"}},
+		{"data": {"title": "Unit test title", "name": "Unit test name", "ups": 99999, "author": "John Doe"}},
+		{"data": {"title": "Rally squirrel", "name": "Squirrel", "ups": 2011, "author": "Tony La Russa"}}
+	]}}`
+
+	m := mocks.NewListingFetcher(t)
+	m.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil).Once()
+	m.On("FetchListing", context.Background(), "/r/cardinals/top").Return(makeListing(movedListingJSON), nil).Once()
+
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(m, report.NewText(buf), post.WithLeaderboardChanges())
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.Contains(t, buf.String(), "Top Posts Changes (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"entered #1 (99999) - Unit test title \n"+
+		"entered #2 (1111) - Greatest shortstop \n"+
+		"entered #3 (11) - Opening Day Backflips \n\n")
+
+	buf.Reset()
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.Contains(t, buf.String(), "Top Posts Changes (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"moved   #2 -> #1 (100000, +98889) - Greatest shortstop \n"+
+		"moved   #1 -> #2 (99999, +0) - Unit test title \n"+
+		"entered #3 (2011) - Rally squirrel \n"+
+		"left    #3 (11) - Opening Day Backflips \n\n")
+}
+
+func TestService_Forget(t *testing.T) {
+	t.Parallel()
+
+	var s *post.Service
+
+	m := mocks.NewListingFetcher(t)
+	// The subreddit is untracked while its top posts are being fetched.
+	m.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil).
+		Run(func(mock.Arguments) { s.Forget("cardinals") }).Once()
+	m.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil).Once()
+
+	var (
+		buf   = &bytes.Buffer{}
+		store = stats.NewStore()
+		err   error
+	)
+
+	s, err = post.NewService(m, report.NewText(buf), post.WithStore(store))
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.False(t, store.HasSubreddit("cardinals"))
+	require.Empty(t, buf.String())
+
+	// Once tracked again, listings fetched afterwards are aggregated.
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.True(t, store.HasSubreddit("cardinals"))
+	require.Contains(t, buf.String(), "Top Posts (cardinals)\n")
+}
+
+func TestService_Pipeline(t *testing.T) {
+	t.Parallel()
+
+	m := mocks.NewListingFetcher(t)
+	m.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil)
+	m.On("FetchListing", context.Background(), "/r/cardinals/new").Return(makeListing(sentimentListingJSON), nil)
+	m.On("FetchListing", context.Background(), "/r/cardinals/comments").Return(makeListing(commentListingJSON), nil)
+
+	stage := pipeline.Config{Workers: 2, Queue: 4, Policy: pipeline.PolicyBlock}
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(m, report.NewText(buf), post.WithPipeline(map[string]pipeline.Config{
+		post.StageParse: stage, post.StageAggregate: stage, post.StageReport: stage,
+	}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		s.Run(ctx)
+	}()
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals", func(ctx context.Context) error {
+		return s.UpdateDomains(ctx, "cardinals", 10)
+	}))
+	require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 1, func(ctx context.Context) error {
+		return s.UpdateActivity(ctx, "cardinals")
+	}))
+
+	// Reports are written once the queued listings are drained.
+	cancel()
+	<-done
+
+	// The reports following an update wait for its listings to be aggregated.
+	require.Less(t, strings.Index(buf.String(), "Top Posts (cardinals)"), strings.Index(buf.String(), "Linked Domains (cardinals)"))
+	require.Less(t, strings.Index(buf.String(), "Mood (cardinals)"), strings.Index(buf.String(), "Posts by Day and Hour UTC (cardinals)"))
+
+	require.Contains(t, buf.String(), "Top Posts (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(99999) - Unit test title \n")
+	require.Contains(t, buf.String(), "Most Positive Posts (cardinals)\n")
+	require.Less(t, strings.Index(buf.String(), "Top Posts (cardinals)"), strings.Index(buf.String(), "Mood (cardinals)"))
+}
+
+func TestService_Notifications(t *testing.T) {
+	t.Parallel()
+
+	risingListingJSON := `{"data": {"children": [
+		{"data": {"title": "Greatest shortstop", "name": "The Wizard", "ups": 200000, "author": "Ozzie Smith"}},
+		{"data": {"title": "Unit test title", "name": "Unit test name", "ups": 101000, "author": "John Doe"}},
+		{"data": {"title": "Rally squirrel", "name": "Squirrel", "ups": 2011, "author": "Tony La Russa"}}
+	]}}`
+
+	var (
+		now      = time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC)
+		client   = mocks.NewListingFetcher(t)
+		notifier = webhookmocks.NewNotifier(t)
+	)
+
+	client.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil).Once()
+	client.On("FetchListing", context.Background(), "/r/cardinals/top").Return(makeListing(risingListingJSON), nil).Once()
+	client.On("FetchAllListings", context.Background(), "/r/cardinals").
+		Return([]*reddit.Listing{testListing}, nil).Once()
+	client.On("FetchAllListings", context.Background(), "/r/cardinals").
+		Return([]*reddit.Listing{makeListing(risingListingJSON)}, nil).Once()
+
+	notifier.On("Notify", webhook.Notification{
+		Event:     webhook.EventTopPost,
+		Subreddit: "cardinals",
+		Text:      "New #1 post in r/cardinals: Greatest shortstop (200000)",
+		Time:      now,
+		Data: &post.LeaderboardChange{
+			Change: "moved", Rank: 1, PreviousRank: 2, Score: 200000, ScoreDelta: 198889, Entry: "Greatest shortstop",
+		},
+	}).Once()
+	notifier.On("Notify", webhook.Notification{
+		Event:     webhook.EventTrendingPost,
+		Subreddit: "cardinals",
+		Text:      "Trending in r/cardinals: Unit test title (101000, +1001)",
+		Time:      now,
+		Data: &post.LeaderboardChange{
+			Change: "moved", Rank: 2, PreviousRank: 1, Score: 101000, ScoreDelta: 1001, Entry: "Unit test title",
+		},
+	}).Once()
+	notifier.On("Notify", webhook.Notification{
+		Event:     webhook.EventAuthorEnteredTop,
+		Subreddit: "cardinals",
+		Text:      "u/Tony La Russa entered the top authors of r/cardinals at #3 (1 posts)",
+		Time:      now,
+		Data: &post.LeaderboardChange{
+			Change: "entered", Rank: 3, Score: 1, Entry: "Tony La Russa",
+		},
+	}).Once()
+
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(client, report.NewText(buf), post.WithNotifier(notifier), post.WithTrendingThreshold(1000),
+		post.WithClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	// The first update of each leaderboard establishes it without notifying.
+	for range 2 {
+		require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+		require.NoError(t, s.UpdateTopNAuthors(context.Background(), "cardinals", 3))
+	}
+
+	require.NotContains(t, buf.String(), "Changes")
+}
+
+func TestService_Rules(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := rules.Parse(strings.NewReader(`watchlist legends = Ozzie Smith
+rule huge: score > 50000 => webhook
+rule legend: author in legends => report cooldown 1h`))
+	require.NoError(t, err)
+
+	var (
+		now      = time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC)
+		client   = mocks.NewListingFetcher(t)
+		notifier = webhookmocks.NewNotifier(t)
+		engine   = rules.NewEngine(parsed, rules.WithClock(func() time.Time { return now }))
+	)
+
+	client.On("FetchListing", context.Background(), "/r/cardinals/top").Return(testListing, nil).Twice()
+	notifier.On("Notify", webhook.Notification{
+		Event:     webhook.EventRuleMatched,
+		Subreddit: "cardinals",
+		Text:      "Rule huge matched in r/cardinals: Unit test title (John Doe, 99999)",
+		Time:      now,
+		Data: rules.Match{
+			Rule:    "huge",
+			Actions: []rules.Action{rules.ActionWebhook},
+			Item: rules.Item{
+				Kind: rules.KindPost, Name: "Unit test name", Subreddit: "cardinals", Author: "John Doe",
+				Title: "Unit test title", Score: 99999, Created: time.Unix(0, 0).UTC(),
+			},
+		},
+	}).Once()
+
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(client, report.NewText(buf), post.WithRules(engine), post.WithNotifier(notifier),
+		post.WithClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.Equal(t, "\n"+
+		"Rule Matches (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"[legend] (1111) - Greatest shortstop (Ozzie Smith) \n\n"+
+		"\n"+
+		"Top Posts (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(99999) - Unit test title \n"+
+		"(1111) - Greatest shortstop \n"+
+		"(11) - Opening Day Backflips \n\n", buf.String())
+
+	// Matches are neither repeated for the same posts nor, while cooling down, for new ones.
+	buf.Reset()
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "cardinals"))
+	require.NotContains(t, buf.String(), "Rule Matches")
+}
+
+func TestService_UpdateSentiment(t *testing.T) {
+	t.Parallel()
+	type fields struct {
+		client func(t *testing.T) reddit.ListingFetcher
+	}
+	type args struct {
+		ctx       context.Context
+		subreddit string
+		num       int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		errMsg string
+		output string
+	}{
+		{
+			name: "Writes mood and extremes",
+			fields: fields{
+				client: func(t *testing.T) reddit.ListingFetcher {
+					t.Helper()
+					m := mocks.NewListingFetcher(t)
+					m.On("FetchListing", context.Background(),
+						"/r/cardinals/new").Return(makeListing(sentimentListingJSON), nil)
+					m.On("FetchListing", context.Background(),
+						"/r/cardinals/comments").Return(makeListing(commentListingJSON), nil)
+
+					return m
+				},
+			},
+			args: args{
+				ctx:       context.Background(),
+				subreddit: "cardinals",
+				num:       5,
+			},
+			output: "\n" +
+				"Mood (cardinals)\n" +
+				"--------------------------------------------------------------------------------\n" +
+				"(+0.102) - 2024-04-01 12:00:00 (2) \n" +
+				"(+0.431) - 2024-04-01 13:00:00 (1) \n\n" +
+				"\n" +
+				"Most Positive Posts (cardinals)\n" +
+				"--------------------------------------------------------------------------------\n" +
+				"(+0.625) - Go is great \n\n" +
+				"\n" +
+				"Most Negative Posts (cardinals)\n" +
+				"--------------------------------------------------------------------------------\n" +
+				"(-0.421) - Build is broken \n\n",
+		},
+		{
+			name: "Handles fetcher error getting comments",
+			fields: fields{
+				client: func(t *testing.T) reddit.ListingFetcher {
+					t.Helper()
+					m := mocks.NewListingFetcher(t)
+					m.On("FetchListing", context.Background(),
+						"/r/cubs/new").Return(makeListing(sentimentListingJSON), nil)
+					m.On("FetchListing", context.Background(),
+						"/r/cubs/comments").Return(nil, errMockedFailure)
+
+					return m
+				},
+			},
+			args: args{
+				ctx:       context.Background(),
+				subreddit: "cubs",
+			},
+			errMsg: "score comments: cubs: fetch listing: mocked failure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			s, err := post.NewService(tt.fields.client(t), report.NewText(buf))
+			require.NoError(t, err)
+			err = s.UpdateSentiment(tt.args.ctx, tt.args.subreddit, tt.args.num)
 			if tt.errMsg != "" {
 				require.EqualError(t, err, tt.errMsg)
 				require.Empty(t, buf)
@@ -228,3 +611,214 @@ func TestService_UpdateTopPosts(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ArrivalObserver(t *testing.T) {
+	t.Parallel()
+
+	type observation struct {
+		subreddit string
+		arrived   int
+		elapsed   time.Duration
+	}
+
+	var (
+		now      = time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC)
+		client   = mocks.NewListingFetcher(t)
+		observed []observation
+	)
+
+	client.On("FetchListing", context.Background(), "/r/cardinals/new").Return(makeListing(sentimentListingJSON), nil).Twice()
+	client.On("FetchListing", context.Background(), "/r/cardinals/new").Return(testListing, nil).Once()
+	client.On("FetchListing", context.Background(), "/r/cardinals/comments").Return(makeListing(commentListingJSON), nil)
+
+	s, err := post.NewService(client, report.NewText(&bytes.Buffer{}),
+		post.WithClock(func() time.Time { return now }),
+		post.WithArrivalObserver(func(subreddit string, arrived int, elapsed time.Duration) {
+			observed = append(observed, observation{subreddit: subreddit, arrived: arrived, elapsed: elapsed})
+		}))
+	require.NoError(t, err)
+
+	for range 3 {
+		require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 5))
+
+		now = now.Add(5 * time.Minute)
+	}
+
+	require.Equal(t, []observation{
+		{subreddit: "cardinals", arrived: 0, elapsed: 5 * time.Minute},
+		{subreddit: "cardinals", arrived: len(testListing.Segment.Children), elapsed: 5 * time.Minute},
+	}, observed)
+}
+
+func TestService_ReportAuthorOverlap(t *testing.T) {
+	t.Parallel()
+
+	m := mocks.NewListingFetcher(t)
+	m.On("FetchAllListings", context.Background(), "/r/cardinals").Return([]*reddit.Listing{testListing}, nil)
+	m.On("FetchAllListings", context.Background(), "/r/stlouis").Return([]*reddit.Listing{
+		makeListing(sentimentListingJSON),
+		makeListing(`{"data": {"children": [{"data": {"name": "t3_ozzie", "author": "Ozzie Smith"}}]}}`),
+	}, nil)
+
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(m, report.NewText(buf))
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateTopNAuthors(context.Background(), "cardinals", 10))
+
+	// Overlap needs the authors of two subreddits.
+	buf.Reset()
+	require.NoError(t, s.ReportAuthorOverlap(context.Background(), 10))
+	require.Empty(t, buf.String())
+
+	require.NoError(t, s.UpdateTopNAuthors(context.Background(), "stlouis", 10))
+
+	buf.Reset()
+
+	require.NoError(t, s.ReportAuthorOverlap(context.Background(), 10))
+	require.Equal(t, "\n"+
+		"Top 10 Cross-Subreddit Authors\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(3) - Ozzie Smith [cardinals, stlouis] \n\n"+
+		"\n"+
+		"Subreddit Author Similarity\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(0.333) - cardinals ~ stlouis, 1 shared [Ozzie Smith] \n\n", buf.String())
+
+	// A negative count reports no authors rather than panicking.
+	require.NoError(t, s.ReportAuthorOverlap(context.Background(), -1))
+}
+
+func TestService_ReportRemovals(t *testing.T) {
+	t.Parallel()
+
+	removedListingJSON := `{"data": {"children": [
+		{"data": {"title": "Go is great", "name": "t3_great", "author": "gopher", "created_utc": 1711972800}},
+		{"data": {"title": "Build is broken", "name": "t3_broken", "author": "gopher", "selftext": "[removed]",
+		  "created_utc": 1711973400}}
+	]}}`
+
+	m := mocks.NewListingFetcher(t)
+	m.On("FetchListing", context.Background(), "/r/cardinals/new").
+		Return(makeListing(sentimentListingJSON), nil).Once()
+	m.On("FetchListing", context.Background(), "/r/cardinals/new").
+		Return(makeListing(removedListingJSON), nil).Once()
+	m.On("FetchListing", context.Background(), "/r/cardinals/comments").
+		Return(makeListing(commentListingJSON), nil).Twice()
+
+	buf := &bytes.Buffer{}
+	now := time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC)
+	s, err := post.NewService(m, report.NewText(buf), post.WithClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	// Removals are detected from the newest posts polled for sentiment.
+	require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 5))
+	require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 5))
+
+	buf.Reset()
+
+	require.NoError(t, s.ReportRemovals(context.Background(), "cardinals", 5))
+	require.NoError(t, s.ReportRemovalRates(context.Background()))
+	require.Equal(t, "\n"+
+		"Recent Removals (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(removed) - 2024-04-01 14:00:00 - Build is broken (gopher) \n\n"+
+		"\n"+
+		"Top 5 Removal Rates by Author (cardinals)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(50.0%) - gopher 1/2 \n\n"+
+		"\n"+
+		"Removal Rates\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(50.0%) - cardinals 1/2 \n\n", buf.String())
+}
+
+func TestService_UpdateActivity(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(t *testing.T) reddit.ListingFetcher {
+		t.Helper()
+		m := mocks.NewListingFetcher(t)
+		m.On("FetchListing", context.Background(), "/r/cardinals/new").Return(makeListing(sentimentListingJSON), nil)
+		m.On("FetchListing", context.Background(), "/r/cardinals/comments").Return(makeListing(commentListingJSON), nil)
+
+		return m
+	}
+
+	t.Run("Writes text heatmaps", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		s, err := post.NewService(newClient(t), report.NewText(buf))
+		require.NoError(t, err)
+		require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 1))
+
+		buf.Reset()
+
+		require.NoError(t, s.UpdateActivity(context.Background(), "cardinals"))
+		require.Contains(t, buf.String(), "Posts by Day and Hour UTC (cardinals)\n"+
+			"--------------------------------------------------------------------------------\n"+
+			"Sun    0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0   0 \n"+
+			"Mon    0   0   0   0   0   0   0   0   0   0   0   0   2   0   0   0   0   0   0   0   0   0   0   0 \n")
+		require.Contains(t, buf.String(), "Comments by Day and Hour UTC (cardinals)\n")
+		require.Contains(t, buf.String(), "Median Post Score by Day and Hour UTC (cardinals)\n")
+	})
+
+	t.Run("Writes machine-readable heatmaps", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &bytes.Buffer{}
+		s, err := post.NewService(newClient(t), report.NewNDJSON(buf))
+		require.NoError(t, err)
+		require.NoError(t, s.UpdateSentiment(context.Background(), "cardinals", 1))
+
+		buf.Reset()
+
+		require.NoError(t, s.UpdateActivity(context.Background(), "cardinals"))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 21)
+
+		monday := struct {
+			Report    string         `json:"report"`
+			Subreddit string         `json:"subreddit"`
+			Row       map[string]any `json:"row"`
+		}{}
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &monday))
+		require.Equal(t, report.KindActivityPosts, monday.Report)
+		require.Equal(t, "cardinals", monday.Subreddit)
+		require.Equal(t, "Mon", monday.Row["day"])
+		require.InDelta(t, 2, monday.Row["12"], 0)
+	})
+}
+
+func TestService_UpdateDomains(t *testing.T) {
+	t.Parallel()
+
+	linkListingJSON := `{"data": {"children": [
+		{"data": {"title": "Go 1.22 released", "name": "t3_rel", "ups": 500, "url": "https://go.dev/blog/go1.22?utm_source=reddit"}},
+		{"data": {"title": "Range over func", "name": "t3_range", "ups": 100, "url": "https://bit.ly/range"}},
+		{"data": {"title": "Ask: generics?", "name": "t3_ask", "ups": 50, "is_self": true,
+		  "url": "https://www.reddit.com/r/golang/comments/ask"}}
+	]}}`
+
+	m := mocks.NewListingFetcher(t)
+	m.On("FetchListing", context.Background(), "/r/golang/top").Return(makeListing(linkListingJSON), nil)
+
+	resolver := linkmocks.NewResolver(t)
+	resolver.On("Resolve", context.Background(), "https://bit.ly/range").Return("https://m.go.dev/blog/range", nil)
+
+	buf := &bytes.Buffer{}
+	s, err := post.NewService(m, report.NewText(buf), post.WithLinkNormalizer(links.NewNormalizer([]string{"bit.ly"}, resolver)))
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateTopPosts(context.Background(), "golang"))
+
+	buf.Reset()
+
+	require.NoError(t, s.UpdateDomains(context.Background(), "golang", 5))
+	require.Equal(t, "\n"+
+		"Top 5 Linked Domains (golang)\n"+
+		"--------------------------------------------------------------------------------\n"+
+		"(2) - go.dev avg 300.0, top (500) Go 1.22 released \n\n", buf.String())
+}
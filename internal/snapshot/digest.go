@@ -0,0 +1,90 @@
+package snapshot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/report"
+)
+
+// Digest keeps the latest report of each kind and subreddit and periodically writes them to a
+// Markdown file sized to be posted to Reddit.
+type Digest struct {
+	path  string
+	title string
+
+	mu sync.Mutex
+	// latest maps kind and subreddit -> latest report; keys lists them in the order first received.
+	latest map[string]*report.Report
+	keys   []string
+}
+
+// NewDigest creates a Digest titled title written to path.
+func NewDigest(path, title string) *Digest {
+	return &Digest{path: path, title: title, latest: map[string]*report.Report{}}
+}
+
+// Report replaces the previous report of the same kind and subreddit.
+func (d *Digest) Report(r *report.Report) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Reports without a kind are told apart by title.
+	key := cmp.Or(r.Kind, r.Title) + "\x00" + r.Subreddit
+
+	if _, ok := d.latest[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+
+	d.latest[key] = r
+
+	return nil
+}
+
+// Run rewrites the file every interval until the context is cancelled, then writes it once more so
+// the final statistics are kept. Failed writes are logged and retried on the next interval.
+func (d *Digest) Run(ctx context.Context, interval time.Duration) {
+	logr := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := d.Write(); err != nil {
+				logr.Error(err.Error())
+			}
+
+			return
+		case <-ticker.C:
+			if err := d.Write(); err != nil {
+				logr.Warn(err.Error())
+			}
+		}
+	}
+}
+
+// Write renders the latest reports and atomically replaces the file.
+func (d *Digest) Write() error {
+	d.mu.Lock()
+
+	reports := make([]*report.Report, len(d.keys))
+	for i, key := range d.keys {
+		reports[i] = d.latest[key]
+	}
+
+	d.mu.Unlock()
+
+	doc := report.MarkdownDocument(d.title, reports, report.MarkdownLimit)
+
+	if err := writeAtomic(d.path, []byte(doc)); err != nil {
+		return fmt.Errorf("write markdown digest: %w", err)
+	}
+
+	return nil
+}
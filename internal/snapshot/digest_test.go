@@ -0,0 +1,61 @@
+package snapshot_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/snapshot"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	ups   int
+	title string
+}
+
+func (r row) String() string {
+	return fmt.Sprintf("(%d) - %s \n", r.ups, r.title)
+}
+
+func (r row) Values() []any {
+	return []any{r.ups, r.title}
+}
+
+func TestDigest_Write(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now    = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		path   = filepath.Join(t.TempDir(), "digest.md")
+		digest = snapshot.NewDigest(path, "Weekly Digest")
+	)
+
+	topPosts := func(sub, title string, at time.Time) *report.Report {
+		return &report.Report{
+			Kind: "top-posts", Title: "Top Posts", Subreddit: sub, Columns: []string{"ups", "title"},
+			Rows: []report.Row{row{ups: 1, title: title}}, GeneratedAt: at,
+		}
+	}
+
+	require.NoError(t, digest.Report(topPosts("golang", "Old", now.Add(-time.Hour))))
+	require.NoError(t, digest.Report(topPosts("rust", "Crab", now.Add(-time.Hour))))
+	require.NoError(t, digest.Report(topPosts("golang", "New", now)))
+	require.NoError(t, digest.Write())
+
+	out, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# Weekly Digest\n\n"+
+		"*Generated 2024-04-01 12:00:00 UTC*\n\n"+
+		"## Top Posts ([r/golang](https://www.reddit.com/r/golang/))\n\n"+
+		"| ups | title |\n"+
+		"|--:|:--|\n"+
+		"| 1 | New |\n\n"+
+		"## Top Posts ([r/rust](https://www.reddit.com/r/rust/))\n\n"+
+		"| ups | title |\n"+
+		"|--:|:--|\n"+
+		"| 1 | Crab |\n\n", string(out))
+}
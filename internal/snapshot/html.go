@@ -0,0 +1,204 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+	"github.com/jqdurham/reddit/internal/stats"
+)
+
+const (
+	redditURL = "https://www.reddit.com"
+	// defaultTopN is how many posts and authors are listed per subreddit.
+	defaultTopN = 10
+	fileMode    = 0o644
+)
+
+var (
+	//go:embed report.html.tmpl
+	reportTemplate string
+	//go:embed style.css
+	styleSheet string
+)
+
+// HTML renders the current statistics to a self-contained HTML file.
+type HTML struct {
+	path  string
+	store *stats.Store
+	tmpl  *template.Template
+	topN  int
+	now   func() time.Time
+}
+
+// Option customizes an HTML snapshot.
+type Option func(h *HTML)
+
+// WithTopN sets how many posts and authors are listed per subreddit.
+func WithTopN(num int) Option {
+	return func(h *HTML) {
+		h.topN = num
+	}
+}
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(h *HTML) {
+		h.now = now
+	}
+}
+
+// NewHTML creates an HTML snapshot of the store written to path.
+func NewHTML(path string, store *stats.Store, opts ...Option) *HTML {
+	h := &HTML{
+		path:  path,
+		store: store,
+		tmpl: template.Must(template.New("report").Funcs(template.FuncMap{
+			"inc": func(i int) int { return i + 1 },
+		}).Parse(reportTemplate)),
+		topN: defaultTopN,
+		now:  time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Run rewrites the file every interval until the context is cancelled, then writes it once more so
+// the final statistics are kept. Failed writes are logged and retried on the next interval.
+func (h *HTML) Run(ctx context.Context, interval time.Duration) {
+	logr := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := h.Write(); err != nil {
+				logr.Error(err.Error())
+			}
+
+			return
+		case <-ticker.C:
+			if err := h.Write(); err != nil {
+				logr.Warn(err.Error())
+			}
+		}
+	}
+}
+
+// Write renders the statistics and atomically replaces the file, so readers never observe a
+// partially written report.
+func (h *HTML) Write() error {
+	buf := &bytes.Buffer{}
+	if err := h.tmpl.Execute(buf, h.page()); err != nil {
+		return fmt.Errorf("render html report: %w", err)
+	}
+
+	if err := writeAtomic(h.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("write html report: %w", err)
+	}
+
+	return nil
+}
+
+type page struct {
+	CSS        template.CSS
+	Generated  time.Time
+	Subreddits []subreddit
+}
+
+type subreddit struct {
+	stats.SubredditSummary
+	URL        string
+	TopPosts   []post
+	TopAuthors []author
+}
+
+type post struct {
+	stats.PostStat
+	URL, AuthorURL string
+	Sparkline      *Sparkline
+}
+
+type author struct {
+	stats.AuthorStat
+	URL string
+}
+
+func (h *HTML) page() page {
+	out := page{
+		CSS:       template.CSS(styleSheet), //nolint:gosec // embedded at build time, not user input.
+		Generated: h.now().UTC(),
+	}
+
+	for _, summary := range h.store.Subreddits() {
+		summary.Updated = summary.Updated.UTC()
+		sub := subreddit{SubredditSummary: summary, URL: redditURL + "/r/" + url.PathEscape(summary.Name) + "/"}
+
+		for _, stat := range h.store.TopPosts(summary.Name, stats.Query{Limit: h.topN, Sort: stats.SortScore}) {
+			sub.TopPosts = append(sub.TopPosts, post{
+				PostStat:  stat,
+				URL:       redditURL + stat.Permalink,
+				AuthorURL: userURL(stat.Author),
+				Sparkline: newSparkline(h.store.ScoreHistory(summary.Name, stat.Name)),
+			})
+		}
+
+		for _, stat := range h.store.TopAuthors(summary.Name, stats.Query{Limit: h.topN, Sort: stats.SortPosts}) {
+			sub.TopAuthors = append(sub.TopAuthors, author{AuthorStat: stat, URL: userURL(stat.Author)})
+		}
+
+		out.Subreddits = append(out.Subreddits, sub)
+	}
+
+	return out
+}
+
+func userURL(name string) string {
+	return redditURL + "/user/" + url.PathEscape(name) + "/"
+}
+
+// writeAtomic writes data to a temporary file beside path and renames it into place.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	// Removing the temporary file fails harmlessly once it has been renamed.
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	if err = os.Chmod(tmp.Name(), fileMode); err != nil {
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	return nil
+}
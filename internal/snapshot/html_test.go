@@ -0,0 +1,75 @@
+package snapshot_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/snapshot"
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML_Write(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now   = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		dir   = t.TempDir()
+		path  = filepath.Join(dir, "report.html")
+		store = stats.NewStore()
+	)
+
+	store.SetAuthorCounts("golang", map[string]int{"gopher": 3})
+
+	for i, score := range []int{10, 40, 25} {
+		store.RecordPosts("golang", now.Add(time.Duration(i-2)*time.Hour), stats.PostStat{
+			Name: "t3_a", Title: "Go 1.22 <released>", Author: "gopher",
+			Permalink: "/r/golang/comments/a/go_122/", Score: score, Comments: 7, Created: now.Add(-3 * time.Hour),
+		})
+	}
+
+	store.RecordPosts("golang", now, stats.PostStat{Name: "t3_b", Title: "New", Author: "newbie", Score: 1})
+
+	html := snapshot.NewHTML(path, store, snapshot.WithTopN(5), snapshot.WithClock(func() time.Time { return now }))
+	require.NoError(t, html.Write())
+
+	out, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	doc := string(out)
+	require.Contains(t, doc, "<p>Generated 2024-04-01 12:00:00 UTC</p>")
+	require.Contains(t, doc, `<h2><a href="https://www.reddit.com/r/golang/">r/golang</a></h2>`)
+	require.Contains(t, doc, `<td><a href="https://www.reddit.com/r/golang/comments/a/go_122/">Go 1.22 &lt;released&gt;</a></td>`)
+	require.Contains(t, doc, `<td><a href="https://www.reddit.com/user/gopher/">u/gopher</a></td>`)
+	require.Contains(t, doc, `aria-label="score 10 to 25"><polyline points="2.0,22.0 60.0,2.0 118.0,12.0"/></svg>`)
+	// A single observation has no trend.
+	require.Contains(t, doc, "<td>&mdash;</td>")
+	require.Contains(t, doc, "svg.sparkline polyline")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files are renamed into place")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
+
+func TestHTML_Run(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "report.html")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The report is written once more on shutdown.
+	snapshot.NewHTML(path, stats.NewStore()).Run(ctx, time.Hour)
+
+	out, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "Waiting for statistics...")
+}
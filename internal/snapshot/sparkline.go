@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jqdurham/reddit/internal/stats"
+)
+
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 24
+	// sparklinePad keeps the stroke inside the viewBox at the extremes.
+	sparklinePad = 2
+	// minSparklinePoints is the fewest observations which make a line.
+	minSparklinePoints = 2
+)
+
+// Sparkline is a small inline chart of a post's score over time.
+type Sparkline struct {
+	Width, Height int
+	First, Last   int
+	// Points is the polyline's coordinate list.
+	Points string
+}
+
+// newSparkline plots the history, returning nil when there are too few observations for a trend.
+func newSparkline(history []stats.ScorePoint) *Sparkline {
+	if len(history) < minSparklinePoints {
+		return nil
+	}
+
+	lowest, highest := history[0].Score, history[0].Score
+	for _, point := range history {
+		lowest, highest = min(lowest, point.Score), max(highest, point.Score)
+	}
+
+	var (
+		points = make([]string, len(history))
+		plotW  = float64(sparklineWidth - 2*sparklinePad)
+		plotH  = float64(sparklineHeight - 2*sparklinePad)
+		span   = float64(highest - lowest)
+	)
+
+	for i, point := range history {
+		x := sparklinePad + plotW*float64(i)/float64(len(history)-1)
+
+		// A flat history is drawn through the middle.
+		y := sparklinePad + plotH/2
+		if span > 0 {
+			y = sparklinePad + plotH*(1-float64(point.Score-lowest)/span)
+		}
+
+		points[i] = strconv.FormatFloat(x, 'f', 1, 64) + "," + strconv.FormatFloat(y, 'f', 1, 64)
+	}
+
+	return &Sparkline{
+		Width:  sparklineWidth,
+		Height: sparklineHeight,
+		First:  history[0].Score,
+		Last:   history[len(history)-1].Score,
+		Points: strings.Join(points, " "),
+	}
+}
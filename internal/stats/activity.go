@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const hoursPerDay = 24
+
+// ItemKind identifies whether activity came from a post or a comment.
+type ItemKind string
+
+const (
+	ItemPost    ItemKind = "post"
+	ItemComment ItemKind = "comment"
+)
+
+// ActivityItem is a post or comment placed into the activity heatmap by its creation time.
+type ActivityItem struct {
+	Name    string
+	Kind    ItemKind
+	Created time.Time
+	Score   int
+}
+
+// HeatmapCell aggregates the activity created during one hour of one day of the week (UTC).
+type HeatmapCell struct {
+	Weekday     time.Weekday `json:"weekday"`
+	Hour        int          `json:"hour"`
+	Posts       int          `json:"posts"`
+	Comments    int          `json:"comments"`
+	MedianScore float64      `json:"median_score"`
+}
+
+// Heatmap is a day-of-week by hour-of-day grid of a subreddit's activity.
+type Heatmap struct {
+	Subreddit string                      `json:"subreddit"`
+	Cells     [7][hoursPerDay]HeatmapCell `json:"cells"`
+}
+
+// Activity aggregates post and comment creation times per subreddit. Items are keyed by name so
+// repeated observations update the score without double counting.
+type Activity struct {
+	// retention is how long before a subreddit's newest item older items are kept; zero keeps
+	// every item.
+	retention time.Duration
+
+	mu    sync.RWMutex
+	items map[string]map[string]ActivityItem
+}
+
+// NewActivity creates an empty Activity tracker keeping items created up to retention before the
+// newest item of their subreddit. Zero keeps every item.
+func NewActivity(retention time.Duration) *Activity {
+	return &Activity{retention: retention, items: map[string]map[string]ActivityItem{}}
+}
+
+// Record adds or updates items for a subreddit, and drops the items which fell out of retention.
+func (a *Activity) Record(subreddit string, items ...ActivityItem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.items[subreddit]; !ok {
+		a.items[subreddit] = map[string]ActivityItem{}
+	}
+
+	for _, item := range items {
+		a.items[subreddit][item.Name] = item
+	}
+
+	if a.retention <= 0 {
+		return
+	}
+
+	var newest time.Time
+	for _, item := range a.items[subreddit] {
+		if item.Created.After(newest) {
+			newest = item.Created
+		}
+	}
+
+	cutoff := newest.Add(-a.retention)
+	for name, item := range a.items[subreddit] {
+		if item.Created.Before(cutoff) {
+			delete(a.items[subreddit], name)
+		}
+	}
+}
+
+// Forget drops everything recorded for a subreddit, e.g. once it is no longer tracked.
+func (a *Activity) Forget(subreddit string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.items, subreddit)
+}
+
+// Heatmap builds the activity grid for a subreddit. The median score of a cell considers posts only.
+func (a *Activity) Heatmap(subreddit string) Heatmap {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var (
+		heatmap = Heatmap{Subreddit: subreddit}
+		scores  [7][hoursPerDay][]int
+	)
+
+	for day := range heatmap.Cells {
+		for hour := range heatmap.Cells[day] {
+			heatmap.Cells[day][hour] = HeatmapCell{Weekday: time.Weekday(day), Hour: hour}
+		}
+	}
+
+	for _, item := range a.items[subreddit] {
+		created := item.Created.UTC()
+		cell := &heatmap.Cells[created.Weekday()][created.Hour()]
+
+		switch item.Kind {
+		case ItemPost:
+			cell.Posts++
+			scores[created.Weekday()][created.Hour()] = append(scores[created.Weekday()][created.Hour()], item.Score)
+		case ItemComment:
+			cell.Comments++
+		}
+	}
+
+	for day := range scores {
+		for hour := range scores[day] {
+			heatmap.Cells[day][hour].MedianScore = median(scores[day][hour])
+		}
+	}
+
+	return heatmap
+}
+
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	slices.Sort(values)
+
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return float64(values[mid])
+	}
+
+	return float64(values[mid-1]+values[mid]) / 2
+}
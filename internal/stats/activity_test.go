@@ -0,0 +1,51 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivity_Heatmap(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2024, 4, 1, 12, 15, 0, 0, time.UTC)
+
+	activity := stats.NewActivity(0)
+	activity.Record("golang",
+		stats.ActivityItem{Name: "t3_a", Kind: stats.ItemPost, Created: monday, Score: 10},
+		stats.ActivityItem{Name: "t3_b", Kind: stats.ItemPost, Created: monday.Add(time.Minute), Score: 30},
+		stats.ActivityItem{Name: "t3_c", Kind: stats.ItemPost, Created: monday.Add(2 * time.Minute), Score: 2},
+		stats.ActivityItem{Name: "t1_d", Kind: stats.ItemComment, Created: monday.Add(24 * time.Hour), Score: 99},
+	)
+	// Observing a post again updates its score rather than counting it twice.
+	activity.Record("golang", stats.ActivityItem{Name: "t3_c", Kind: stats.ItemPost, Created: monday.Add(2 * time.Minute), Score: 50})
+
+	heatmap := activity.Heatmap("golang")
+
+	require.Equal(t, "golang", heatmap.Subreddit)
+	require.Equal(t, stats.HeatmapCell{Weekday: time.Monday, Hour: 12, Posts: 3, MedianScore: 30},
+		heatmap.Cells[time.Monday][12])
+	require.Equal(t, stats.HeatmapCell{Weekday: time.Tuesday, Hour: 12, Comments: 1},
+		heatmap.Cells[time.Tuesday][12])
+	require.Equal(t, stats.HeatmapCell{Weekday: time.Sunday, Hour: 0}, heatmap.Cells[time.Sunday][0])
+}
+
+func TestActivity_Retention(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2024, 4, 1, 12, 15, 0, 0, time.UTC)
+
+	activity := stats.NewActivity(7 * 24 * time.Hour)
+	activity.Record("golang", stats.ActivityItem{Name: "t3_a", Kind: stats.ItemPost, Created: monday, Score: 10})
+	// A post created over a week after the first pushes it out of retention.
+	activity.Record("golang", stats.ActivityItem{Name: "t3_b", Kind: stats.ItemPost, Created: monday.Add(8 * 24 * time.Hour), Score: 20})
+
+	heatmap := activity.Heatmap("golang")
+
+	require.Equal(t, stats.HeatmapCell{Weekday: time.Tuesday, Hour: 12, Posts: 1, MedianScore: 20},
+		heatmap.Cells[time.Tuesday][12])
+	require.Equal(t, stats.HeatmapCell{Weekday: time.Monday, Hour: 12}, heatmap.Cells[time.Monday][12])
+}
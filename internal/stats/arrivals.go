@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// Arrivals counts the posts appearing in each subreddit's newest posts between polls.
+type Arrivals struct {
+	mu    sync.Mutex
+	polls map[string]poll
+}
+
+// poll is the previous poll of a subreddit's newest posts.
+type poll struct {
+	at    time.Time
+	names map[string]struct{}
+}
+
+// NewArrivals creates an empty Arrivals.
+func NewArrivals() *Arrivals {
+	return &Arrivals{polls: map[string]poll{}}
+}
+
+// Observe records the names of a subreddit's newest posts polled at at. It returns how many were
+// not in the previous poll and how long ago that was, or ok false on the first poll. A count equal
+// to the number of names suggests posts were missed between polls.
+func (a *Arrivals) Observe(subreddit string, at time.Time, names []string) (int, time.Duration, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current := poll{at: at, names: make(map[string]struct{}, len(names))}
+	for _, name := range names {
+		current.names[name] = struct{}{}
+	}
+
+	previous, ok := a.polls[subreddit]
+	a.polls[subreddit] = current
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	arrived := 0
+
+	for name := range current.names {
+		if _, seen := previous.names[name]; !seen {
+			arrived++
+		}
+	}
+
+	return arrived, at.Sub(previous.at), true
+}
+
+// Forget drops the previous poll of a subreddit, e.g. once it is no longer tracked.
+func (a *Arrivals) Forget(subreddit string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.polls, subreddit)
+}
@@ -0,0 +1,35 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrivals_Observe(t *testing.T) {
+	t.Parallel()
+
+	var (
+		base     = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		arrivals = stats.NewArrivals()
+	)
+
+	_, _, ok := arrivals.Observe("golang", base, []string{"t3_a", "t3_b", "t3_c"})
+	require.False(t, ok)
+
+	arrived, elapsed, ok := arrivals.Observe("golang", base.Add(5*time.Minute), []string{"t3_d", "t3_e", "t3_a", "t3_b"})
+	require.True(t, ok)
+	require.Equal(t, 2, arrived)
+	require.Equal(t, 5*time.Minute, elapsed)
+
+	arrived, elapsed, ok = arrivals.Observe("golang", base.Add(6*time.Minute), []string{"t3_d", "t3_e", "t3_a"})
+	require.True(t, ok)
+	require.Zero(t, arrived)
+	require.Equal(t, time.Minute, elapsed)
+
+	// Subreddits are tracked separately.
+	_, _, ok = arrivals.Observe("rust", base, []string{"t3_d"})
+	require.False(t, ok)
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// LinkPost is a non-self post linking to an external domain.
+type LinkPost struct {
+	Name, Title, Domain, URL string
+	Score                    int
+	Created                  time.Time
+}
+
+// DomainStat summarizes the posts linking to a domain.
+type DomainStat struct {
+	Domain   string
+	Posts    int
+	AvgScore float64
+	Top      LinkPost
+}
+
+// Domains aggregates link posts per subreddit by their normalized domain. Posts are keyed by name
+// so repeated observations update the score without double counting.
+type Domains struct {
+	// retention is how long before a subreddit's newest post older posts are kept; zero keeps
+	// every post.
+	retention time.Duration
+
+	mu    sync.RWMutex
+	posts map[string]map[string]LinkPost
+}
+
+// NewDomains creates an empty Domains tracker keeping posts created up to retention before the
+// newest post of their subreddit. Zero keeps every post.
+func NewDomains(retention time.Duration) *Domains {
+	return &Domains{retention: retention, posts: map[string]map[string]LinkPost{}}
+}
+
+// Record adds or updates link posts for a subreddit, and drops the posts which fell out of
+// retention.
+func (d *Domains) Record(subreddit string, posts ...LinkPost) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.posts[subreddit]; !ok {
+		d.posts[subreddit] = map[string]LinkPost{}
+	}
+
+	for _, post := range posts {
+		d.posts[subreddit][post.Name] = post
+	}
+
+	if d.retention <= 0 {
+		return
+	}
+
+	var newest time.Time
+	for _, post := range d.posts[subreddit] {
+		if post.Created.After(newest) {
+			newest = post.Created
+		}
+	}
+
+	cutoff := newest.Add(-d.retention)
+	for name, post := range d.posts[subreddit] {
+		if post.Created.Before(cutoff) {
+			delete(d.posts[subreddit], name)
+		}
+	}
+}
+
+// Forget drops everything recorded for a subreddit, e.g. once it is no longer tracked.
+func (d *Domains) Forget(subreddit string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.posts, subreddit)
+}
+
+// Leaderboard returns up to num domains linked from a subreddit, ordered by post count and then
+// average score. A negative num is treated as zero.
+func (d *Domains) Leaderboard(subreddit string, num int) []DomainStat {
+	num = max(num, 0)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	byDomain := map[string]*DomainStat{}
+
+	for _, post := range d.posts[subreddit] {
+		stat, ok := byDomain[post.Domain]
+		if !ok {
+			stat = &DomainStat{Domain: post.Domain, Top: post}
+			byDomain[post.Domain] = stat
+		}
+
+		stat.Posts++
+		stat.AvgScore += float64(post.Score)
+
+		if post.Score > stat.Top.Score || (post.Score == stat.Top.Score && post.Name < stat.Top.Name) {
+			stat.Top = post
+		}
+	}
+
+	out := make([]DomainStat, 0, len(byDomain))
+	for _, stat := range byDomain {
+		stat.AvgScore /= float64(stat.Posts)
+		out = append(out, *stat)
+	}
+
+	slices.SortFunc(out, func(a, b DomainStat) int {
+		return cmp.Or(
+			cmp.Compare(b.Posts, a.Posts),
+			cmp.Compare(b.AvgScore, a.AvgScore),
+			cmp.Compare(a.Domain, b.Domain),
+		)
+	})
+
+	return out[:min(num, len(out))]
+}
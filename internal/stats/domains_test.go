@@ -0,0 +1,51 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomains_Leaderboard(t *testing.T) {
+	t.Parallel()
+
+	var (
+		gh1 = stats.LinkPost{Name: "t3_a", Title: "Release", Domain: "github.com", Score: 10}
+		gh2 = stats.LinkPost{Name: "t3_b", Title: "Proposal", Domain: "github.com", Score: 50}
+		yt  = stats.LinkPost{Name: "t3_c", Title: "Talk", Domain: "youtube.com", Score: 100}
+		blg = stats.LinkPost{Name: "t3_d", Title: "Blog", Domain: "go.dev", Score: 5}
+	)
+
+	domains := stats.NewDomains(0)
+	domains.Record("golang", gh1, gh2, yt, blg)
+	// Observing a post again updates its score rather than counting it twice.
+	gh1.Score = 20
+	domains.Record("golang", gh1)
+
+	require.Equal(t, []stats.DomainStat{
+		{Domain: "github.com", Posts: 2, AvgScore: 35, Top: gh2},
+		{Domain: "youtube.com", Posts: 1, AvgScore: 100, Top: yt},
+	}, domains.Leaderboard("golang", 2))
+
+	require.Empty(t, domains.Leaderboard("python", 10))
+	require.Empty(t, domains.Leaderboard("golang", -1))
+}
+
+func TestDomains_Retention(t *testing.T) {
+	t.Parallel()
+
+	var (
+		created = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		old     = stats.LinkPost{Name: "t3_a", Domain: "github.com", Score: 10, Created: created}
+		fresh   = stats.LinkPost{Name: "t3_b", Domain: "go.dev", Score: 5, Created: created.Add(8 * 24 * time.Hour)}
+	)
+
+	domains := stats.NewDomains(7 * 24 * time.Hour)
+	domains.Record("golang", old)
+	// A post created over a week after the first pushes it out of retention.
+	domains.Record("golang", fresh)
+
+	require.Equal(t, []stats.DomainStat{{Domain: "go.dev", Posts: 1, AvgScore: 5, Top: fresh}}, domains.Leaderboard("golang", 10))
+}
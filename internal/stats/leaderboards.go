@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"sync"
+)
+
+// ChangeKind describes how an entry's position on a leaderboard changed.
+type ChangeKind string
+
+const (
+	ChangeEntered ChangeKind = "entered"
+	ChangeLeft    ChangeKind = "left"
+	ChangeMoved   ChangeKind = "moved"
+	ChangeScore   ChangeKind = "score"
+)
+
+// RankedEntry is an entry of a leaderboard, identified by Key and described by Label.
+type RankedEntry struct {
+	Key, Label string
+	Score      int
+}
+
+// RankChange describes an entry which entered, left, moved on or changed score on a leaderboard.
+// Ranks start at 1; Rank is zero for entries which left and PreviousRank is zero for entries which
+// entered.
+type RankChange struct {
+	Kind         ChangeKind
+	Key, Label   string
+	Rank         int
+	PreviousRank int
+	Score        int
+	ScoreDelta   int
+}
+
+// Leaderboards retains the latest ranking of each leaderboard so updates can be reduced to their
+// changes.
+type Leaderboards struct {
+	mu     sync.Mutex
+	boards map[string][]RankedEntry
+}
+
+// NewLeaderboards creates an empty Leaderboards tracker.
+func NewLeaderboards() *Leaderboards {
+	return &Leaderboards{boards: map[string][]RankedEntry{}}
+}
+
+// Has reports whether a leaderboard has been updated before.
+func (l *Leaderboards) Has(board string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.boards[board]
+
+	return ok
+}
+
+// Update replaces a leaderboard's ranking, in rank order, and returns how it changed. Entries which
+// are present in both rankings are reported in their new rank order, followed by those which left
+// in their previous rank order. On a leaderboard's first update every entry has entered.
+func (l *Leaderboards) Update(board string, entries []RankedEntry) []RankChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	previous := l.boards[board]
+	l.boards[board] = append([]RankedEntry(nil), entries...)
+
+	previousRanks := make(map[string]int, len(previous))
+	for i, entry := range previous {
+		previousRanks[entry.Key] = i + 1
+	}
+
+	var changes []RankChange
+
+	current := make(map[string]struct{}, len(entries))
+
+	for i, entry := range entries {
+		current[entry.Key] = struct{}{}
+
+		change := RankChange{Key: entry.Key, Label: entry.Label, Rank: i + 1, Score: entry.Score}
+
+		prevRank, ok := previousRanks[entry.Key]
+		if !ok {
+			change.Kind = ChangeEntered
+			changes = append(changes, change)
+
+			continue
+		}
+
+		change.PreviousRank = prevRank
+		change.ScoreDelta = entry.Score - previous[prevRank-1].Score
+
+		switch {
+		case prevRank != change.Rank:
+			change.Kind = ChangeMoved
+		case change.ScoreDelta != 0:
+			change.Kind = ChangeScore
+		default:
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	for i, entry := range previous {
+		if _, ok := current[entry.Key]; ok {
+			continue
+		}
+
+		changes = append(changes, RankChange{
+			Kind: ChangeLeft, Key: entry.Key, Label: entry.Label, PreviousRank: i + 1, Score: entry.Score,
+		})
+	}
+
+	return changes
+}
+
+// Remove drops a leaderboard, so its next update is treated as its first.
+func (l *Leaderboards) Remove(board string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.boards, board)
+}
@@ -0,0 +1,63 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboards_Update(t *testing.T) {
+	t.Parallel()
+
+	boards := stats.NewLeaderboards()
+
+	// Leaderboards are tracked independently.
+	require.Len(t, boards.Update("top-posts:rust", []stats.RankedEntry{{Key: "t3_z", Label: "Z", Score: 1}}), 1)
+
+	steps := []struct {
+		name    string
+		entries []stats.RankedEntry
+		want    []stats.RankChange
+	}{
+		{
+			name:    "First update enters every entry",
+			entries: []stats.RankedEntry{{Key: "t3_a", Label: "A", Score: 30}, {Key: "t3_b", Label: "B", Score: 20}},
+			want: []stats.RankChange{
+				{Kind: stats.ChangeEntered, Key: "t3_a", Label: "A", Rank: 1, Score: 30},
+				{Kind: stats.ChangeEntered, Key: "t3_b", Label: "B", Rank: 2, Score: 20},
+			},
+		},
+		{
+			name:    "Unchanged",
+			entries: []stats.RankedEntry{{Key: "t3_a", Label: "A", Score: 30}, {Key: "t3_b", Label: "B", Score: 20}},
+		},
+		{
+			name:    "Rank moves",
+			entries: []stats.RankedEntry{{Key: "t3_b", Label: "B", Score: 45}, {Key: "t3_a", Label: "A", Score: 30}},
+			want: []stats.RankChange{
+				{Kind: stats.ChangeMoved, Key: "t3_b", Label: "B", Rank: 1, PreviousRank: 2, Score: 45, ScoreDelta: 25},
+				{Kind: stats.ChangeMoved, Key: "t3_a", Label: "A", Rank: 2, PreviousRank: 1, Score: 30},
+			},
+		},
+		{
+			name:    "Entering and leaving",
+			entries: []stats.RankedEntry{{Key: "t3_c", Label: "C", Score: 50}, {Key: "t3_b", Label: "B", Score: 46}},
+			want: []stats.RankChange{
+				{Kind: stats.ChangeEntered, Key: "t3_c", Label: "C", Rank: 1, Score: 50},
+				{Kind: stats.ChangeMoved, Key: "t3_b", Label: "B", Rank: 2, PreviousRank: 1, Score: 46, ScoreDelta: 1},
+				{Kind: stats.ChangeLeft, Key: "t3_a", Label: "A", PreviousRank: 2, Score: 30},
+			},
+		},
+		{
+			name:    "Score delta",
+			entries: []stats.RankedEntry{{Key: "t3_c", Label: "C", Score: 55}, {Key: "t3_b", Label: "B", Score: 46}},
+			want: []stats.RankChange{
+				{Kind: stats.ChangeScore, Key: "t3_c", Label: "C", Rank: 1, PreviousRank: 1, Score: 55, ScoreDelta: 5},
+			},
+		},
+	}
+	for _, step := range steps {
+		require.Equal(t, step.want, boards.Update("top-posts:golang", step.entries), step.name)
+	}
+}
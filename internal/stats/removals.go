@@ -0,0 +1,279 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	removedContent = "[removed]"
+	// maxRecentRemovals is how many of a subreddit's removals are kept for Recent.
+	maxRecentRemovals = 100
+)
+
+// RemovalKind describes how a post was taken down.
+type RemovalKind string
+
+const (
+	// RemovalVanished is a post that disappeared from a listing it should still appear in.
+	RemovalVanished RemovalKind = "vanished"
+	// RemovalDeleted is a post whose author or body flipped to [deleted].
+	RemovalDeleted RemovalKind = "deleted"
+	// RemovalRemoved is a post whose body flipped to [removed], typically by a moderator.
+	RemovalRemoved RemovalKind = "removed"
+)
+
+// Observation is a post as seen in a single poll of a listing.
+type Observation struct {
+	Name, Title, Author, Body string
+	Created                   time.Time
+}
+
+// Removal records a post transitioning out of view between two polls.
+type Removal struct {
+	Name, Title, Author string
+	Kind                RemovalKind
+	Created, Detected   time.Time
+}
+
+// RemovalRate is the share of observed posts that were later removed.
+type RemovalRate struct {
+	Key               string
+	Removed, Observed int
+}
+
+// Rate returns the fraction of observed posts that were removed.
+func (r RemovalRate) Rate() float64 {
+	if r.Observed == 0 {
+		return 0
+	}
+
+	return float64(r.Removed) / float64(r.Observed)
+}
+
+// Removals detects removed and deleted posts by diffing successive snapshots of a subreddit's
+// listing, keyed by each post's fullname.
+type Removals struct {
+	mu sync.RWMutex
+	// seen holds the last observation of each post still within the listing window.
+	seen map[string]map[string]Observation
+	// flagged holds the creation time of posts already reported as removed, so they are counted
+	// once, until they age out of the listing window.
+	flagged map[string]map[string]time.Time
+	// removals holds each subreddit's most recent removals, oldest first.
+	removals map[string][]Removal
+	// removed and observed count each author's removed and observed posts per subreddit.
+	removed, observed map[string]map[string]int
+}
+
+// NewRemovals creates an empty Removals tracker.
+func NewRemovals() *Removals {
+	return &Removals{
+		seen:     map[string]map[string]Observation{},
+		flagged:  map[string]map[string]time.Time{},
+		removals: map[string][]Removal{},
+		removed:  map[string]map[string]int{},
+		observed: map[string]map[string]int{},
+	}
+}
+
+// Observe compares a snapshot of a subreddit's listing with the previous one and returns any newly
+// detected removals. Posts that drop out of the listing because they are older than everything in
+// the snapshot are treated as having aged out rather than removed. An empty snapshot carries no
+// window to compare against and is ignored.
+func (r *Removals) Observe(subreddit string, at time.Time, snapshot []Observation) []Removal {
+	if len(snapshot) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.seen[subreddit]; !ok {
+		r.seen[subreddit] = map[string]Observation{}
+		r.flagged[subreddit] = map[string]time.Time{}
+		r.removed[subreddit] = map[string]int{}
+		r.observed[subreddit] = map[string]int{}
+	}
+
+	var (
+		prev     = r.seen[subreddit]
+		current  = make(map[string]Observation, len(snapshot))
+		detected []Removal
+		cutoff   time.Time
+	)
+
+	for i, obs := range snapshot {
+		if i == 0 || obs.Created.Before(cutoff) {
+			cutoff = obs.Created
+		}
+
+		if _, ok := r.flagged[subreddit][obs.Name]; ok {
+			continue
+		}
+
+		before, ok := prev[obs.Name]
+		if !ok {
+			if !isGone(obs) {
+				r.observed[subreddit][obs.Author]++
+				current[obs.Name] = obs
+			}
+
+			continue
+		}
+
+		if kind, gone := transition(obs); gone {
+			detected = append(detected, r.flag(subreddit, before, kind, at))
+
+			continue
+		}
+
+		current[obs.Name] = obs
+	}
+
+	for name, before := range prev {
+		if _, ok := current[name]; ok {
+			continue
+		}
+
+		if _, ok := r.flagged[subreddit][name]; ok {
+			continue
+		}
+
+		if !before.Created.Before(cutoff) {
+			detected = append(detected, r.flag(subreddit, before, RemovalVanished, at))
+		}
+	}
+
+	r.seen[subreddit] = current
+
+	// Flagged posts older than the listing window cannot show up again.
+	for name, created := range r.flagged[subreddit] {
+		if created.Before(cutoff) {
+			delete(r.flagged[subreddit], name)
+		}
+	}
+
+	return detected
+}
+
+// Forget drops everything recorded for a subreddit, e.g. once it is no longer tracked.
+func (r *Removals) Forget(subreddit string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.seen, subreddit)
+	delete(r.flagged, subreddit)
+	delete(r.removals, subreddit)
+	delete(r.removed, subreddit)
+	delete(r.observed, subreddit)
+}
+
+// Recent returns up to num of the most recently detected removals in a subreddit, newest first. A
+// negative num is treated as zero.
+func (r *Removals) Recent(subreddit string, num int) []Removal {
+	num = max(num, 0)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := r.removals[subreddit]
+	out := make([]Removal, 0, min(num, len(all)))
+
+	for i := len(all) - 1; i >= 0 && len(out) < num; i-- {
+		out = append(out, all[i])
+	}
+
+	return out
+}
+
+// SubredditRates returns the removal rate of every observed subreddit, highest first.
+func (r *Removals) SubredditRates() []RemovalRate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]RemovalRate, 0, len(r.observed))
+
+	for subreddit, authors := range r.observed {
+		rate := RemovalRate{Key: subreddit}
+		for _, qty := range authors {
+			rate.Observed += qty
+		}
+
+		for _, qty := range r.removed[subreddit] {
+			rate.Removed += qty
+		}
+
+		out = append(out, rate)
+	}
+
+	sortRates(out)
+
+	return out
+}
+
+// AuthorRates returns the removal rate of authors with at least one removal in a subreddit,
+// highest first.
+func (r *Removals) AuthorRates(subreddit string) []RemovalRate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]RemovalRate, 0, len(r.removed[subreddit]))
+	for author, qty := range r.removed[subreddit] {
+		out = append(out, RemovalRate{Key: author, Removed: qty, Observed: r.observed[subreddit][author]})
+	}
+
+	sortRates(out)
+
+	return out
+}
+
+func (r *Removals) flag(subreddit string, before Observation, kind RemovalKind, at time.Time) Removal {
+	removal := Removal{
+		Name:     before.Name,
+		Title:    before.Title,
+		Author:   before.Author,
+		Kind:     kind,
+		Created:  before.Created,
+		Detected: at,
+	}
+
+	r.flagged[subreddit][before.Name] = before.Created
+	r.removed[subreddit][before.Author]++
+
+	r.removals[subreddit] = append(r.removals[subreddit], removal)
+	if excess := len(r.removals[subreddit]) - maxRecentRemovals; excess > 0 {
+		r.removals[subreddit] = slices.Delete(r.removals[subreddit], 0, excess)
+	}
+
+	return removal
+}
+
+func transition(obs Observation) (RemovalKind, bool) {
+	switch {
+	case obs.Body == removedContent:
+		return RemovalRemoved, true
+	case obs.Author == deletedAuthor || obs.Body == deletedAuthor:
+		return RemovalDeleted, true
+	default:
+		return "", false
+	}
+}
+
+func isGone(obs Observation) bool {
+	_, gone := transition(obs)
+
+	return gone
+}
+
+func sortRates(rates []RemovalRate) {
+	slices.SortFunc(rates, func(a, b RemovalRate) int {
+		return cmp.Or(
+			cmp.Compare(b.Rate(), a.Rate()),
+			cmp.Compare(b.Removed, a.Removed),
+			cmp.Compare(a.Key, b.Key),
+		)
+	})
+}
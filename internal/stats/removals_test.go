@@ -0,0 +1,55 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovals_Observe(t *testing.T) {
+	t.Parallel()
+
+	var (
+		base   = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		first  = base.Add(time.Minute)
+		second = base.Add(2 * time.Minute)
+		old    = stats.Observation{Name: "t3_old", Title: "Old", Author: "alice", Created: base}
+		kept   = stats.Observation{Name: "t3_kept", Title: "Kept", Author: "alice", Created: base.Add(time.Minute)}
+		mod    = stats.Observation{Name: "t3_mod", Title: "Spam", Author: "bob", Created: base.Add(2 * time.Minute)}
+		gone   = stats.Observation{Name: "t3_gone", Title: "Gone", Author: "carol", Created: base.Add(3 * time.Minute)}
+		self   = stats.Observation{Name: "t3_self", Title: "Oops", Author: "alice", Created: base.Add(4 * time.Minute)}
+		fresh  = stats.Observation{Name: "t3_fresh", Title: "Fresh", Author: "dave", Created: base.Add(5 * time.Minute)}
+	)
+
+	removals := stats.NewRemovals()
+
+	require.Empty(t, removals.Observe("golang", first, []stats.Observation{old, kept, mod, gone, self}))
+
+	modded := mod
+	modded.Body = "[removed]"
+	deleted := self
+	deleted.Author = "[deleted]"
+
+	// old ages out of the window, gone vanishes, mod is removed and self is deleted.
+	got := removals.Observe("golang", second, []stats.Observation{kept, modded, deleted, fresh})
+	require.ElementsMatch(t, []stats.Removal{
+		{Name: "t3_mod", Title: "Spam", Author: "bob", Kind: stats.RemovalRemoved, Created: mod.Created, Detected: second},
+		{Name: "t3_self", Title: "Oops", Author: "alice", Kind: stats.RemovalDeleted, Created: self.Created, Detected: second},
+		{Name: "t3_gone", Title: "Gone", Author: "carol", Kind: stats.RemovalVanished, Created: gone.Created, Detected: second},
+	}, got)
+
+	// Removals are only reported once.
+	require.Empty(t, removals.Observe("golang", second, []stats.Observation{kept, modded, deleted, fresh}))
+	require.Empty(t, removals.Observe("golang", second, nil))
+
+	require.Len(t, removals.Recent("golang", 2), 2)
+	require.Empty(t, removals.Recent("golang", -1))
+	require.Equal(t, []stats.RemovalRate{{Key: "golang", Removed: 3, Observed: 6}}, removals.SubredditRates())
+	require.Equal(t, []stats.RemovalRate{
+		{Key: "bob", Removed: 1, Observed: 1},
+		{Key: "carol", Removed: 1, Observed: 1},
+		{Key: "alice", Removed: 1, Observed: 3},
+	}, removals.AuthorRates("golang"))
+}
@@ -0,0 +1,449 @@
+package stats
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// deletedAuthor is reported by Reddit for posts whose author deleted their account.
+	deletedAuthor = "[deleted]"
+	// maxScoreHistory bounds the observations retained per post.
+	maxScoreHistory = 48
+	// postRetention is how long after its last observation a post is kept, so posts which dropped
+	// out of every listing do not accumulate.
+	postRetention = 7 * 24 * time.Hour
+)
+
+// Sort orders accepted by TopPosts and TopAuthors.
+const (
+	SortScore    = "score"
+	SortComments = "comments"
+	SortCreated  = "created"
+	SortPosts    = "posts"
+)
+
+// PostSorts lists the orders accepted by TopPosts.
+func PostSorts() []string {
+	return []string{SortScore, SortComments, SortCreated}
+}
+
+// AuthorSorts lists the orders accepted by TopAuthors.
+func AuthorSorts() []string {
+	return []string{SortPosts, SortScore}
+}
+
+// PostStat is the latest observation of a post.
+type PostStat struct {
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Permalink string    `json:"permalink"`
+	Score     int       `json:"score"`
+	Comments  int       `json:"comments"`
+	Created   time.Time `json:"created"`
+}
+
+// AuthorStat summarizes an author's posts in a subreddit.
+type AuthorStat struct {
+	Author string `json:"author"`
+	Posts  int    `json:"posts"`
+	Score  int    `json:"score"`
+}
+
+// ScorePoint is a post's score as observed at a point in time.
+type ScorePoint struct {
+	At    time.Time `json:"at"`
+	Score int       `json:"score"`
+}
+
+// SubredditSummary describes what is known about a tracked subreddit.
+type SubredditSummary struct {
+	Name    string    `json:"name"`
+	Posts   int       `json:"posts"`
+	Authors int       `json:"authors"`
+	Updated time.Time `json:"updated"`
+}
+
+// Query narrows and orders leaderboard results. A zero Since includes every post and a Limit of
+// zero or less returns every result.
+type Query struct {
+	Limit int
+	Since time.Time
+	Sort  string
+}
+
+// Store retains statistics gathered from each tracked subreddit in memory.
+type Store struct {
+	mu sync.RWMutex
+	// authors maps subreddit -> author -> post count.
+	authors map[string]map[string]int
+	// authorIndex maps author -> subreddit -> post count.
+	authorIndex map[string]map[string]int
+	// posts maps subreddit -> fullname -> latest observation.
+	posts map[string]map[string]PostStat
+	// history maps subreddit -> fullname -> recent score observations, oldest first.
+	history map[string]map[string][]ScorePoint
+	updated map[string]time.Time
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		authors:     map[string]map[string]int{},
+		authorIndex: map[string]map[string]int{},
+		posts:       map[string]map[string]PostStat{},
+		history:     map[string]map[string][]ScorePoint{},
+		updated:     map[string]time.Time{},
+	}
+}
+
+// RecordPosts adds or updates posts observed in a subreddit, and drops the posts last observed over
+// a week earlier.
+func (s *Store) RecordPosts(subreddit string, at time.Time, posts ...PostStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[subreddit]; !ok {
+		s.posts[subreddit] = map[string]PostStat{}
+		s.history[subreddit] = map[string][]ScorePoint{}
+	}
+
+	for _, post := range posts {
+		s.posts[subreddit][post.Name] = post
+
+		// Several updates may observe a post during the same crawl; only the latest is kept.
+		history := s.history[subreddit][post.Name]
+		if n := len(history); n > 0 && !history[n-1].At.Before(at) {
+			history = history[:n-1]
+		}
+
+		history = append(history, ScorePoint{At: at, Score: post.Score})
+		s.history[subreddit][post.Name] = history[max(len(history)-maxScoreHistory, 0):]
+	}
+
+	cutoff := at.Add(-postRetention)
+	for name, history := range s.history[subreddit] {
+		if history[len(history)-1].At.Before(cutoff) {
+			delete(s.posts[subreddit], name)
+			delete(s.history[subreddit], name)
+		}
+	}
+
+	s.updated[subreddit] = at
+}
+
+// ScoreHistory returns the recent score observations of a post, oldest first.
+func (s *Store) ScoreHistory(subreddit, name string) []ScorePoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.history[subreddit][name])
+}
+
+// Subreddits summarizes every subreddit with recorded posts or authors, ordered by name.
+func (s *Store) Subreddits() []SubredditSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := map[string]struct{}{}
+	for name := range s.posts {
+		names[name] = struct{}{}
+	}
+
+	for name := range s.authors {
+		names[name] = struct{}{}
+	}
+
+	out := make([]SubredditSummary, 0, len(names))
+	for _, name := range sortedKeys(names) {
+		out = append(out, SubredditSummary{
+			Name:    name,
+			Posts:   len(s.posts[name]),
+			Authors: len(s.authors[name]),
+			Updated: s.updated[name],
+		})
+	}
+
+	return out
+}
+
+// HasSubreddit reports whether anything has been recorded for a subreddit.
+func (s *Store) HasSubreddit(subreddit string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, posts := s.posts[subreddit]
+	_, authors := s.authors[subreddit]
+
+	return posts || authors
+}
+
+// TopPosts returns a subreddit's posts created since q.Since, ordered by q.Sort (score by default).
+func (s *Store) TopPosts(subreddit string, q Query) []PostStat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]PostStat, 0, len(s.posts[subreddit]))
+
+	for _, post := range s.posts[subreddit] {
+		if post.Created.Before(q.Since) {
+			continue
+		}
+
+		out = append(out, post)
+	}
+
+	slices.SortFunc(out, func(a, b PostStat) int {
+		var order int
+
+		switch q.Sort {
+		case SortComments:
+			order = cmp.Compare(b.Comments, a.Comments)
+		case SortCreated:
+			order = b.Created.Compare(a.Created)
+		default:
+			order = cmp.Compare(b.Score, a.Score)
+		}
+
+		return cmp.Or(order, cmp.Compare(a.Name, b.Name))
+	})
+
+	return limit(out, q.Limit)
+}
+
+// TopAuthors returns a subreddit's authors ordered by q.Sort (post count by default). Without a
+// q.Since the post counts come from the latest full crawl of the subreddit; otherwise they are
+// tallied from the recorded posts created since then.
+func (s *Store) TopAuthors(subreddit string, q Query) []AuthorStat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byAuthor := map[string]*AuthorStat{}
+
+	if q.Since.IsZero() {
+		for author, qty := range s.authors[subreddit] {
+			byAuthor[author] = &AuthorStat{Author: author, Posts: qty}
+		}
+	}
+
+	for _, post := range s.posts[subreddit] {
+		if post.Created.Before(q.Since) {
+			continue
+		}
+
+		stat, ok := byAuthor[post.Author]
+		if !ok {
+			stat = &AuthorStat{Author: post.Author}
+			byAuthor[post.Author] = stat
+		}
+
+		if !q.Since.IsZero() {
+			stat.Posts++
+		}
+
+		stat.Score += post.Score
+	}
+
+	out := make([]AuthorStat, 0, len(byAuthor))
+	for _, stat := range byAuthor {
+		out = append(out, *stat)
+	}
+
+	slices.SortFunc(out, func(a, b AuthorStat) int {
+		order := cmp.Compare(b.Posts, a.Posts)
+		if q.Sort == SortScore {
+			order = cmp.Compare(b.Score, a.Score)
+		}
+
+		return cmp.Or(order, cmp.Compare(a.Author, b.Author))
+	})
+
+	return limit(out, q.Limit)
+}
+
+// SetAuthorCounts replaces the per-author post counts for a subreddit and updates the author index.
+func (s *Store) SetAuthorCounts(subreddit string, counts map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for author := range s.authors[subreddit] {
+		delete(s.authorIndex[author], subreddit)
+
+		if len(s.authorIndex[author]) == 0 {
+			delete(s.authorIndex, author)
+		}
+	}
+
+	s.authors[subreddit] = maps.Clone(counts)
+
+	for author, qty := range counts {
+		if author == deletedAuthor {
+			continue
+		}
+
+		if _, ok := s.authorIndex[author]; !ok {
+			s.authorIndex[author] = map[string]int{}
+		}
+
+		s.authorIndex[author][subreddit] = qty
+	}
+}
+
+// Forget drops everything recorded for a subreddit, e.g. once it is no longer tracked.
+func (s *Store) Forget(subreddit string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for author := range s.authors[subreddit] {
+		delete(s.authorIndex[author], subreddit)
+
+		if len(s.authorIndex[author]) == 0 {
+			delete(s.authorIndex, author)
+		}
+	}
+
+	delete(s.authors, subreddit)
+	delete(s.posts, subreddit)
+	delete(s.history, subreddit)
+	delete(s.updated, subreddit)
+}
+
+// AuthorSubreddits returns the post count per subreddit for an author.
+func (s *Store) AuthorSubreddits(author string) map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.authorIndex[author])
+}
+
+// AuthorOverlap is an author active in several tracked subreddits.
+type AuthorOverlap struct {
+	Author     string
+	Subreddits []string
+	Posts      int
+}
+
+// AuthorOverlap returns authors that posted in at least minSubreddits subreddits, ordered by the
+// number of subreddits and then total posts.
+func (s *Store) AuthorOverlap(minSubreddits int) []AuthorOverlap {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []AuthorOverlap
+
+	for author, subreddits := range s.authorIndex {
+		if len(subreddits) < minSubreddits {
+			continue
+		}
+
+		overlap := AuthorOverlap{Author: author, Subreddits: sortedKeys(subreddits)}
+		for _, qty := range subreddits {
+			overlap.Posts += qty
+		}
+
+		out = append(out, overlap)
+	}
+
+	slices.SortFunc(out, func(a, b AuthorOverlap) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Subreddits), len(a.Subreddits)),
+			cmp.Compare(b.Posts, a.Posts),
+			cmp.Compare(a.Author, b.Author),
+		)
+	})
+
+	return out
+}
+
+// Similarity compares the author sets of two subreddits.
+type Similarity struct {
+	A, B    string
+	Jaccard float64
+	// Bridges are the authors active in both subreddits, most active first.
+	Bridges []string
+}
+
+// SubredditSimilarity returns the Jaccard similarity of author sets for every pair of subreddits,
+// most similar first.
+func (s *Store) SubredditSimilarity() []Similarity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subreddits := sortedKeys(s.authors)
+
+	var out []Similarity
+
+	for i, a := range subreddits {
+		for _, b := range subreddits[i+1:] {
+			out = append(out, s.similarity(a, b))
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Similarity) int {
+		return cmp.Compare(b.Jaccard, a.Jaccard)
+	})
+
+	return out
+}
+
+func (s *Store) similarity(a, b string) Similarity {
+	var (
+		bridges []string
+		union   = len(s.authors[a])
+	)
+
+	for author := range s.authors[b] {
+		if author == deletedAuthor {
+			continue
+		}
+
+		if _, ok := s.authors[a][author]; ok {
+			bridges = append(bridges, author)
+
+			continue
+		}
+
+		union++
+	}
+
+	if _, ok := s.authors[a][deletedAuthor]; ok {
+		union--
+	}
+
+	slices.SortFunc(bridges, func(x, y string) int {
+		return cmp.Or(
+			cmp.Compare(s.authors[a][y]+s.authors[b][y], s.authors[a][x]+s.authors[b][x]),
+			cmp.Compare(x, y),
+		)
+	})
+
+	sim := Similarity{A: a, B: b, Bridges: bridges}
+	if union > 0 {
+		sim.Jaccard = float64(len(bridges)) / float64(union)
+	}
+
+	return sim
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func limit[T any](items []T, num int) []T {
+	if num > 0 && len(items) > num {
+		return items[:num]
+	}
+
+	return items
+}
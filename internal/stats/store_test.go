@@ -0,0 +1,196 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_AuthorOverlap(t *testing.T) {
+	t.Parallel()
+
+	store := stats.NewStore()
+	store.SetAuthorCounts("golang", map[string]int{"alice": 3, "bob": 1, "carol": 2, "[deleted]": 7})
+	store.SetAuthorCounts("python", map[string]int{"alice": 1, "bob": 4, "dave": 1, "[deleted]": 2})
+	store.SetAuthorCounts("rust", map[string]int{"alice": 1, "erin": 5})
+
+	require.Equal(t, []stats.AuthorOverlap{
+		{Author: "alice", Subreddits: []string{"golang", "python", "rust"}, Posts: 5},
+		{Author: "bob", Subreddits: []string{"golang", "python"}, Posts: 5},
+	}, store.AuthorOverlap(2))
+
+	require.Equal(t, map[string]int{"golang": 3, "python": 1, "rust": 1}, store.AuthorSubreddits("alice"))
+
+	// A fresh crawl replaces the subreddit's counts, including its entries in the author index.
+	store.SetAuthorCounts("rust", map[string]int{"erin": 6})
+
+	require.Equal(t, map[string]int{"golang": 3, "python": 1}, store.AuthorSubreddits("alice"))
+	require.Empty(t, store.AuthorSubreddits("[deleted]"))
+}
+
+func TestStore_SubredditSimilarity(t *testing.T) {
+	t.Parallel()
+
+	store := stats.NewStore()
+	store.SetAuthorCounts("golang", map[string]int{"alice": 3, "bob": 1, "carol": 2, "[deleted]": 7})
+	store.SetAuthorCounts("python", map[string]int{"alice": 1, "bob": 4, "dave": 1, "[deleted]": 2})
+	store.SetAuthorCounts("rust", map[string]int{"erin": 5})
+
+	require.Equal(t, []stats.Similarity{
+		{A: "golang", B: "python", Jaccard: 0.5, Bridges: []string{"bob", "alice"}},
+		{A: "golang", B: "rust", Jaccard: 0},
+		{A: "python", B: "rust", Jaccard: 0},
+	}, store.SubredditSimilarity())
+}
+
+func TestStore_TopPosts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now   = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		store = stats.NewStore()
+	)
+
+	store.RecordPosts("golang", now,
+		stats.PostStat{Name: "t3_a", Author: "alice", Score: 10, Comments: 1, Created: now.Add(-3 * time.Hour)},
+		stats.PostStat{Name: "t3_b", Author: "bob", Score: 5, Comments: 9, Created: now.Add(-time.Hour)},
+		stats.PostStat{Name: "t3_c", Author: "alice", Score: 7, Comments: 3, Created: now.Add(-2 * time.Hour)},
+	)
+	// A later observation of the same post replaces the earlier one.
+	store.RecordPosts("golang", now,
+		stats.PostStat{Name: "t3_b", Author: "bob", Score: 12, Comments: 9, Created: now.Add(-time.Hour)})
+
+	names := func(posts []stats.PostStat) []string {
+		out := make([]string, 0, len(posts))
+		for _, post := range posts {
+			out = append(out, post.Name)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query stats.Query
+		want  []string
+	}{
+		{name: "Default sort by score", query: stats.Query{}, want: []string{"t3_b", "t3_a", "t3_c"}},
+		{name: "Sort by comments", query: stats.Query{Sort: stats.SortComments}, want: []string{"t3_b", "t3_c", "t3_a"}},
+		{name: "Sort by created", query: stats.Query{Sort: stats.SortCreated}, want: []string{"t3_b", "t3_c", "t3_a"}},
+		{name: "Limit", query: stats.Query{Limit: 1}, want: []string{"t3_b"}},
+		{name: "Since", query: stats.Query{Since: now.Add(-150 * time.Minute)}, want: []string{"t3_b", "t3_c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, names(store.TopPosts("golang", tt.query)))
+		})
+	}
+}
+
+func TestStore_TopAuthors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now   = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		store = stats.NewStore()
+	)
+
+	store.SetAuthorCounts("golang", map[string]int{"alice": 2, "bob": 4})
+	store.RecordPosts("golang", now,
+		stats.PostStat{Name: "t3_a", Author: "alice", Score: 10, Created: now.Add(-3 * time.Hour)},
+		stats.PostStat{Name: "t3_b", Author: "bob", Score: 5, Created: now.Add(-time.Hour)},
+		stats.PostStat{Name: "t3_c", Author: "alice", Score: 7, Created: now.Add(-2 * time.Hour)},
+	)
+
+	require.Equal(t, []stats.AuthorStat{
+		{Author: "bob", Posts: 4, Score: 5},
+		{Author: "alice", Posts: 2, Score: 17},
+	}, store.TopAuthors("golang", stats.Query{}))
+
+	require.Equal(t, []stats.AuthorStat{
+		{Author: "alice", Posts: 2, Score: 17},
+		{Author: "bob", Posts: 4, Score: 5},
+	}, store.TopAuthors("golang", stats.Query{Sort: stats.SortScore}))
+
+	require.Equal(t, []stats.AuthorStat{
+		{Author: "alice", Posts: 1, Score: 7},
+		{Author: "bob", Posts: 1, Score: 5},
+	}, store.TopAuthors("golang", stats.Query{Since: now.Add(-150 * time.Minute)}))
+
+	require.Equal(t, []stats.SubredditSummary{
+		{Name: "golang", Posts: 3, Authors: 2, Updated: now},
+	}, store.Subreddits())
+	require.False(t, store.HasSubreddit("rust"))
+}
+
+func TestStore_ScoreHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		start = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		store = stats.NewStore()
+	)
+
+	for i := range 50 {
+		store.RecordPosts("golang", start.Add(time.Duration(i)*time.Minute), stats.PostStat{Name: "t3_a", Score: i})
+	}
+
+	// A second observation during the same crawl replaces the first.
+	store.RecordPosts("golang", start.Add(49*time.Minute), stats.PostStat{Name: "t3_a", Score: 100})
+
+	history := store.ScoreHistory("golang", "t3_a")
+	require.Len(t, history, 48)
+	require.Equal(t, stats.ScorePoint{At: start.Add(2 * time.Minute), Score: 2}, history[0])
+	require.Equal(t, stats.ScorePoint{At: start.Add(49 * time.Minute), Score: 100}, history[47])
+	require.Empty(t, store.ScoreHistory("golang", "t3_b"))
+}
+
+func TestStore_Retention(t *testing.T) {
+	t.Parallel()
+
+	var (
+		start = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+		store = stats.NewStore()
+	)
+
+	store.RecordPosts("golang", start, stats.PostStat{Name: "t3_a", Score: 1}, stats.PostStat{Name: "t3_b", Score: 2})
+	store.RecordPosts("golang", start.Add(6*24*time.Hour), stats.PostStat{Name: "t3_b", Score: 3})
+	// Over a week after its last observation, a post which dropped out of the listings is dropped.
+	store.RecordPosts("golang", start.Add(8*24*time.Hour), stats.PostStat{Name: "t3_c", Score: 4})
+
+	require.Empty(t, store.ScoreHistory("golang", "t3_a"))
+	require.Len(t, store.ScoreHistory("golang", "t3_b"), 2)
+	require.Len(t, store.ScoreHistory("golang", "t3_c"), 1)
+}
+
+func TestStore_Forget(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	store := stats.NewStore()
+	store.SetAuthorCounts("golang", map[string]int{"alice": 3, "bob": 1})
+	store.SetAuthorCounts("rust", map[string]int{"alice": 1})
+	store.RecordPosts("golang", at, stats.PostStat{Name: "t3_a", Author: "alice", Score: 5})
+
+	store.Forget("golang")
+
+	require.False(t, store.HasSubreddit("golang"))
+	require.Empty(t, store.ScoreHistory("golang", "t3_a"))
+	require.Equal(t, map[string]int{"rust": 1}, store.AuthorSubreddits("alice"))
+	require.Empty(t, store.AuthorSubreddits("bob"))
+	require.Equal(t, []string{"rust"}, subredditNames(store.Subreddits()))
+}
+
+func subredditNames(summaries []stats.SubredditSummary) []string {
+	names := make([]string, len(summaries))
+	for i, summary := range summaries {
+		names[i] = summary.Name
+	}
+
+	return names
+}
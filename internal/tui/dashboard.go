@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/metrics"
+	"github.com/jqdurham/reddit/internal/report"
+)
+
+const (
+	redrawInterval = time.Second
+	// maxErrors bounds the error ticker.
+	maxErrors = 5
+	// separatorLines are the blank lines between the header, panels, jobs and errors.
+	separatorLines = 3
+
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+)
+
+// Dashboard redraws a fixed-layout screen of the latest statistics, rate limit status, job status
+// and errors. It receives top posts and authors as a report.Reporter, job runs as a
+// metrics.Recorder and log lines as an io.Writer.
+type Dashboard struct {
+	metrics.Nop
+
+	out   io.Writer
+	rates RateSource
+	now   func() time.Time
+	size  func() (int, int)
+	dirty chan struct{}
+
+	mu      sync.Mutex
+	posts   map[string][]string
+	authors map[string][]string
+	jobs    map[string]*jobStatus
+	errors  []string
+}
+
+type jobStatus struct {
+	runs, failures int
+	last           time.Time
+	dur            time.Duration
+	err            error
+}
+
+// Option customizes a Dashboard.
+type Option func(d *Dashboard)
+
+// WithClock replaces time.Now as the source of the current time.
+func WithClock(now func() time.Time) Option {
+	return func(d *Dashboard) {
+		d.now = now
+	}
+}
+
+// NewDashboard creates a Dashboard drawing to out.
+func NewDashboard(out io.Writer, rates RateSource, opts ...Option) *Dashboard {
+	d := &Dashboard{
+		out:     out,
+		rates:   rates,
+		now:     time.Now,
+		size:    func() (int, int) { return size(out) },
+		dirty:   make(chan struct{}, 1),
+		posts:   map[string][]string{},
+		authors: map[string][]string{},
+		jobs:    map[string]*jobStatus{},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Report keeps the latest top posts and authors of each subreddit; other reports are ignored.
+func (d *Dashboard) Report(r *report.Report) error {
+	var panel map[string][]string
+
+	switch r.Kind {
+	case report.KindTopPosts:
+		panel = d.posts
+	case report.KindTopAuthors:
+		panel = d.authors
+	default:
+		return nil
+	}
+
+	rows := make([]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = strings.TrimSpace(row.String())
+	}
+
+	d.mu.Lock()
+	panel[r.Subreddit] = rows
+	d.mu.Unlock()
+
+	d.markDirty()
+
+	return nil
+}
+
+// ObserveJob records the outcome of a job run.
+func (d *Dashboard) ObserveJob(job string, dur time.Duration, err error) {
+	d.mu.Lock()
+
+	status, ok := d.jobs[job]
+	if !ok {
+		status = &jobStatus{}
+		d.jobs[job] = status
+	}
+
+	status.runs++
+	status.last = d.now()
+	status.dur = dur
+	status.err = err
+
+	if err != nil {
+		status.failures++
+	}
+
+	d.mu.Unlock()
+
+	d.markDirty()
+}
+
+// Write adds each line to the error ticker, so the dashboard can be the destination of a logger.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		d.errors = append(d.errors, string(line))
+	}
+
+	if len(d.errors) > maxErrors {
+		d.errors = d.errors[len(d.errors)-maxErrors:]
+	}
+
+	d.mu.Unlock()
+
+	d.markDirty()
+
+	return len(p), nil
+}
+
+// Run takes over the terminal and redraws whenever statistics change, the terminal is resized or
+// a second passes. The terminal is restored once the context is cancelled.
+func (d *Dashboard) Run(ctx context.Context) error {
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+
+	defer signal.Stop(resize)
+
+	if _, err := io.WriteString(d.out, enterScreen); err != nil {
+		return fmt.Errorf("enter screen: %w", err)
+	}
+
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.draw(); err != nil {
+			_, _ = io.WriteString(d.out, leaveScreen)
+
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			if _, err := io.WriteString(d.out, leaveScreen); err != nil {
+				return fmt.Errorf("leave screen: %w", err)
+			}
+
+			return nil
+		case <-ticker.C:
+		case <-resize:
+		case <-d.dirty:
+		}
+	}
+}
+
+// Render lays out the dashboard to fit width columns and height rows.
+func (d *Dashboard) Render(width, height int) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		header = []string{
+			"reddit monitor  " + d.now().UTC().Format(time.DateTime) + " UTC",
+			d.rateGauge(),
+		}
+		jobs   = d.jobLines(max(height/4, 1))
+		errs   = d.errorLines()
+		budget = height - len(header) - len(jobs) - len(errs) - separatorLines
+	)
+
+	lines := slices.Concat(header, []string{""}, d.panelLines(width, budget), []string{""}, jobs, []string{""}, errs)
+
+	out := make([]string, height)
+	for i := range out {
+		if i < len(lines) {
+			out[i] = fit(lines[i], width)
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func (d *Dashboard) draw() error {
+	width, height := d.size()
+	frame := strings.ReplaceAll(d.Render(width, height), "\n", clearLine+"\r\n")
+
+	if _, err := io.WriteString(d.out, cursorHome+frame+clearLine+clearBelow); err != nil {
+		return fmt.Errorf("draw: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Dashboard) markDirty() {
+	select {
+	case d.dirty <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,115 @@
+package tui_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/reddit"
+	"github.com/jqdurham/reddit/internal/report"
+	"github.com/jqdurham/reddit/internal/tui"
+	"github.com/stretchr/testify/require"
+)
+
+type rateSource reddit.RateStatus
+
+func (r rateSource) RateStatus() reddit.RateStatus {
+	return reddit.RateStatus(r)
+}
+
+type testRow string
+
+func (r testRow) String() string {
+	return string(r) + " \n"
+}
+
+func (r testRow) Values() []any {
+	return []any{string(r)}
+}
+
+func rows(values ...string) []report.Row {
+	out := make([]report.Row, len(values))
+	for i, v := range values {
+		out[i] = testRow(v)
+	}
+
+	return out
+}
+
+func TestDashboard_Render(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	dash := tui.NewDashboard(&bytes.Buffer{}, rateSource{Remaining: 75, Used: 25, Reset: 120},
+		tui.WithClock(func() time.Time { return now }))
+
+	require.NoError(t, dash.Report(&report.Report{Kind: report.KindTopPosts, Subreddit: "golang",
+		Rows: rows("(1337) - Go 1.22 released", "(42) - A rather long title which will not fit")}))
+	require.NoError(t, dash.Report(&report.Report{Kind: report.KindTopAuthors, Subreddit: "golang",
+		Rows: rows("(3) - alice", "(2) - bob", "(1) - carol")}))
+	require.NoError(t, dash.Report(&report.Report{Kind: report.KindTopPosts, Subreddit: "rust",
+		Rows: rows("(7) - Rust 1.77")}))
+	// Reports other than top posts and authors are not shown.
+	require.NoError(t, dash.Report(&report.Report{Kind: report.KindMood, Subreddit: "python", Rows: rows("mood")}))
+
+	dash.ObserveJob("top-posts:golang", 1500*time.Millisecond, nil)
+	dash.ObserveJob("top-posts:golang", 250*time.Millisecond, errors.New("rate limit exceeded"))
+
+	for i := range 7 {
+		_, err := fmt.Fprintf(dash, "level=ERROR msg=failure%d\n", i)
+		require.NoError(t, err)
+	}
+
+	want := []string{
+		"reddit monitor  2024-04-01 12:00:00 UTC",
+		"Rate limit  [#####---------------] 25 used, 75 remaining, resets in 2m0s",
+		"",
+		"r/golang " + strings.Repeat("─", 63),
+		"(1337) - Go 1.22 released          │ (3) - alice",
+		"(42) - A rather long title which … │ (2) - bob",
+		"r/rust " + strings.Repeat("─", 65),
+		"(7) - Rust 1.77                    │",
+		"",
+		"JOB                            RUNS  FAILS   DURATION  LAST",
+		"top-posts:golang                  2      1      250ms  12:00:00 error: …",
+		"",
+		"ERRORS",
+		"level=ERROR msg=failure2",
+		"level=ERROR msg=failure3",
+		"level=ERROR msg=failure4",
+		"level=ERROR msg=failure5",
+		"level=ERROR msg=failure6",
+		"",
+		"",
+	}
+
+	got := strings.Split(dash.Render(72, len(want)), "\n")
+	for i := range got {
+		got[i] = strings.TrimRight(got[i], " ")
+		want[i] = strings.TrimRight(want[i], " ")
+	}
+
+	require.Equal(t, want, got)
+}
+
+func TestDashboard_Run(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	dash := tui.NewDashboard(buf, rateSource{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, dash.Run(ctx))
+
+	out := buf.String()
+	require.True(t, strings.HasPrefix(out, "\x1b[?1049h\x1b[?25l\x1b[H"))
+	require.Contains(t, out, "Rate limit  waiting for first response\x1b[K\r\n")
+	require.True(t, strings.HasSuffix(out, "\x1b[?25h\x1b[?1049l"))
+	require.False(t, tui.IsTerminal(buf))
+}
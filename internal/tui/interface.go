@@ -0,0 +1,8 @@
+package tui
+
+import "github.com/jqdurham/reddit/internal/reddit"
+
+// RateSource reports the API's latest rate limit status.
+type RateSource interface {
+	RateStatus() reddit.RateStatus
+}
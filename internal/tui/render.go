@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	gaugeWidth   = 20
+	jobNameWidth = 28
+	columnDivide = " │ "
+	ellipsis     = "…"
+)
+
+// rateGauge draws the share of the rate limit period's requests already used.
+func (d *Dashboard) rateGauge() string {
+	status := d.rates.RateStatus()
+
+	total := float64(status.Used) + float64(status.Remaining)
+	if total == 0 {
+		return "Rate limit  waiting for first response"
+	}
+
+	used := int(math.Round(float64(status.Used) / total * gaugeWidth))
+
+	return fmt.Sprintf("Rate limit  [%s%s] %d used, %.0f remaining, resets in %s",
+		strings.Repeat("#", used), strings.Repeat("-", gaugeWidth-used), status.Used, status.Remaining,
+		time.Duration(status.Reset)*time.Second)
+}
+
+// panelLines draws a panel per subreddit, splitting the available rows between them.
+func (d *Dashboard) panelLines(width, budget int) []string {
+	subreddits := make([]string, 0, len(d.posts)+len(d.authors))
+	for sub := range d.posts {
+		subreddits = append(subreddits, sub)
+	}
+
+	for sub := range d.authors {
+		if _, ok := d.posts[sub]; !ok {
+			subreddits = append(subreddits, sub)
+		}
+	}
+
+	if len(subreddits) == 0 {
+		return []string{"Waiting for statistics..."}
+	}
+
+	slices.Sort(subreddits)
+
+	var (
+		left  = (width - utf8.RuneCountInString(columnDivide)) / 2
+		right = width - left - utf8.RuneCountInString(columnDivide)
+		rows  = budget/len(subreddits) - 1
+		lines = make([]string, 0, budget)
+	)
+
+	for _, sub := range subreddits {
+		heading := "r/" + sub + " "
+		lines = append(lines, heading+strings.Repeat("─", max(width-utf8.RuneCountInString(heading), 0)))
+
+		posts, authors := d.posts[sub], d.authors[sub]
+		for i := range min(rows, max(len(posts), len(authors))) {
+			lines = append(lines, pad(fit(at(posts, i), left), left)+columnDivide+fit(at(authors, i), right))
+		}
+	}
+
+	return lines
+}
+
+// jobLines lists up to num jobs, the most recently run first.
+func (d *Dashboard) jobLines(num int) []string {
+	names := make([]string, 0, len(d.jobs))
+	for name := range d.jobs {
+		names = append(names, name)
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(d.jobs[b].last.Compare(d.jobs[a].last), cmp.Compare(a, b))
+	})
+
+	lines := []string{fmt.Sprintf("%-*s %6s %6s %10s  %s", jobNameWidth, "JOB", "RUNS", "FAILS", "DURATION", "LAST")}
+
+	for _, name := range names[:min(num, len(names))] {
+		job := d.jobs[name]
+
+		last := "ok"
+		if job.err != nil {
+			last = "error: " + strings.ReplaceAll(job.err.Error(), "\n", "; ")
+		}
+
+		lines = append(lines, fmt.Sprintf("%-*s %6d %6d %10s  %s %s", jobNameWidth, fit(name, jobNameWidth),
+			job.runs, job.failures, job.dur.Round(time.Millisecond), job.last.UTC().Format(time.TimeOnly), last))
+	}
+
+	return lines
+}
+
+func (d *Dashboard) errorLines() []string {
+	return append([]string{"ERRORS"}, d.errors...)
+}
+
+// fit truncates s to width runes, marking the truncation with an ellipsis.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+
+	return string(runes[:width-1]) + ellipsis
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+func at(rows []string, i int) string {
+	if i < len(rows) {
+		return rows[i]
+	}
+
+	return ""
+}
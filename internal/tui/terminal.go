@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// IsTerminal reports whether w is a character device such as an interactive terminal.
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// size returns the dimensions of the terminal behind w, falling back to 80x24.
+func size(w io.Writer) (int, int) {
+	if file, ok := w.(*os.File); ok {
+		if width, height, err := terminalSize(file.Fd()); err == nil && width > 0 && height > 0 {
+			return width, height
+		}
+	}
+
+	return defaultWidth, defaultHeight
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin)
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+func terminalSize(uintptr) (int, int, error) {
+	return 0, 0, errors.New("terminal size unsupported")
+}
+
+// notifyResize is a no-op; the dashboard still picks up new dimensions on its next redraw.
+func notifyResize(chan<- os.Signal) {}
@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+package tui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	rows, cols, xPixel, yPixel uint16
+}
+
+func terminalSize(fd uintptr) (int, int, error) {
+	var ws winsize
+
+	//nolint:gosec // TIOCGWINSZ fills the winsize struct passed by pointer.
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, 0, errno
+	}
+
+	return int(ws.cols), int(ws.rows), nil
+}
+
+// notifyResize delivers SIGWINCH to ch whenever the terminal is resized.
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
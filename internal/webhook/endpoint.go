@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Payload formats accepted by receivers.
+const (
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+// discordContentLimit is the most characters Discord accepts in a message.
+const discordContentLimit = 2000
+
+// Formats lists the supported payload formats.
+func Formats() []string {
+	return []string{FormatJSON, FormatSlack, FormatDiscord}
+}
+
+// Endpoint is a webhook receiver. When Secret is set, deliveries are signed with it.
+type Endpoint struct {
+	URL    string
+	Format string
+	Secret string
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+type discordPayload struct {
+	Content string `json:"content"`
+}
+
+// payload encodes the notification in the endpoint's format.
+func (e Endpoint) payload(n Notification) ([]byte, error) {
+	var v any
+
+	switch e.Format {
+	case FormatSlack:
+		v = slackPayload{Text: n.Text}
+	case FormatDiscord:
+		content := n.Text
+		if utf8.RuneCountInString(content) > discordContentLimit {
+			content = string([]rune(content)[:discordContentLimit])
+		}
+
+		v = discordPayload{Content: content}
+	default:
+		v = n
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	return body, nil
+}
+
+// Sign returns the signature sent in the X-Webhook-Signature header: the hex encoded HMAC-SHA256
+// of the timestamp header, a period and the request body, keyed with the endpoint's secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,17 @@
+package webhook
+
+import "fmt"
+
+// DeliveryError is returned when a receiver responds with a status code not indicating success.
+type DeliveryError struct {
+	URL    string
+	Status int
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("webhook delivery: %s status: %d", e.URL, e.Status)
+}
+
+func NewDeliveryError(url string, status int) *DeliveryError {
+	return &DeliveryError{URL: url, Status: status}
+}
@@ -0,0 +1,8 @@
+package webhook
+
+// Notifier delivers notifications to webhook receivers without blocking the caller.
+//
+//go:generate mockery --name Notifier
+type Notifier interface {
+	Notify(n Notification)
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import "time"
+
+// Notification events raised by the post service.
+const (
+	EventTopPost          = "top-post"
+	EventAuthorEnteredTop = "author-entered-top"
+	EventTrendingPost     = "trending-post"
+	EventRuleMatched      = "rule-matched"
+)
+
+// Notification describes something noteworthy about a subreddit. Text is a human readable summary
+// used by chat payload formats; Data carries event specific details for the JSON format.
+type Notification struct {
+	Event     string    `json:"event"`
+	Subreddit string    `json:"subreddit,omitempty"`
+	Text      string    `json:"text"`
+	Time      time.Time `json:"time"`
+	Data      any       `json:"data,omitempty"`
+}
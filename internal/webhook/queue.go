@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/logger"
+)
+
+const (
+	defaultBuffer   = 64
+	defaultAttempts = 5
+	defaultBackoff  = time.Second
+	maxBackoff      = 30 * time.Second
+	requestTimeout  = 10 * time.Second
+)
+
+// Queue delivers notifications to webhook endpoints in the background. Each endpoint has its own
+// bounded queue, so a slow receiver only delays its own deliveries, and Notify never blocks: when
+// an endpoint falls behind, new notifications for it are dropped.
+type Queue struct {
+	endpoints  []*endpointQueue
+	httpClient *http.Client
+	attempts   int
+	backoff    time.Duration
+	buffer     int
+}
+
+type endpointQueue struct {
+	Endpoint
+	ch      chan Notification
+	dropped atomic.Uint64
+}
+
+// Option customizes a Queue.
+type Option func(q *Queue)
+
+// WithHTTPClient replaces the client used to deliver notifications.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(q *Queue) {
+		q.httpClient = httpClient
+	}
+}
+
+// WithRetry sets how many times a delivery is attempted and the delay before the first retry,
+// which doubles on each subsequent retry.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(q *Queue) {
+		q.attempts = max(attempts, 1)
+		q.backoff = backoff
+	}
+}
+
+// WithBuffer sets how many notifications are queued per endpoint before new ones are dropped.
+func WithBuffer(buffer int) Option {
+	return func(q *Queue) {
+		q.buffer = max(buffer, 1)
+	}
+}
+
+// NewQueue creates a Queue delivering to the endpoints once Run is called.
+func NewQueue(endpoints []Endpoint, opts ...Option) *Queue {
+	q := &Queue{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		attempts:   defaultAttempts,
+		backoff:    defaultBackoff,
+		buffer:     defaultBuffer,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	for _, endpoint := range endpoints {
+		q.endpoints = append(q.endpoints, &endpointQueue{Endpoint: endpoint, ch: make(chan Notification, q.buffer)})
+	}
+
+	return q
+}
+
+// Notify queues the notification for every endpoint.
+func (q *Queue) Notify(n Notification) {
+	for _, endpoint := range q.endpoints {
+		select {
+		case endpoint.ch <- n:
+		default:
+			endpoint.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns how many notifications were discarded because an endpoint fell behind.
+func (q *Queue) Dropped() uint64 {
+	var dropped uint64
+	for _, endpoint := range q.endpoints {
+		dropped += endpoint.dropped.Load()
+	}
+
+	return dropped
+}
+
+// Run delivers queued notifications until the context is cancelled. Deliveries which still fail
+// after every attempt are logged and discarded.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, endpoint := range q.endpoints {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case n := <-endpoint.ch:
+					if err := q.deliver(ctx, endpoint.Endpoint, n); err != nil && ctx.Err() == nil {
+						logger.FromContext(ctx).Warn("webhook delivery failed",
+							"url", endpoint.URL, "event", n.Event, "err", err.Error())
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// deliver posts the notification, retrying with exponential backoff while the failure may be
+// temporary.
+func (q *Queue) deliver(ctx context.Context, endpoint Endpoint, n Notification) error {
+	body, err := endpoint.payload(n)
+	if err != nil {
+		return err
+	}
+
+	delay := q.backoff
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := q.send(ctx, endpoint, n.Event, body)
+		if err == nil {
+			return nil
+		}
+
+		if attempt == q.attempts || !retryable(err) {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+
+		wait := max(delay, retryAfter)
+		delay = min(delay*2, maxBackoff)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("attempt %d: %w", attempt, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send makes a single delivery attempt, returning how long the receiver asked to wait before
+// retrying, if at all, up to maxBackoff.
+func (q *Queue) send(ctx context.Context, endpoint Endpoint, event string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+
+	if endpoint.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", Sign(endpoint.Secret, timestamp, body))
+	}
+
+	res, err := q.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		return 0, nil
+	}
+
+	// Retry-After is capped so a receiver cannot hold the endpoint's queue for longer than the
+	// backoff would.
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		retryAfter = min(max(time.Duration(secs)*time.Second, 0), maxBackoff)
+	}
+
+	return retryAfter, NewDeliveryError(endpoint.URL, res.StatusCode)
+}
+
+// retryable reports whether a failed delivery may succeed later: the receiver was unreachable,
+// throttled us or failed itself.
+func retryable(err error) bool {
+	var delivery *DeliveryError
+	if !errors.As(err, &delivery) {
+		return true
+	}
+
+	return delivery.Status == http.StatusTooManyRequests || delivery.Status >= http.StatusInternalServerError
+}
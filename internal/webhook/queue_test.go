@@ -0,0 +1,183 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jqdurham/reddit/internal/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+type delivery struct {
+	header http.Header
+	body   string
+}
+
+// receiver is a stand-in webhook receiver answering with the queued statuses, then 200 OK.
+type receiver struct {
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []delivery
+	received   chan struct{}
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rcv := &receiver{statuses: statuses, received: make(chan struct{}, 16)}
+	srv := httptest.NewServer(rcv)
+	t.Cleanup(srv.Close)
+
+	return rcv, srv
+}
+
+func (rcv *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	rcv.deliveries = append(rcv.deliveries, delivery{header: r.Header.Clone(), body: string(body)})
+
+	status := http.StatusOK
+	if len(rcv.statuses) > 0 {
+		status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+	}
+	rcv.mu.Unlock()
+
+	w.WriteHeader(status)
+	rcv.received <- struct{}{}
+}
+
+func (rcv *receiver) wait(t *testing.T, num int) []delivery {
+	t.Helper()
+
+	for range num {
+		select {
+		case <-rcv.received:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for delivery")
+		}
+	}
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return rcv.deliveries
+}
+
+func testNotification() webhook.Notification {
+	return webhook.Notification{
+		Event:     webhook.EventTopPost,
+		Subreddit: "golang",
+		Text:      "New #1 post in r/golang: Go 1.22 released (1337)",
+		Time:      time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestQueue_Deliver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		endpoint webhook.Endpoint
+		statuses []int
+		attempts int
+		want     string
+	}{
+		{
+			name:     "JSON",
+			endpoint: webhook.Endpoint{Format: webhook.FormatJSON, Secret: "s3cr3t"},
+			attempts: 1,
+			want: `{"event":"top-post","subreddit":"golang","text":"New #1 post in r/golang: Go 1.22 released (1337)",` +
+				`"time":"2024-04-01T12:00:00Z"}`,
+		},
+		{
+			name:     "Slack",
+			endpoint: webhook.Endpoint{Format: webhook.FormatSlack},
+			attempts: 1,
+			want:     `{"text":"New #1 post in r/golang: Go 1.22 released (1337)"}`,
+		},
+		{
+			name:     "Discord",
+			endpoint: webhook.Endpoint{Format: webhook.FormatDiscord},
+			attempts: 1,
+			want:     `{"content":"New #1 post in r/golang: Go 1.22 released (1337)"}`,
+		},
+		{
+			name:     "Retries temporary failures",
+			endpoint: webhook.Endpoint{Format: webhook.FormatSlack},
+			statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			attempts: 3,
+			want:     `{"text":"New #1 post in r/golang: Go 1.22 released (1337)"}`,
+		},
+		{
+			name:     "Gives up on rejection",
+			endpoint: webhook.Endpoint{Format: webhook.FormatSlack},
+			statuses: []int{http.StatusBadRequest},
+			attempts: 1,
+			want:     `{"text":"New #1 post in r/golang: Go 1.22 released (1337)"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rcv, srv := newReceiver(t, tt.statuses...)
+			tt.endpoint.URL = srv.URL
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			queue := webhook.NewQueue([]webhook.Endpoint{tt.endpoint}, webhook.WithRetry(3, time.Millisecond))
+			go queue.Run(ctx)
+
+			queue.Notify(testNotification())
+
+			deliveries := rcv.wait(t, tt.attempts)
+			require.Len(t, deliveries, tt.attempts)
+
+			for _, d := range deliveries {
+				require.Equal(t, tt.want, d.body)
+				require.Equal(t, webhook.EventTopPost, d.header.Get("X-Webhook-Event"))
+				require.Equal(t, "application/json", d.header.Get("Content-Type"))
+
+				if tt.endpoint.Secret == "" {
+					require.Empty(t, d.header.Get("X-Webhook-Signature"))
+
+					continue
+				}
+
+				require.Equal(t, webhook.Sign(tt.endpoint.Secret, d.header.Get("X-Webhook-Timestamp"), []byte(d.body)),
+					d.header.Get("X-Webhook-Signature"))
+			}
+
+			select {
+			case <-rcv.received:
+				t.Fatal("unexpected delivery")
+			case <-time.After(20 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestQueue_Notify(t *testing.T) {
+	t.Parallel()
+
+	queue := webhook.NewQueue([]webhook.Endpoint{{URL: "http://127.0.0.1:0"}}, webhook.WithBuffer(1))
+
+	queue.Notify(testNotification())
+	queue.Notify(testNotification())
+
+	require.Equal(t, uint64(1), queue.Dropped())
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "sha256=b1e22ded59b9dfc05bffea94610159c357e7d5c4d91d6d61e04144802ba4ceba",
+		webhook.Sign("s3cr3t", "1711972800", []byte(`{"event":"top-post"}`)))
+}